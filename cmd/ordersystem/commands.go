@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/infra/database"
+	"github.com/vs0uz4/clean_architecture/internal/infra/messaging"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+type command func(ctx context.Context, args []string) error
+
+func archiveCommand(archiver *database.OrderArchiver, defaultAge time.Duration) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("archive", flag.ContinueOnError)
+		olderThan := flags.Duration("older-than", defaultAge, "archive orders created before this age")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if *olderThan <= 0 {
+			return fmt.Errorf("invalid archive age %s", *olderThan)
+		}
+
+		archive, err := archiver.Archive(ctx, *olderThan)
+		if err != nil {
+			return err
+		}
+		log.Printf("Archived %d orders created before %s into %s (archive id %d)", archive.OrderCount, archive.Cutoff.Format(time.RFC3339), archive.FileName, archive.ID)
+		return nil
+	}
+}
+
+func restoreCommand(archiver *database.OrderArchiver) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: restore <archive-id>")
+		}
+		archiveID, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid archive id %q", flags.Arg(0))
+		}
+
+		archive, err := archiver.Restore(ctx, archiveID)
+		if err != nil {
+			return err
+		}
+		log.Printf("Restored %d orders from %s (archive id %d)", archive.OrderCount, archive.FileName, archive.ID)
+		return nil
+	}
+}
+
+func exportCommand(exportOrders *usecase.ExportOrdersUseCase) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("export", flag.ContinueOnError)
+		format := flags.String("format", usecase.OrderFormatCSV, "output format (csv or jsonl)")
+		tenant := flags.String("tenant", entity.DefaultTenant, "tenant whose orders are exported")
+		output := flags.String("output", "-", "output file, - for stdout")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if *output != "-" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		return exportOrders.Execute(entity.ContextWithTenant(ctx, *tenant), *format, w)
+	}
+}
+
+func importCommand(importOrders *usecase.ImportOrdersUseCase) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("import", flag.ContinueOnError)
+		format := flags.String("format", usecase.OrderFormatCSV, "input format (csv or jsonl)")
+		tenant := flags.String("tenant", entity.DefaultTenant, "tenant the orders are imported into")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: import [-format=csv|jsonl] [-tenant=name] <file|->")
+		}
+
+		var r io.Reader = os.Stdin
+		if flags.Arg(0) != "-" {
+			file, err := os.Open(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			r = file
+		}
+
+		report, err := importOrders.Execute(entity.ContextWithTenant(ctx, *tenant), *format, r)
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return err
+		}
+		log.Printf("Imported %d orders, %d records failed", report.Imported, report.Failed)
+		if report.Failed > 0 {
+			return fmt.Errorf("%d records could not be imported", report.Failed)
+		}
+		return nil
+	}
+}
+
+func deadLettersCommand(deadLetters *database.DeadLetterRepository) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("deadletters", flag.ContinueOnError)
+		all := flags.Bool("all", false, "include the dead letters already redispatched")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		records, err := deadLetters.List(ctx, *all)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		log.Printf("%d dead letters", len(records))
+		return nil
+	}
+}
+
+// redispatchCommand delivers a dead letter again to the handler that failed
+// it, building the dispatcher only when called since it connects to RabbitMQ.
+func redispatchCommand(deadLetters *database.DeadLetterRepository, decoders map[string]events.EventDecoder, newDispatcher func() *events.EventDispatcher) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("redispatch", flag.ContinueOnError)
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: redispatch <dead-letter-id>")
+		}
+		id, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid dead letter id %q", flags.Arg(0))
+		}
+
+		record, err := deadLetters.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if record.RedispatchedAt != nil {
+			return database.ErrDeadLetterAlreadyRedispatched
+		}
+		decode, ok := decoders[record.EventName]
+		if !ok {
+			return fmt.Errorf("no decoder for event %s", record.EventName)
+		}
+		event, err := decode(record.Metadata, record.Payload)
+		if err != nil {
+			return err
+		}
+
+		ctx = entity.ContextWithTenant(ctx, record.TenantID)
+		if err := newDispatcher().Redeliver(ctx, record.Handler, event); err != nil {
+			return err
+		}
+		if err := deadLetters.MarkRedispatched(ctx, record.ID, time.Now().UTC()); err != nil {
+			return err
+		}
+		log.Printf("Redispatched %s to %s (dead letter id %d)", record.EventName, record.Handler, record.ID)
+		return nil
+	}
+}
+
+func schedulesCommand(schedules *database.ScheduleRepository) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("schedules", flag.ContinueOnError)
+		all := flags.Bool("all", false, "include the events already dispatched, cancelled or failed")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		records, err := schedules.List(ctx, *all)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		log.Printf("%d scheduled events", len(records))
+		return nil
+	}
+}
+
+// scheduleCommand schedules an event, given by name and JSON payload in its
+// current schema version, for the scheduler of the running application to
+// dispatch.
+func scheduleCommand(scheduler *events.Scheduler, registry *events.SchemaRegistry) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("schedule", flag.ContinueOnError)
+		at := flags.String("at", "", "dispatch the event at this time (RFC3339)")
+		after := flags.Duration("after", 0, "dispatch the event once this delay has passed")
+		tenant := flags.String("tenant", entity.DefaultTenant, "tenant the event is dispatched on behalf of")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() != 2 || (*at == "") == (*after <= 0) {
+			return fmt.Errorf("usage: schedule -at=<time>|-after=<delay> [-tenant=name] <event> <payload-json>")
+		}
+		dueAt, err := parseReplayTime(*at)
+		if err != nil {
+			return err
+		}
+		eventName := flags.Arg(0)
+		version, ok := registry.Version(eventName)
+		if !ok {
+			return fmt.Errorf("no schema for event %s", eventName)
+		}
+
+		id := events.NewID()
+		event, err := registry.Decode(eventName, events.Metadata{
+			ID:            id,
+			OccurredAt:    time.Now().UTC(),
+			CorrelationID: id,
+			Version:       version,
+		}, []byte(flags.Arg(1)))
+		if err != nil {
+			return err
+		}
+
+		ctx = entity.ContextWithTenant(ctx, *tenant)
+		if *at == "" {
+			dueAt = time.Now().Add(*after)
+		}
+		if _, err := scheduler.ScheduleAt(ctx, event, dueAt); err != nil {
+			return err
+		}
+		log.Printf("Scheduled %s %s for %s", eventName, id, dueAt.UTC().Format(time.RFC3339))
+		return nil
+	}
+}
+
+func unscheduleCommand(schedules *database.ScheduleRepository) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("unschedule", flag.ContinueOnError)
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: unschedule <scheduled-event-id>")
+		}
+
+		if err := schedules.Cancel(ctx, flags.Arg(0), time.Now().UTC()); err != nil {
+			return err
+		}
+		log.Printf("Cancelled scheduled event %s", flags.Arg(0))
+		return nil
+	}
+}
+
+func purgeInboxCommand(inbox *database.InboxRepository, defaultRetention time.Duration) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("purge-inbox", flag.ContinueOnError)
+		olderThan := flags.Duration("older-than", defaultRetention, "purge events processed before this age")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if *olderThan <= 0 {
+			return fmt.Errorf("invalid inbox retention %s", *olderThan)
+		}
+
+		purged, err := inbox.Purge(ctx, time.Now().Add(-*olderThan))
+		if err != nil {
+			return err
+		}
+		log.Printf("Purged %d processed events from the inbox", purged)
+		return nil
+	}
+}
+
+// workerCommand consumes commands until SIGINT or SIGTERM, then waits up to
+// shutdownTimeout for the messages being handled before giving up on them,
+// which the broker delivers again. A second signal stops it right away.
+func workerCommand(defaults messaging.ConsumerConfig, shutdownTimeout time.Duration, consume func(ctx context.Context, config messaging.ConsumerConfig) error) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("worker", flag.ContinueOnError)
+		config := defaults
+		flags.StringVar(&config.Queue, "queue", defaults.Queue, "queue to consume the create-order commands from")
+		flags.IntVar(&config.Prefetch, "prefetch", defaults.Prefetch, "messages the broker sends ahead of their acks")
+		flags.IntVar(&config.Concurrency, "concurrency", defaults.Concurrency, "messages handled at a time")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if config.Queue == "" {
+			return fmt.Errorf("no queue to consume")
+		}
+		if config.Prefetch <= 0 || config.Concurrency <= 0 {
+			return fmt.Errorf("invalid prefetch %d or concurrency %d", config.Prefetch, config.Concurrency)
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		consumed := make(chan error, 1)
+		go func() { consumed <- consume(ctx, config) }()
+		log.Printf("Worker consuming %s (prefetch=%d, concurrency=%d)", config.Queue, config.Prefetch, config.Concurrency)
+
+		select {
+		case err := <-consumed:
+			return err
+		case <-ctx.Done():
+			stop()
+		}
+		log.Printf("Worker stopping, waiting up to %s for the messages being handled", shutdownTimeout)
+		select {
+		case err := <-consumed:
+			if err == nil {
+				log.Printf("Worker stopped")
+			}
+			return err
+		case <-time.After(shutdownTimeout):
+			return fmt.Errorf("worker did not stop within %s", shutdownTimeout)
+		}
+	}
+}
+
+// replayCommand dispatches the logged events again, in the order they were
+// logged, to all their handlers or only the ones given in -handler.
+func replayCommand(eventLog *database.EventLogRepository, decoders map[string]events.EventDecoder, newDispatcher func() *events.EventDispatcher) command {
+	return func(ctx context.Context, args []string) error {
+		flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+		from := flags.String("from", "", "replay events that occurred at or after this time (RFC3339)")
+		to := flags.String("to", "", "replay events that occurred before this time (RFC3339)")
+		eventName := flags.String("event", "", "replay only events with this name")
+		handlerNames := flags.String("handler", "", "comma-separated handlers to replay to, all of them when empty")
+		dryRun := flags.Bool("dry-run", false, "list the events without dispatching them")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		filter := database.EventLogFilter{EventName: *eventName}
+		var err error
+		if filter.From, err = parseReplayTime(*from); err != nil {
+			return err
+		}
+		if filter.To, err = parseReplayTime(*to); err != nil {
+			return err
+		}
+		var handlers []string
+		if *handlerNames != "" {
+			handlers = strings.Split(*handlerNames, ",")
+		}
+
+		total, err := eventLog.Count(ctx, filter)
+		if err != nil {
+			return err
+		}
+		log.Printf("Replaying %d events", total)
+
+		var eventDispatcher *events.EventDispatcher
+		if !*dryRun {
+			eventDispatcher = newDispatcher()
+			for _, name := range handlers {
+				if !eventDispatcher.HasHandlerNamed(name) {
+					return fmt.Errorf("unknown handler %q", name)
+				}
+			}
+		}
+
+		replayed, failed := 0, 0
+		err = eventLog.Stream(ctx, filter, func(record database.EventLogRecord) error {
+			replayed++
+			if *dryRun {
+				log.Printf("[%d/%d] %s %s (tenant %s, occurred at %s)", replayed, total, record.EventName, record.Metadata.ID, record.TenantID, record.Metadata.OccurredAt.Format(time.RFC3339))
+				return nil
+			}
+			decode, ok := decoders[record.EventName]
+			if !ok {
+				return fmt.Errorf("no decoder for event %s", record.EventName)
+			}
+			event, err := decode(record.Metadata, record.Payload)
+			if err != nil {
+				return err
+			}
+			if err := eventDispatcher.Replay(entity.ContextWithTenant(ctx, record.TenantID), event, handlers...); err != nil {
+				failed++
+				log.Printf("[%d/%d] failed to replay %s %s: %v", replayed, total, record.EventName, record.Metadata.ID, err)
+			}
+			if replayed%100 == 0 || replayed == total {
+				log.Printf("[%d/%d] events replayed", replayed, total)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			log.Printf("Dry run, %d events would be replayed", replayed)
+			return nil
+		}
+		log.Printf("Replayed %d events, %d failed", replayed, failed)
+		if failed > 0 {
+			return fmt.Errorf("%d events could not be replayed", failed)
+		}
+		return nil
+	}
+}
+
+func parseReplayTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339", value)
+	}
+	return parsed, nil
+}
+
+func runCommand(ctx context.Context, commands map[string]command, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd(ctx, args[1:])
+}
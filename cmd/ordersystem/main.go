@@ -1,22 +1,35 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	graphql_handler "github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/streadway/amqp"
 	"github.com/vs0uz4/clean_architecture/configs"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/event"
 	"github.com/vs0uz4/clean_architecture/internal/event/handler"
+	"github.com/vs0uz4/clean_architecture/internal/infra/auth"
+	"github.com/vs0uz4/clean_architecture/internal/infra/database"
 	"github.com/vs0uz4/clean_architecture/internal/infra/graph"
 	"github.com/vs0uz4/clean_architecture/internal/infra/grpc/pb"
 	"github.com/vs0uz4/clean_architecture/internal/infra/grpc/service"
+	"github.com/vs0uz4/clean_architecture/internal/infra/messaging"
+	"github.com/vs0uz4/clean_architecture/internal/infra/web"
 	"github.com/vs0uz4/clean_architecture/internal/infra/web/webserver"
+	"github.com/vs0uz4/clean_architecture/internal/infra/worker"
 	"github.com/vs0uz4/clean_architecture/pkg/events"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -31,31 +44,175 @@ func main() {
 		panic(err)
 	}
 
-	db, err := sql.Open(cfg.DBDriver, fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName))
+	poolConfig := database.PoolConfig{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+	}
+	retryConfig := database.RetryConfig{
+		Attempts:       cfg.DBConnectAttempts,
+		InitialBackoff: cfg.DBConnectBackoff,
+		MaxBackoff:     cfg.DBConnectMaxBackoff,
+	}
+
+	db, err := database.OpenDB(context.Background(), cfg.DBDriver, fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName), poolConfig, retryConfig)
 	if err != nil {
 		panic(err)
 	}
 	defer db.Close()
 
-	rabbitMQChannel := getRabbitMQChannel(cfg.RMQUser, cfg.RMQPassword, cfg.RMQHost, cfg.RMQPort)
+	dbRouter := database.NewDBRouter(db, getReplicaDB(cfg.DBDriver, cfg.DBReplicaDSN, poolConfig))
+	go dbRouter.MonitorReplica(context.Background(), cfg.DBReplicaCheck)
 
-	eventDispatcher := events.NewEventDispatcher()
-	eventDispatcher.Register("OrderCreated", &handler.OrderCreatedHandler{
-		RabbitMQChannel: rabbitMQChannel,
-	})
+	orderRepository, orderHistoryRepository := getOrderRepositories(dbRouter, cfg.OrderStorage, cfg.OrderSnapshotEvery)
+	orderRepository = getCachedOrderRepository(orderRepository, cfg.OrderCacheEnabled, cfg.OrderCacheSize, cfg.OrderCacheTTL)
+
+	deadLetterRepository := database.NewDeadLetterRepository(db)
+	eventLogRepository := database.NewEventLogRepository(db)
+	scheduleRepository := database.NewScheduleRepository(db)
+	inboxRepository := database.NewInboxRepository(db)
+	eventRegistry := event.NewSchemaRegistry()
+	eventDecoders := eventRegistry.Decoders()
+	retryPolicy := events.RetryPolicy{
+		MaxAttempts:    cfg.EventRetryAttempts,
+		InitialBackoff: cfg.EventRetryBackoff,
+		MaxBackoff:     cfg.EventRetryMaxBackoff,
+		Jitter:         cfg.EventRetryJitter,
+	}
+	handlerMetrics := events.NewHandlerMetrics()
+	eventRoutes, topology := getRabbitMQTopology(cfg.RMQExchange, cfg.RMQExchangeType, cfg.RMQQueueBindings, cfg.EventRoutes, messaging.Queue{
+		Name:            cfg.WorkerQueue,
+		DeadLetterQueue: cfg.WorkerDeadLetters,
+	}, cfg.WorkerRoutingKey)
+	rabbitMQConfig := messaging.ConnectionConfig{
+		URL:             fmt.Sprintf("amqp://%s:%s@%s:%s/", cfg.RMQUser, cfg.RMQPassword, cfg.RMQHost, cfg.RMQPort),
+		ConnectAttempts: cfg.RMQConnectAttempts,
+		InitialBackoff:  cfg.RMQReconnectBackoff,
+		MaxBackoff:      cfg.RMQReconnectMax,
+		ConfirmTimeout:  cfg.RMQConfirmTimeout,
+		OutageBuffer:    cfg.RMQOutageBuffer,
+		Topology:        topology.Declare,
+	}
+	registerEventHandlers := func(eventDispatcher *events.EventDispatcher) *events.EventDispatcher {
+		publisher := getRabbitMQConnection(cfg.RMQOutageMode, rabbitMQConfig)
+		eventDispatcher.Use(
+			events.Tracing(events.NewLogTracer(slog.Default())),
+			events.Logging(slog.Default()),
+			events.Timing(handlerMetrics),
+			events.Timeout(cfg.EventHandlerTimeout),
+			events.Recovery(),
+		)
+		eventDispatcher.SetDeadLetterSink(deadLetterRepository)
+		if cfg.EventLogEnabled {
+			eventDispatcher.SetEventStore(eventLogRepository, func(event events.EventInterface, err error) {
+				slog.Error("event not logged", slog.String("event", event.GetName()), slog.String("event_id", events.MetadataOf(event).ID), slog.Any("error", err))
+			})
+		}
+		orderCreatedRoute := eventRoutes.For(event.OrderCreatedName)
+		events.Subscribe(eventDispatcher, event.OrderCreatedName, handler.NewOrderCreatedHandler(publisher, handler.Route{
+			Exchange:   orderCreatedRoute.Exchange,
+			RoutingKey: orderCreatedRoute.RoutingKey,
+		}), events.WithRetry(retryPolicy))
+		return eventDispatcher
+	}
+
+	if len(os.Args) > 1 {
+		orderArchiver := getOrderArchiver(db, cfg.OrderStorage, cfg.OrderSnapshotEvery, cfg.OrderArchiveDir, orderRepository)
+		orderArchiver.BatchSize = cfg.OrderArchiveBatch
+		commands := map[string]command{
+			"archive":     archiveCommand(orderArchiver, cfg.OrderArchiveAfter),
+			"restore":     restoreCommand(orderArchiver),
+			"export":      exportCommand(NewExportOrdersUseCase(orderRepository)),
+			"import":      importCommand(NewImportOrdersUseCase(orderRepository, cfg.OrderImportBatchSize)),
+			"deadletters": deadLettersCommand(deadLetterRepository),
+			"redispatch": redispatchCommand(deadLetterRepository, eventDecoders, func() *events.EventDispatcher {
+				return registerEventHandlers(events.NewEventDispatcher())
+			}),
+			"replay": replayCommand(eventLogRepository, eventDecoders, func() *events.EventDispatcher {
+				return registerEventHandlers(events.NewEventDispatcher())
+			}),
+			// Only stores the event, the scheduler of the application
+			// dispatches it.
+			"schedule":    scheduleCommand(events.NewScheduler(scheduleRepository, nil, eventDecoders, events.SchedulerConfig{}), eventRegistry),
+			"schedules":   schedulesCommand(scheduleRepository),
+			"unschedule":  unscheduleCommand(scheduleRepository),
+			"purge-inbox": purgeInboxCommand(inboxRepository, cfg.EventInboxRetention),
+			"worker": workerCommand(messaging.ConsumerConfig{
+				Queue:       cfg.WorkerQueue,
+				Prefetch:    cfg.WorkerPrefetch,
+				Concurrency: cfg.WorkerConcurrency,
+				RetryDelay:  cfg.WorkerRetryDelay,
+				MaxAttempts: cfg.WorkerMaxAttempts,
+			}, cfg.WorkerShutdown, func(ctx context.Context, config messaging.ConsumerConfig) error {
+				eventDispatcher := registerEventHandlers(getEventDispatcher(cfg.EventDispatchMode, cfg.EventWorkers, cfg.EventQueueSize, cfg.EventBackpressure))
+				defer eventDispatcher.Close()
+				// Consumers get a connection of their own, so the broker
+				// throttling the publishes does not hold back the acks.
+				consumer := getRabbitMQConnection(cfg.RMQOutageMode, rabbitMQConfig)
+				defer consumer.Close()
+
+				createOrderHandler := worker.NewCreateOrderHandler(NewCreateOrderUseCase(orderRepository, eventDispatcher), orderRepository)
+				return consumer.Consume(ctx, config, worker.NewDeliveryHandler(worker.CreateOrderCommand, worker.NewSchemaRegistry(),
+					events.NewInboxHandler(inboxRepository, events.Typed[dto.OrderInputDTO](createOrderHandler))))
+			}),
+		}
+		if err := runCommand(context.Background(), commands, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	createOrderUseCase := NewCreateOrderUseCase(db, eventDispatcher)
-	listOrderUseCase := NewListOrderUseCase(db)
+	// The servers stop on SIGINT or SIGTERM, so main returns and the deferred
+	// Close drains the events still queued.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	eventDispatcher := registerEventHandlers(getEventDispatcher(cfg.EventDispatchMode, cfg.EventWorkers, cfg.EventQueueSize, cfg.EventBackpressure))
+	defer eventDispatcher.Close()
+
+	if cfg.SchedulerEnabled {
+		scheduler := events.NewScheduler(scheduleRepository, eventDispatcher, eventDecoders, events.SchedulerConfig{
+			PollInterval: cfg.SchedulerInterval,
+			BatchSize:    cfg.SchedulerBatchSize,
+		})
+		go scheduler.Run(ctx)
+		log.Printf("Event scheduler enabled (poll interval=%s)", cfg.SchedulerInterval)
+	}
+
+	createOrderUseCase := NewCreateOrderUseCase(orderRepository, eventDispatcher)
+	listOrderUseCase := NewListOrderUseCase(orderRepository)
+	getOrderHistoryUseCase := NewGetOrderHistoryUseCase(orderRepository, orderHistoryRepository)
 
 	webserver := webserver.NewWebServer(cfg.WebServerPort)
-	webOrderHandler := NewWebOrderHandler(db, eventDispatcher)
+	webOrderHandler := NewWebOrderHandler(orderRepository, eventDispatcher)
+	webOrderHistoryHandler := NewWebOrderHistoryHandler(orderRepository, orderHistoryRepository)
+	webOrderBulkHandler := NewWebOrderBulkHandler(orderRepository, cfg.OrderImportBatchSize)
+	webEventMetricsHandler := web.NewWebEventMetricsHandler(handlerMetrics)
+	tenantClaimMiddleware, tenantClaimInterceptor := getTenantClaims(cfg.AuthJWTSecret, cfg.AuthRequireClaim)
+	webserver.AddMiddleware(tenantClaimMiddleware)
+	webserver.AddMiddleware(web.TenantMiddleware)
+	webserver.AddMiddleware(web.ActorMiddleware)
+	webserver.AddMiddleware(web.CorrelationMiddleware)
 	webserver.AddHandler("/order", webOrderHandler.Create, "POST")
 	webserver.AddHandler("/order", webOrderHandler.List, "GET")
+	webserver.AddHandler("/order/{id}/history", webOrderHistoryHandler.List, "GET")
+	webserver.AddHandler("/orders/export", webOrderBulkHandler.Export, "GET")
+	webserver.AddHandler("/orders/import", webOrderBulkHandler.Import, "POST")
+	webserver.AddHandler("/events/metrics", webEventMetricsHandler.List, "GET")
+	if orderCache, ok := orderRepository.(*database.CachedOrderRepository); ok {
+		webserver.AddHandler("/orders/cache/metrics", web.NewWebCacheMetricsHandler(orderCache).Get, "GET")
+	}
 	fmt.Println("Starting web server on port", cfg.WebServerPort)
-	go webserver.Start()
+	go func() {
+		if err := webserver.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Web server failed: %v", err)
+			stop()
+		}
+	}()
 
-	grpcServer := grpc.NewServer()
-	orderService := service.NewOrderService(*createOrderUseCase, *listOrderUseCase)
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(tenantClaimInterceptor, service.TenantUnaryInterceptor, service.ActorUnaryInterceptor, service.CorrelationUnaryInterceptor))
+	orderService := service.NewOrderService(*createOrderUseCase, *listOrderUseCase, *getOrderHistoryUseCase)
 	pb.RegisterOrderServiceServer(grpcServer, orderService)
 	reflection.Register(grpcServer)
 
@@ -67,38 +224,159 @@ func main() {
 	go grpcServer.Serve(lis)
 
 	srv := graphql_handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
-		CreateOrderUseCase: *createOrderUseCase,
-		ListOrderUseCase:   *listOrderUseCase,
+		CreateOrderUseCase:     *createOrderUseCase,
+		ListOrderUseCase:       *listOrderUseCase,
+		GetOrderHistoryUseCase: *getOrderHistoryUseCase,
 	}}))
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", tenantClaimMiddleware(web.TenantMiddleware(web.ActorMiddleware(web.CorrelationMiddleware(srv)))))
 
 	fmt.Println("Starting GraphQL server on port", cfg.GraphQLServerPort)
-	http.ListenAndServe(":"+cfg.GraphQLServerPort, nil)
+	graphqlServer := &http.Server{Addr: ":" + cfg.GraphQLServerPort}
+	go func() {
+		if err := graphqlServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("GraphQL server failed: %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Printf("Shutting down, waiting up to %s for the requests in progress", cfg.ShutdownTimeout)
+	shutdownServers(cfg.ShutdownTimeout, webserver, graphqlServer, grpcServer)
+	log.Printf("Waiting for the events being dispatched")
 }
 
-func getRabbitMQChannel(user, password, host, port string) *amqp.Channel {
-	connectionString := fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port)
+// shutdownServers stops the servers from taking new requests and waits up to
+// timeout for the ones in progress, then drops them.
+func shutdownServers(timeout time.Duration, webServer *webserver.WebServer, graphqlServer *http.Server, grpcServer *grpc.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	var conn *amqp.Connection
-	var err error
+	if err := webServer.Shutdown(ctx); err != nil {
+		log.Printf("Web server did not stop gracefully: %v", err)
+	}
+	if err := graphqlServer.Shutdown(ctx); err != nil {
+		log.Printf("GraphQL server did not stop gracefully: %v", err)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+}
 
-	for i := 0; i < 5; i++ {
-		conn, err = amqp.Dial(connectionString)
-		if err == nil {
-			break
+// getTenantClaims reads the tenant from the claim of the bearer tokens signed
+// with secret, ahead of the tenant headers, or instead of them when the claim
+// is required. Without a secret the tenant only comes from the headers.
+func getTenantClaims(secret string, requireClaim bool) (func(http.Handler) http.Handler, grpc.UnaryServerInterceptor) {
+	if secret == "" && requireClaim {
+		panic("AUTH_REQUIRE_TENANT_CLAIM needs AUTH_JWT_SECRET to verify the tokens")
+	}
+	if secret == "" {
+		headersOnly := func(next http.Handler) http.Handler { return next }
+		metadataOnly := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(ctx, req)
 		}
-		log.Printf("Failed to connect to RabbitMQ. Retrying in 5 seconds...")
-		time.Sleep(5 * time.Second)
+		return headersOnly, metadataOnly
 	}
+	verifier := auth.NewTokenVerifier(secret)
+	verifier.RequireTenant = requireClaim
+	return web.TenantClaimMiddleware(verifier), service.TenantClaimUnaryInterceptor(verifier)
+}
 
+func getReplicaDB(driver, dsn string, poolConfig database.PoolConfig) *sql.DB {
+	if dsn == "" {
+		return nil
+	}
+	replica, err := sql.Open(driver, dsn)
+	if err != nil {
+		log.Printf("Failed to open read replica, reads will use the primary: %v", err)
+		return nil
+	}
+	poolConfig.Apply(replica)
+	log.Printf("Read replica configured, list queries will be routed to it")
+	return replica
+}
+
+func getOrderRepositories(dbRouter *database.DBRouter, storage string, snapshotEvery int) (entity.OrderRepositoryInterface, entity.OrderHistoryRepositoryInterface) {
+	if storage == "eventsourced" {
+		log.Printf("Using event-sourced order storage (snapshot every %d events)", snapshotEvery)
+		eventSourcedOrderRepository := database.NewEventSourcedOrderRepository(dbRouter.Primary(), snapshotEvery)
+		return eventSourcedOrderRepository, eventSourcedOrderRepository
+	}
+	return NewOrderRepository(dbRouter), NewOrderHistoryRepository(dbRouter.Primary())
+}
+
+func getOrderArchiver(db *sql.DB, storage string, snapshotEvery int, dir string, orderRepository entity.OrderRepositoryInterface) *database.OrderArchiver {
+	archiver := database.NewOrderArchiver(db, dir)
+	if storage == "eventsourced" {
+		archiver = database.NewEventSourcedOrderArchiver(database.NewEventSourcedOrderRepository(db, snapshotEvery), dir)
+	}
+	if orderCache, ok := orderRepository.(*database.CachedOrderRepository); ok {
+		archiver.Invalidate = orderCache.Invalidate
+	}
+	return archiver
+}
+
+func getCachedOrderRepository(orderRepository entity.OrderRepositoryInterface, cacheEnabled bool, cacheSize int, cacheTTL time.Duration) entity.OrderRepositoryInterface {
+	if cacheEnabled {
+		orderRepository = database.NewCachedOrderRepository(orderRepository, cacheSize, cacheTTL)
+		log.Printf("Order cache enabled (size=%d, ttl=%s)", cacheSize, cacheTTL)
+	}
+	return orderRepository
+}
+
+func getEventDispatcher(mode string, workers, queueSize int, backpressure string) *events.EventDispatcher {
+	if mode != "async" {
+		return events.NewEventDispatcher()
+	}
+	parsedBackpressure, err := events.ParseBackpressure(backpressure)
+	if err != nil {
+		panic(err)
+	}
+	log.Printf("Async event dispatch enabled (workers=%d, queue=%d, backpressure=%s)", workers, queueSize, parsedBackpressure)
+	return events.NewAsyncEventDispatcher(events.AsyncConfig{
+		Workers:      workers,
+		QueueSize:    queueSize,
+		Backpressure: parsedBackpressure,
+	})
+}
+
+func getRabbitMQConnection(outageMode string, config messaging.ConnectionConfig) *messaging.Connection {
+	parsedOutageMode, err := messaging.ParseOutageMode(outageMode)
 	if err != nil {
 		panic(err)
 	}
-	ch, err := conn.Channel()
+	config.OutageMode = parsedOutageMode
+	connection, err := messaging.Dial(context.Background(), config)
 	if err != nil {
 		panic(err)
 	}
+	return connection
+}
 
-	return ch
+func getRabbitMQTopology(exchange, exchangeType, queueBindings, routes string, commandQueue messaging.Queue, commandKey string) (messaging.Routes, messaging.TopologyConfig) {
+	kind, err := messaging.ParseExchangeKind(exchangeType)
+	if err != nil {
+		panic(err)
+	}
+	eventRoutes, err := messaging.ParseRoutes(routes, exchange)
+	if err != nil {
+		panic(err)
+	}
+	bindings, err := messaging.ParseBindings(queueBindings, exchange)
+	if err != nil {
+		panic(err)
+	}
+	return eventRoutes, messaging.TopologyConfig{
+		Exchanges: eventRoutes.Exchanges(kind),
+		Queues:    []messaging.Queue{commandQueue},
+		Bindings:  append(bindings, messaging.Binding{Queue: commandQueue.Name, Exchange: exchange, Key: commandKey}),
+	}
 }
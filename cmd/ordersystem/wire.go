@@ -22,38 +22,82 @@ var setOrderRepositoryDependency = wire.NewSet(
 
 var setEventDispatcherDependency = wire.NewSet(
 	events.NewEventDispatcher,
-	event.NewOrderCreated,
-	wire.Bind(new(events.EventInterface), new(*event.OrderCreated)),
+	event.NewOrderCreatedFactory,
 	wire.Bind(new(events.EventDispatcherInterface), new(*events.EventDispatcher)),
 )
 
 var setOrderCreatedEvent = wire.NewSet(
-	event.NewOrderCreated,
-	wire.Bind(new(events.EventInterface), new(*event.OrderCreated)),
+	event.NewOrderCreatedFactory,
 )
 
-func NewCreateOrderUseCase(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
+func NewOrderRepository(router *database.DBRouter) *database.OrderRepository {
+	wire.Build(
+		database.NewRoutedOrderRepository,
+	)
+	return &database.OrderRepository{}
+}
+
+func NewCreateOrderUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
 	wire.Build(
-		setOrderRepositoryDependency,
 		setOrderCreatedEvent,
 		usecase.NewCreateOrderUseCase,
 	)
 	return &usecase.CreateOrderUseCase{}
 }
 
-func NewListOrderUseCase(db *sql.DB) *usecase.ListOrderUseCase {
+func NewListOrderUseCase(orderRepository entity.OrderRepositoryInterface) *usecase.ListOrderUseCase {
 	wire.Build(
-		setOrderRepositoryDependency,
 		usecase.NewListOrderUseCase,
 	)
 	return &usecase.ListOrderUseCase{}
 }
 
-func NewWebOrderHandler(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
+func NewWebOrderHandler(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
 	wire.Build(
-		setOrderRepositoryDependency,
 		setOrderCreatedEvent,
 		web.NewWebOrderHandler,
 	)
 	return &web.WebOrderHandler{}
 }
+
+func NewOrderHistoryRepository(db *sql.DB) *database.OrderHistoryRepository {
+	wire.Build(
+		database.NewOrderHistoryRepository,
+	)
+	return &database.OrderHistoryRepository{}
+}
+
+func NewGetOrderHistoryUseCase(orderRepository entity.OrderRepositoryInterface, orderHistoryRepository entity.OrderHistoryRepositoryInterface) *usecase.GetOrderHistoryUseCase {
+	wire.Build(
+		usecase.NewGetOrderHistoryUseCase,
+	)
+	return &usecase.GetOrderHistoryUseCase{}
+}
+
+func NewWebOrderHistoryHandler(orderRepository entity.OrderRepositoryInterface, orderHistoryRepository entity.OrderHistoryRepositoryInterface) *web.WebOrderHistoryHandler {
+	wire.Build(
+		web.NewWebOrderHistoryHandler,
+	)
+	return &web.WebOrderHistoryHandler{}
+}
+
+func NewExportOrdersUseCase(orderRepository entity.OrderRepositoryInterface) *usecase.ExportOrdersUseCase {
+	wire.Build(
+		usecase.NewExportOrdersUseCase,
+	)
+	return &usecase.ExportOrdersUseCase{}
+}
+
+func NewImportOrdersUseCase(orderRepository entity.OrderRepositoryInterface, batchSize int) *usecase.ImportOrdersUseCase {
+	wire.Build(
+		usecase.NewImportOrdersUseCase,
+	)
+	return &usecase.ImportOrdersUseCase{}
+}
+
+func NewWebOrderBulkHandler(orderRepository entity.OrderRepositoryInterface, importBatchSize int) *web.WebOrderBulkHandler {
+	wire.Build(
+		web.NewWebOrderBulkHandler,
+	)
+	return &web.WebOrderBulkHandler{}
+}
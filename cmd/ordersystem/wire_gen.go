@@ -23,30 +23,62 @@ import (
 
 // Injectors from wire.go:
 
-func NewCreateOrderUseCase(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
-	orderRepository := database.NewOrderRepository(db)
-	orderCreated := event.NewOrderCreated()
-	createOrderUseCase := usecase.NewCreateOrderUseCase(orderRepository, orderCreated, eventDispatcher)
+func NewOrderRepository(router *database.DBRouter) *database.OrderRepository {
+	orderRepository := database.NewRoutedOrderRepository(router)
+	return orderRepository
+}
+
+func NewCreateOrderUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
+	eventFactory := event.NewOrderCreatedFactory()
+	createOrderUseCase := usecase.NewCreateOrderUseCase(orderRepository, eventFactory, eventDispatcher)
 	return createOrderUseCase
 }
 
-func NewListOrderUseCase(db *sql.DB) *usecase.ListOrderUseCase {
-	orderRepository := database.NewOrderRepository(db)
+func NewListOrderUseCase(orderRepository entity.OrderRepositoryInterface) *usecase.ListOrderUseCase {
 	listOrderUseCase := usecase.NewListOrderUseCase(orderRepository)
 	return listOrderUseCase
 }
 
-func NewWebOrderHandler(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
-	orderRepository := database.NewOrderRepository(db)
-	orderCreated := event.NewOrderCreated()
-	webOrderHandler := web.NewWebOrderHandler(eventDispatcher, orderRepository, orderCreated)
+func NewWebOrderHandler(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
+	eventFactory := event.NewOrderCreatedFactory()
+	webOrderHandler := web.NewWebOrderHandler(eventDispatcher, orderRepository, eventFactory)
 	return webOrderHandler
 }
 
+func NewOrderHistoryRepository(db *sql.DB) *database.OrderHistoryRepository {
+	orderHistoryRepository := database.NewOrderHistoryRepository(db)
+	return orderHistoryRepository
+}
+
+func NewGetOrderHistoryUseCase(orderRepository entity.OrderRepositoryInterface, orderHistoryRepository entity.OrderHistoryRepositoryInterface) *usecase.GetOrderHistoryUseCase {
+	getOrderHistoryUseCase := usecase.NewGetOrderHistoryUseCase(orderRepository, orderHistoryRepository)
+	return getOrderHistoryUseCase
+}
+
+func NewWebOrderHistoryHandler(orderRepository entity.OrderRepositoryInterface, orderHistoryRepository entity.OrderHistoryRepositoryInterface) *web.WebOrderHistoryHandler {
+	webOrderHistoryHandler := web.NewWebOrderHistoryHandler(orderRepository, orderHistoryRepository)
+	return webOrderHistoryHandler
+}
+
+func NewExportOrdersUseCase(orderRepository entity.OrderRepositoryInterface) *usecase.ExportOrdersUseCase {
+	exportOrdersUseCase := usecase.NewExportOrdersUseCase(orderRepository)
+	return exportOrdersUseCase
+}
+
+func NewImportOrdersUseCase(orderRepository entity.OrderRepositoryInterface, batchSize int) *usecase.ImportOrdersUseCase {
+	importOrdersUseCase := usecase.NewImportOrdersUseCase(orderRepository, batchSize)
+	return importOrdersUseCase
+}
+
+func NewWebOrderBulkHandler(orderRepository entity.OrderRepositoryInterface, importBatchSize int) *web.WebOrderBulkHandler {
+	webOrderBulkHandler := web.NewWebOrderBulkHandler(orderRepository, importBatchSize)
+	return webOrderBulkHandler
+}
+
 // wire.go:
 
 var setOrderRepositoryDependency = wire.NewSet(database.NewOrderRepository, wire.Bind(new(entity.OrderRepositoryInterface), new(*database.OrderRepository)))
 
-var setEventDispatcherDependency = wire.NewSet(events.NewEventDispatcher, event.NewOrderCreated, wire.Bind(new(events.EventInterface), new(*event.OrderCreated)), wire.Bind(new(events.EventDispatcherInterface), new(*events.EventDispatcher)))
+var setEventDispatcherDependency = wire.NewSet(events.NewEventDispatcher, event.NewOrderCreatedFactory, wire.Bind(new(events.EventDispatcherInterface), new(*events.EventDispatcher)))
 
-var setOrderCreatedEvent = wire.NewSet(event.NewOrderCreated, wire.Bind(new(events.EventInterface), new(*event.OrderCreated)))
+var setOrderCreatedEvent = wire.NewSet(event.NewOrderCreatedFactory)
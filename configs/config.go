@@ -1,21 +1,78 @@
 package configs
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type conf struct {
-	DBDriver          string `mapstructure:"DB_DRIVER"`
-	DBHost            string `mapstructure:"DB_HOST"`
-	DBPort            string `mapstructure:"DB_PORT"`
-	DBUser            string `mapstructure:"DB_USER"`
-	DBPassword        string `mapstructure:"DB_PASSWORD"`
-	DBName            string `mapstructure:"DB_NAME"`
-	RMQHost           string `mapstructure:"RABBITMQ_HOST"`
-	RMQPort           string `mapstructure:"RABBITMQ_PORT"`
-	RMQUser           string `mapstructure:"RABBITMQ_USER"`
-	RMQPassword       string `mapstructure:"RABBITMQ_PASSWORD"`
-	WebServerPort     string `mapstructure:"WEB_SERVER_PORT"`
-	GRPCServerPort    string `mapstructure:"GRPC_SERVER_PORT"`
-	GraphQLServerPort string `mapstructure:"GRAPHQL_SERVER_PORT"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBHost               string        `mapstructure:"DB_HOST"`
+	DBPort               string        `mapstructure:"DB_PORT"`
+	DBUser               string        `mapstructure:"DB_USER"`
+	DBPassword           string        `mapstructure:"DB_PASSWORD"`
+	DBName               string        `mapstructure:"DB_NAME"`
+	DBMaxOpenConns       int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns       int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime    time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime    time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+	DBConnectAttempts    int           `mapstructure:"DB_CONNECT_ATTEMPTS"`
+	DBConnectBackoff     time.Duration `mapstructure:"DB_CONNECT_BACKOFF"`
+	DBConnectMaxBackoff  time.Duration `mapstructure:"DB_CONNECT_MAX_BACKOFF"`
+	DBReplicaDSN         string        `mapstructure:"DB_REPLICA_DSN"`
+	DBReplicaCheck       time.Duration `mapstructure:"DB_REPLICA_HEALTH_CHECK_INTERVAL"`
+	RMQHost              string        `mapstructure:"RABBITMQ_HOST"`
+	RMQPort              string        `mapstructure:"RABBITMQ_PORT"`
+	RMQUser              string        `mapstructure:"RABBITMQ_USER"`
+	RMQPassword          string        `mapstructure:"RABBITMQ_PASSWORD"`
+	RMQConfirmTimeout    time.Duration `mapstructure:"RABBITMQ_CONFIRM_TIMEOUT"`
+	RMQConnectAttempts   int           `mapstructure:"RABBITMQ_CONNECT_ATTEMPTS"`
+	RMQReconnectBackoff  time.Duration `mapstructure:"RABBITMQ_RECONNECT_INITIAL_BACKOFF"`
+	RMQReconnectMax      time.Duration `mapstructure:"RABBITMQ_RECONNECT_MAX_BACKOFF"`
+	RMQOutageMode        string        `mapstructure:"RABBITMQ_OUTAGE_MODE"`
+	RMQOutageBuffer      int           `mapstructure:"RABBITMQ_OUTAGE_BUFFER"`
+	RMQExchange          string        `mapstructure:"RABBITMQ_EXCHANGE"`
+	RMQExchangeType      string        `mapstructure:"RABBITMQ_EXCHANGE_TYPE"`
+	RMQQueueBindings     string        `mapstructure:"RABBITMQ_QUEUE_BINDINGS"`
+	EventRoutes          string        `mapstructure:"EVENT_ROUTES"`
+	WebServerPort        string        `mapstructure:"WEB_SERVER_PORT"`
+	GRPCServerPort       string        `mapstructure:"GRPC_SERVER_PORT"`
+	GraphQLServerPort    string        `mapstructure:"GRAPHQL_SERVER_PORT"`
+	OrderStorage         string        `mapstructure:"ORDER_STORAGE"`
+	OrderSnapshotEvery   int           `mapstructure:"ORDER_SNAPSHOT_EVERY"`
+	OrderCacheEnabled    bool          `mapstructure:"ORDER_CACHE_ENABLED"`
+	OrderCacheSize       int           `mapstructure:"ORDER_CACHE_SIZE"`
+	OrderCacheTTL        time.Duration `mapstructure:"ORDER_CACHE_TTL"`
+	OrderArchiveDir      string        `mapstructure:"ORDER_ARCHIVE_DIR"`
+	OrderArchiveAfter    time.Duration `mapstructure:"ORDER_ARCHIVE_AFTER"`
+	OrderArchiveBatch    int           `mapstructure:"ORDER_ARCHIVE_BATCH_SIZE"`
+	OrderImportBatchSize int           `mapstructure:"ORDER_IMPORT_BATCH_SIZE"`
+	EventDispatchMode    string        `mapstructure:"EVENT_DISPATCH_MODE"`
+	EventWorkers         int           `mapstructure:"EVENT_WORKERS"`
+	EventQueueSize       int           `mapstructure:"EVENT_QUEUE_SIZE"`
+	EventBackpressure    string        `mapstructure:"EVENT_BACKPRESSURE"`
+	EventRetryAttempts   int           `mapstructure:"EVENT_RETRY_MAX_ATTEMPTS"`
+	EventRetryBackoff    time.Duration `mapstructure:"EVENT_RETRY_INITIAL_BACKOFF"`
+	EventRetryMaxBackoff time.Duration `mapstructure:"EVENT_RETRY_MAX_BACKOFF"`
+	EventRetryJitter     float64       `mapstructure:"EVENT_RETRY_JITTER"`
+	EventHandlerTimeout  time.Duration `mapstructure:"EVENT_HANDLER_TIMEOUT"`
+	EventLogEnabled      bool          `mapstructure:"EVENT_LOG_ENABLED"`
+	EventInboxRetention  time.Duration `mapstructure:"EVENT_INBOX_RETENTION"`
+	SchedulerEnabled     bool          `mapstructure:"SCHEDULER_ENABLED"`
+	SchedulerInterval    time.Duration `mapstructure:"SCHEDULER_POLL_INTERVAL"`
+	SchedulerBatchSize   int           `mapstructure:"SCHEDULER_BATCH_SIZE"`
+	WorkerQueue          string        `mapstructure:"WORKER_QUEUE"`
+	WorkerRoutingKey     string        `mapstructure:"WORKER_ROUTING_KEY"`
+	WorkerDeadLetters    string        `mapstructure:"WORKER_DEAD_LETTER_QUEUE"`
+	WorkerPrefetch       int           `mapstructure:"WORKER_PREFETCH"`
+	WorkerConcurrency    int           `mapstructure:"WORKER_CONCURRENCY"`
+	WorkerShutdown       time.Duration `mapstructure:"WORKER_SHUTDOWN_TIMEOUT"`
+	WorkerRetryDelay     time.Duration `mapstructure:"WORKER_RETRY_DELAY"`
+	WorkerMaxAttempts    int           `mapstructure:"WORKER_MAX_ATTEMPTS"`
+	ShutdownTimeout      time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	AuthJWTSecret        string        `mapstructure:"AUTH_JWT_SECRET"`
+	AuthRequireClaim     bool          `mapstructure:"AUTH_REQUIRE_TENANT_CLAIM"`
 }
 
 func LoadConfig(path string) (*conf, error) {
@@ -17,3 +17,29 @@ type OrderOutputDTO struct {
 type OrdersOutputDTO struct {
 	Orders []OrderOutputDTO `json:"orders"`
 }
+
+type OrderHistoryOutputDTO struct {
+	ID         int64           `json:"id"`
+	OrderID    string          `json:"order_id"`
+	Actor      string          `json:"actor"`
+	Operation  string          `json:"operation"`
+	OccurredAt string          `json:"occurred_at"`
+	Before     *OrderOutputDTO `json:"before"`
+	After      *OrderOutputDTO `json:"after"`
+}
+
+type OrderHistoryListOutputDTO struct {
+	History []OrderHistoryOutputDTO `json:"history"`
+}
+
+type OrderImportErrorDTO struct {
+	Line  int    `json:"line"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+type OrderImportOutputDTO struct {
+	Imported int                   `json:"imported"`
+	Failed   int                   `json:"failed"`
+	Errors   []OrderImportErrorDTO `json:"errors"`
+}
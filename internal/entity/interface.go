@@ -1,7 +1,18 @@
 package entity
 
+import "context"
+
 type OrderRepositoryInterface interface {
-	Save(order *Order) error
-	List() ([]Order, error)
+	Save(ctx context.Context, order *Order) error
+	Update(ctx context.Context, order *Order) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*Order, error)
+	List(ctx context.Context) ([]Order, error)
+	Stream(ctx context.Context, fn func(order Order) error) error
+	SaveBatch(ctx context.Context, orders []Order) error
 	// GetTotal() (int, error)
 }
+
+type OrderHistoryRepositoryInterface interface {
+	ListByOrderID(ctx context.Context, orderID string) ([]OrderHistory, error)
+}
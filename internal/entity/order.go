@@ -5,6 +5,11 @@ import (
 	"time"
 )
 
+var (
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrOrderAlreadyExists = errors.New("order already exists")
+)
+
 type Order struct {
 	ID         string
 	Price      float64
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OrderOperationCreate = "create"
+	OrderOperationUpdate = "update"
+	OrderOperationDelete = "delete"
+	// Archived orders leave the live storage and come back when restored.
+	OrderOperationArchive = "archive"
+	OrderOperationRestore = "restore"
+
+	AnonymousActor = "anonymous"
+)
+
+type OrderHistory struct {
+	ID         int64
+	OrderID    string
+	Actor      string
+	Operation  string
+	OccurredAt time.Time
+	Before     *Order
+	After      *Order
+}
+
+type actorContextKey struct{}
+
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	if actor == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenAContextWithoutActor_WhenIGetTheActor_ThenIShouldReceiveAnonymous(t *testing.T) {
+	assert.Equal(t, AnonymousActor, ActorFromContext(context.Background()))
+}
+
+func TestGivenAContextWithActor_WhenIGetTheActor_ThenIShouldReceiveTheActor(t *testing.T) {
+	ctx := ContextWithActor(context.Background(), "john.doe")
+	assert.Equal(t, "john.doe", ActorFromContext(ctx))
+}
+
+func TestGivenAnEmptyActor_WhenISetTheActor_ThenIShouldKeepTheContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, ContextWithActor(ctx, ""))
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "context"
+
+const DefaultTenant = "default"
+
+type tenantContextKey struct{}
+
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	if tenant == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// LookupTenant reports the tenant set on the context, if any, so callers can
+// avoid overriding a tenant already resolved from an authenticated claim.
+func LookupTenant(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+func TenantFromContext(ctx context.Context) string {
+	if tenant, ok := LookupTenant(ctx); ok {
+		return tenant
+	}
+	return DefaultTenant
+}
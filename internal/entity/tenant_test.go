@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenAContextWithoutTenant_WhenIGetTheTenant_ThenIShouldReceiveTheDefaultTenant(t *testing.T) {
+	_, ok := LookupTenant(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, DefaultTenant, TenantFromContext(context.Background()))
+}
+
+func TestGivenAContextWithTenant_WhenIGetTheTenant_ThenIShouldReceiveTheTenant(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "store-a")
+	tenant, ok := LookupTenant(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "store-a", tenant)
+	assert.Equal(t, "store-a", TenantFromContext(ctx))
+}
+
+func TestGivenAnEmptyTenant_WhenISetTheTenant_ThenIShouldKeepTheContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, ContextWithTenant(ctx, ""))
+}
@@ -1,39 +1,50 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"sync"
 
 	"github.com/streadway/amqp"
+	"github.com/vs0uz4/clean_architecture/internal/event"
 	"github.com/vs0uz4/clean_architecture/pkg/events"
 )
 
+// Publisher sends a message to the broker, returning once it is safely
+// there.
+type Publisher interface {
+	Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error
+}
+
+// Route is where an event is published: the exchange and the routing key
+// bindings match it by.
+type Route struct {
+	Exchange   string
+	RoutingKey string
+}
+
 type OrderCreatedHandler struct {
-	RabbitMQChannel *amqp.Channel
+	Publisher Publisher
+	Route     Route
 }
 
-func NewOrderCreatedHandler(rabbitMQChannel *amqp.Channel) *OrderCreatedHandler {
+func NewOrderCreatedHandler(publisher Publisher, route Route) *OrderCreatedHandler {
 	return &OrderCreatedHandler{
-		RabbitMQChannel: rabbitMQChannel,
+		Publisher: publisher,
+		Route:     route,
 	}
 }
 
-func (h *OrderCreatedHandler) Handle(event events.EventInterface, wg *sync.WaitGroup) {
-	defer wg.Done()
-	fmt.Printf("Order created: %v", event.GetPayload())
-	jsonOutput, _ := json.Marshal(event.GetPayload())
-
-	msgRabbitmq := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        jsonOutput,
+func (h *OrderCreatedHandler) Handle(ctx context.Context, event *event.OrderCreated) error {
+	jsonOutput, err := json.Marshal(event.Payload())
+	if err != nil {
+		return events.Permanent(err)
 	}
 
-	h.RabbitMQChannel.Publish(
-		"amq.direct", // exchange
-		"",           // key name
-		false,        // mandatory
-		false,        // immediate
-		msgRabbitmq,  // message to publish
-	)
+	msgRabbitmq := newPublishing(ctx, event, jsonOutput)
+
+	if err := h.Publisher.Publish(ctx, h.Route.Exchange, h.Route.RoutingKey, msgRabbitmq); err != nil {
+		return fmt.Errorf("publishing %s %s: %w", event.GetName(), msgRabbitmq.MessageId, err)
+	}
+	return nil
 }
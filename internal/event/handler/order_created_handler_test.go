@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/event"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+type fakePublisher struct {
+	exchange string
+	key      string
+	messages []amqp.Publishing
+	err      error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	p.exchange, p.key = exchange, key
+	p.messages = append(p.messages, msg)
+	return p.err
+}
+
+func TestOrderCreatedHandler_Handle(t *testing.T) {
+	publisher := &fakePublisher{}
+	orderCreated := event.NewOrderCreated(context.Background(), dto.OrderOutputDTO{ID: "1", Price: 100, Tax: 10, FinalPrice: 110})
+
+	err := NewOrderCreatedHandler(publisher, Route{Exchange: "orders", RoutingKey: "order.created"}).Handle(context.Background(), orderCreated)
+
+	require.NoError(t, err)
+	assert.Equal(t, "orders", publisher.exchange)
+	assert.Equal(t, "order.created", publisher.key)
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, orderCreated.GetMetadata().ID, publisher.messages[0].MessageId)
+	assert.JSONEq(t, `{"id":"1","price":100,"tax":10,"final_price":110,"created_at":""}`, string(publisher.messages[0].Body))
+}
+
+func TestOrderCreatedHandler_Handle_PublishFailure(t *testing.T) {
+	publisher := &fakePublisher{err: assert.AnError}
+	orderCreated := event.NewOrderCreated(context.Background(), dto.OrderOutputDTO{ID: "1"})
+
+	err := NewOrderCreatedHandler(publisher, Route{Exchange: "orders"}).Handle(context.Background(), orderCreated)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.False(t, events.IsPermanent(err), "publish failures are retried")
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/streadway/amqp"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// Headers carrying the parts of the event envelope that have no AMQP
+// property of their own.
+const (
+	CausationIDHeader   = "causation_id"
+	SchemaVersionHeader = "schema_version"
+	TenantIDHeader      = "tenant_id"
+)
+
+// newPublishing maps the event envelope onto the message properties, leaving
+// the body as the bare payload for the existing consumers.
+func newPublishing(ctx context.Context, event events.EventInterface, body []byte) amqp.Publishing {
+	metadata := events.MetadataOf(event)
+	return amqp.Publishing{
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     metadata.ID,
+		CorrelationId: metadata.CorrelationID,
+		Timestamp:     metadata.OccurredAt,
+		Type:          event.GetName(),
+		AppId:         metadata.Source,
+		Headers: amqp.Table{
+			CausationIDHeader:   metadata.CausationID,
+			SchemaVersionHeader: int32(metadata.Version),
+			TenantIDHeader:      entity.TenantFromContext(ctx),
+		},
+		Body: body,
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/event"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+func TestNewPublishing_CarriesEnvelope(t *testing.T) {
+	ctx := entity.ContextWithTenant(context.Background(), "store-a")
+	ctx = events.ContextWithCorrelationID(ctx, "correlation-1")
+	ctx = events.ContextWithCausationID(ctx, "request-1")
+	orderCreated := event.NewOrderCreated(ctx, dto.OrderOutputDTO{ID: "1"})
+	metadata := orderCreated.GetMetadata()
+
+	publishing := newPublishing(ctx, orderCreated, []byte(`{"id":"1"}`))
+
+	assert.Equal(t, metadata.ID, publishing.MessageId)
+	assert.NotEmpty(t, publishing.MessageId)
+	assert.Equal(t, "correlation-1", publishing.CorrelationId)
+	assert.Equal(t, metadata.OccurredAt, publishing.Timestamp)
+	assert.Equal(t, event.OrderCreatedName, publishing.Type)
+	assert.Equal(t, event.Source, publishing.AppId)
+	assert.Equal(t, amqp.Table{
+		CausationIDHeader:   "request-1",
+		SchemaVersionHeader: int32(event.OrderCreatedVersion),
+		TenantIDHeader:      "store-a",
+	}, publishing.Headers)
+	assert.Equal(t, `{"id":"1"}`, string(publishing.Body))
+	assert.Equal(t, amqp.Persistent, publishing.DeliveryMode)
+	assert.NoError(t, publishing.Headers.Validate())
+}
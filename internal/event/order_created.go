@@ -1,30 +1,35 @@
 package event
 
-import "time"
+import (
+	"context"
+	"fmt"
 
-type OrderCreated struct {
-	Name    string
-	Payload interface{}
-}
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
 
-func NewOrderCreated() *OrderCreated {
-	return &OrderCreated{
-		Name: "OrderCreated",
-	}
-}
+const (
+	Source = "ordersystem"
 
-func (e *OrderCreated) GetName() string {
-	return e.Name
-}
+	OrderCreatedName    = "OrderCreated"
+	OrderCreatedVersion = 1
+)
 
-func (e *OrderCreated) GetPayload() interface{} {
-	return e.Payload
-}
+type OrderCreated = events.Event[dto.OrderOutputDTO]
 
-func (e *OrderCreated) SetPayload(payload interface{}) {
-	e.Payload = payload
+func NewOrderCreated(ctx context.Context, payload dto.OrderOutputDTO) *OrderCreated {
+	return events.NewEventWithContext(ctx, OrderCreatedName, payload, events.WithVersion(OrderCreatedVersion), events.WithSource(Source))
 }
 
-func (e *OrderCreated) GetDateTime() time.Time {
-	return time.Now()
+// NewOrderCreatedFactory builds the events for CreateOrderUseCase. A payload
+// other than a dto.OrderOutputDTO is a wiring mistake, which panics instead
+// of publishing an event its consumers cannot decode.
+func NewOrderCreatedFactory() events.EventFactory {
+	return func(ctx context.Context, payload interface{}) events.EventInterface {
+		output, ok := payload.(dto.OrderOutputDTO)
+		if !ok {
+			panic(fmt.Sprintf("%s payload must be a dto.OrderOutputDTO, got %T", OrderCreatedName, payload))
+		}
+		return NewOrderCreated(ctx, output)
+	}
 }
@@ -0,0 +1,24 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+func TestNewOrderCreatedFactory(t *testing.T) {
+	factory := NewOrderCreatedFactory()
+
+	created := factory(context.Background(), dto.OrderOutputDTO{ID: "1", Price: 100, Tax: 10, FinalPrice: 110})
+	require.IsType(t, &OrderCreated{}, created)
+	assert.Equal(t, OrderCreatedVersion, events.MetadataOf(created).Version)
+	assert.Equal(t, Source, events.MetadataOf(created).Source)
+
+	assert.PanicsWithValue(t, "OrderCreated payload must be a dto.OrderOutputDTO, got map[string]string", func() {
+		factory(context.Background(), map[string]string{"id": "1"})
+	})
+}
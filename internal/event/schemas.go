@@ -0,0 +1,18 @@
+package event
+
+import (
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// NewSchemaRegistry registers the current schema of every event of the order
+// system. When a payload changes shape, bump its version constant and register
+// an upcaster from the previous version here, so the events already stored or
+// queued still decode.
+func NewSchemaRegistry() *events.SchemaRegistry {
+	registry := events.NewSchemaRegistry()
+	if err := events.RegisterSchema[dto.OrderOutputDTO](registry, OrderCreatedName, OrderCreatedVersion); err != nil {
+		panic(err)
+	}
+	return registry
+}
@@ -0,0 +1,24 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+func TestNewSchemaRegistry_DecodesOrderCreated(t *testing.T) {
+	registry := NewSchemaRegistry()
+	metadata := events.Metadata{ID: "event-1", OccurredAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), CorrelationID: "request-1", Version: OrderCreatedVersion, Source: Source}
+
+	decoded, err := registry.Decode(OrderCreatedName, metadata, []byte(`{"id":"1","price":100,"tax":10,"final_price":110}`))
+
+	require.NoError(t, err)
+	orderCreated, ok := decoded.(*OrderCreated)
+	require.True(t, ok)
+	assert.Equal(t, dto.OrderOutputDTO{ID: "1", Price: 100, Tax: 10, FinalPrice: 110}, orderCreated.Payload())
+	assert.Equal(t, metadata, orderCreated.GetMetadata())
+}
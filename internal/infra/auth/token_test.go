@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signToken builds an HS256 JWT with the given JSON claims.
+func signToken(secret, header, claims string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestTokenVerifier_Tenant(t *testing.T) {
+	verifier := NewTokenVerifier("secret")
+	verifier.now = func() time.Time { return time.Unix(1700000000, 0) }
+	hs256 := `{"alg":"HS256","typ":"JWT"}`
+
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+		err      error
+	}{
+		{name: "tenant claim", token: signToken("secret", hs256, `{"sub":"user-1","tenant_id":"store-a","exp":1700000060}`), expected: "store-a"},
+		{name: "no tenant claim", token: signToken("secret", hs256, `{"sub":"user-1"}`)},
+		{name: "expired", token: signToken("secret", hs256, `{"tenant_id":"store-a","exp":1700000000}`), err: ErrTokenExpired},
+		{name: "other secret", token: signToken("other", hs256, `{"tenant_id":"store-a"}`), err: ErrInvalidToken},
+		{name: "unsigned", token: signToken("secret", `{"alg":"none"}`, `{"tenant_id":"store-a"}`), err: ErrInvalidToken},
+		{name: "malformed", token: "not-a-token", err: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant, err := verifier.Tenant(tt.token)
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tenant)
+		})
+	}
+}
+
+func TestTokenVerifier_RequireTenant(t *testing.T) {
+	verifier := NewTokenVerifier("secret")
+	verifier.RequireTenant = true
+	hs256 := `{"alg":"HS256","typ":"JWT"}`
+
+	tenant, err := verifier.Tenant(signToken("secret", hs256, `{"tenant_id":"store-a"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "store-a", tenant)
+
+	_, err = verifier.Tenant(signToken("secret", hs256, `{"sub":"user-1"}`))
+	assert.ErrorIs(t, err, ErrTenantClaimRequired)
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "abc.def.ghi", BearerToken("Bearer abc.def.ghi"))
+	assert.Equal(t, "abc.def.ghi", BearerToken("bearer abc.def.ghi"))
+	assert.Equal(t, "", BearerToken("Basic dXNlcjpwYXNz"))
+	assert.Equal(t, "", BearerToken(""))
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/cache"
+)
+
+type CachedOrderRepository struct {
+	Repository entity.OrderRepositoryInterface
+	cache      *cache.LRU[string, []entity.Order]
+}
+
+func NewCachedOrderRepository(repository entity.OrderRepositoryInterface, size int, ttl time.Duration) *CachedOrderRepository {
+	return &CachedOrderRepository{
+		Repository: repository,
+		cache:      cache.NewLRU[string, []entity.Order](size, ttl),
+	}
+}
+
+func (r *CachedOrderRepository) Save(ctx context.Context, order *entity.Order) error {
+	if err := r.Repository.Save(ctx, order); err != nil {
+		return err
+	}
+	r.Invalidate(ctx, order.ID)
+	return nil
+}
+
+func (r *CachedOrderRepository) SaveBatch(ctx context.Context, orders []entity.Order) error {
+	if err := r.Repository.SaveBatch(ctx, orders); err != nil {
+		return err
+	}
+	for _, order := range orders {
+		r.Invalidate(ctx, order.ID)
+	}
+	return nil
+}
+
+func (r *CachedOrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	if err := r.Repository.Update(ctx, order); err != nil {
+		return err
+	}
+	r.Invalidate(ctx, order.ID)
+	return nil
+}
+
+func (r *CachedOrderRepository) Delete(ctx context.Context, id string) error {
+	if err := r.Repository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.Invalidate(ctx, id)
+	return nil
+}
+
+func (r *CachedOrderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	if cached, ok := r.cached(ctx, orderCacheKey(ctx, id)); ok {
+		order := cached[0]
+		return &order, nil
+	}
+
+	order, err := r.Repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(orderCacheKey(ctx, id), []entity.Order{*order})
+	return order, nil
+}
+
+func (r *CachedOrderRepository) List(ctx context.Context) ([]entity.Order, error) {
+	if cached, ok := r.cached(ctx, listOrdersCacheKey(ctx)); ok {
+		return copyOrders(cached), nil
+	}
+
+	orders, err := r.Repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(listOrdersCacheKey(ctx), copyOrders(orders))
+	return orders, nil
+}
+
+func (r *CachedOrderRepository) Stream(ctx context.Context, fn func(order entity.Order) error) error {
+	return r.Repository.Stream(ctx, fn)
+}
+
+func (r *CachedOrderRepository) Stats() cache.Stats {
+	return r.cache.Stats()
+}
+
+// cached skips the cache for primary reads, which then refresh it, as it may
+// hold what a replica returned before the write the caller wants to observe.
+func (r *CachedOrderRepository) cached(ctx context.Context, key string) ([]entity.Order, bool) {
+	if isPrimaryRead(ctx) {
+		return nil, false
+	}
+	return r.cache.Get(key)
+}
+
+// Invalidate drops the order, and the tenant's list, from the cache, for the
+// writes that do not go through the decorator, such as the OrderArchiver's.
+func (r *CachedOrderRepository) Invalidate(ctx context.Context, id string) {
+	r.cache.Delete(orderCacheKey(ctx, id))
+	r.cache.Delete(listOrdersCacheKey(ctx))
+}
+
+// Cache keys are scoped by tenant so cached reads never cross tenants.
+func orderCacheKey(ctx context.Context, id string) string {
+	return "orders:" + entity.TenantFromContext(ctx) + ":id:" + id
+}
+
+func listOrdersCacheKey(ctx context.Context) string {
+	return "orders:" + entity.TenantFromContext(ctx) + ":list"
+}
+
+func copyOrders(orders []entity.Order) []entity.Order {
+	if orders == nil {
+		return nil
+	}
+	return append([]entity.Order(nil), orders...)
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+type countingOrderRepository struct {
+	orders    []entity.Order
+	listCalls int
+	findCalls int
+	err       error
+}
+
+func (r *countingOrderRepository) Save(ctx context.Context, order *entity.Order) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.orders = append(r.orders, *order)
+	return nil
+}
+
+func (r *countingOrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.orders {
+		if r.orders[i].ID == order.ID {
+			r.orders[i] = *order
+			return nil
+		}
+	}
+	return entity.ErrOrderNotFound
+}
+
+func (r *countingOrderRepository) Delete(ctx context.Context, id string) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range r.orders {
+		if r.orders[i].ID == id {
+			r.orders = append(r.orders[:i], r.orders[i+1:]...)
+			return nil
+		}
+	}
+	return entity.ErrOrderNotFound
+}
+
+func (r *countingOrderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	r.findCalls++
+	for _, order := range r.orders {
+		if order.ID == id {
+			found := order
+			return &found, nil
+		}
+	}
+	return nil, entity.ErrOrderNotFound
+}
+
+func (r *countingOrderRepository) List(ctx context.Context) ([]entity.Order, error) {
+	r.listCalls++
+	return r.orders, r.err
+}
+
+func (r *countingOrderRepository) Stream(ctx context.Context, fn func(order entity.Order) error) error {
+	for _, order := range r.orders {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+func (r *countingOrderRepository) SaveBatch(ctx context.Context, orders []entity.Order) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.orders = append(r.orders, orders...)
+	return nil
+}
+
+func TestCachedOrderRepository_List(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingOrderRepository{orders: []entity.Order{{ID: "1", Price: 10, Tax: 1}}}
+	repo := NewCachedOrderRepository(inner, 10, time.Minute)
+
+	orders, err := repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	orders, err = repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	assert.Equal(t, 1, inner.listCalls)
+	assert.Equal(t, uint64(1), repo.Stats().Hits)
+	assert.Equal(t, uint64(1), repo.Stats().Misses)
+}
+
+func TestCachedOrderRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingOrderRepository{orders: []entity.Order{{ID: "1", Price: 10, Tax: 1}}}
+	repo := NewCachedOrderRepository(inner, 10, time.Minute)
+
+	order, err := repo.FindByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", order.ID)
+
+	order.Price = 99
+
+	order, err = repo.FindByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, order.Price)
+	assert.Equal(t, 1, inner.findCalls)
+
+	_, err = repo.FindByID(ctx, "2")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	_, err = repo.FindByID(ctx, "2")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	assert.Equal(t, 3, inner.findCalls)
+}
+
+func TestCachedOrderRepository_PrimaryReadSkipsCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingOrderRepository{orders: []entity.Order{{ID: "1", Price: 10, Tax: 1}}}
+	repo := NewCachedOrderRepository(inner, 10, time.Minute)
+
+	_, err := repo.FindByID(ctx, "1")
+	assert.NoError(t, err)
+	inner.orders[0].Price = 20
+
+	order, err := repo.FindByID(WithPrimaryRead(ctx), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, order.Price)
+	assert.Equal(t, 2, inner.findCalls)
+
+	order, err = repo.FindByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, order.Price, "primary read refreshes the cache")
+	assert.Equal(t, 2, inner.findCalls)
+}
+
+func TestCachedOrderRepository_SaveInvalidatesCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingOrderRepository{}
+	repo := NewCachedOrderRepository(inner, 10, time.Minute)
+
+	orders, err := repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+
+	assert.NoError(t, repo.Save(ctx, &entity.Order{ID: "1", Price: 10, Tax: 1}))
+
+	orders, err = repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, 2, inner.listCalls)
+}
+
+func TestCachedOrderRepository_SaveErrorKeepsCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingOrderRepository{orders: []entity.Order{{ID: "1", Price: 10, Tax: 1}}}
+	repo := NewCachedOrderRepository(inner, 10, time.Minute)
+
+	_, err := repo.List(ctx)
+	assert.NoError(t, err)
+
+	inner.err = assert.AnError
+	assert.Equal(t, assert.AnError, repo.Save(ctx, &entity.Order{ID: "2", Price: 10, Tax: 1}))
+
+	_, err = repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, inner.listCalls)
+}
+
+func TestCachedOrderRepository_UpdateAndDeleteInvalidateCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingOrderRepository{orders: []entity.Order{{ID: "1", Price: 10, Tax: 1}}}
+	repo := NewCachedOrderRepository(inner, 10, time.Minute)
+
+	order, err := repo.FindByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, order.Price)
+
+	assert.NoError(t, repo.Update(ctx, &entity.Order{ID: "1", Price: 20, Tax: 1}))
+	order, err = repo.FindByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, order.Price)
+
+	assert.NoError(t, repo.Delete(ctx, "1"))
+	_, err = repo.FindByID(ctx, "1")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	assert.Equal(t, 3, inner.findCalls)
+}
+
+func TestCachedOrderRepository_KeepsTenantsApart(t *testing.T) {
+	db := newOrdersDB(t)
+	defer db.Close()
+	repo := NewCachedOrderRepository(NewOrderRepository(db), 10, time.Minute)
+	tenantA := entity.ContextWithTenant(context.Background(), "store-a")
+	tenantB := entity.ContextWithTenant(context.Background(), "store-b")
+
+	assert.NoError(t, repo.Save(tenantA, &entity.Order{ID: "1", Price: 10, Tax: 1, FinalPrice: 11}))
+
+	orders, err := repo.List(tenantA)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	_, err = repo.FindByID(tenantA, "1")
+	assert.NoError(t, err)
+
+	orders, err = repo.List(tenantB)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+	_, err = repo.FindByID(tenantB, "1")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+}
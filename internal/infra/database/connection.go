@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (c PoolConfig) Apply(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
+
+type RetryConfig struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	backoff := c.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if c.MaxBackoff > 0 && backoff >= c.MaxBackoff {
+			return c.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// OpenDB opens the connection pool, applies the pool limits and pings the
+// database, retrying with exponential backoff until it becomes reachable.
+func OpenDB(ctx context.Context, driver, dsn string, pool PoolConfig, retry RetryConfig) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	pool.Apply(db)
+
+	attempts := retry.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= attempts {
+			break
+		}
+		backoff := retry.backoff(attempt)
+		log.Printf("Failed to connect to the database (attempt %d/%d): %v. Retrying in %s...", attempt, attempts, err, backoff)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+	db.Close()
+	return nil, err
+}
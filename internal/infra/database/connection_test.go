@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryConfig_Backoff(t *testing.T) {
+	retry := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, retry.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, retry.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, retry.backoff(3))
+	assert.Equal(t, time.Second, retry.backoff(10))
+}
+
+func TestOpenDB_AppliesPoolConfig(t *testing.T) {
+	pool := PoolConfig{MaxOpenConns: 7, MaxIdleConns: 3, ConnMaxLifetime: time.Minute, ConnMaxIdleTime: time.Second}
+
+	db, err := OpenDB(context.Background(), "sqlite3", ":memory:", pool, RetryConfig{Attempts: 1})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 7, db.Stats().MaxOpenConnections)
+}
+
+func TestOpenDB_RetriesUntilAttemptsAreExhausted(t *testing.T) {
+	start := time.Now()
+	db, err := OpenDB(context.Background(), "sqlite3", "file:/nonexistent/dir/orders.db?mode=ro", PoolConfig{}, RetryConfig{
+		Attempts:       3,
+		InitialBackoff: 10 * time.Millisecond,
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, db)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestOpenDB_StopsWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	db, err := OpenDB(ctx, "sqlite3", "file:/nonexistent/dir/orders.db?mode=ro", PoolConfig{}, RetryConfig{
+		Attempts:       10,
+		InitialBackoff: time.Second,
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, db)
+}
+
+func TestOpenDB_UnknownDriver(t *testing.T) {
+	db, err := OpenDB(context.Background(), "unknown", "", PoolConfig{}, RetryConfig{})
+	assert.Error(t, err)
+	assert.Nil(t, db)
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+type primaryReadKey struct{}
+
+// WithPrimaryRead makes the reads done with ctx hit the primary, for callers
+// that must observe a write they have just made, which the replica may not
+// have applied yet. Cached repositories read through their cache as well.
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+func isPrimaryRead(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadKey{}).(bool)
+	return primary
+}
+
+type DBRouter struct {
+	primary        *sql.DB
+	replica        *sql.DB
+	replicaHealthy atomic.Bool
+}
+
+func NewDBRouter(primary *sql.DB, replica *sql.DB) *DBRouter {
+	router := &DBRouter{
+		primary: primary,
+		replica: replica,
+	}
+	router.replicaHealthy.Store(replica != nil)
+	return router
+}
+
+func (r *DBRouter) Primary() *sql.DB {
+	return r.primary
+}
+
+func (r *DBRouter) Reader() *sql.DB {
+	if r.replica != nil && r.replicaHealthy.Load() {
+		return r.replica
+	}
+	return r.primary
+}
+
+func (r *DBRouter) HasReplica() bool {
+	return r.replica != nil
+}
+
+func (r *DBRouter) ReplicaHealthy() bool {
+	return r.replicaHealthy.Load()
+}
+
+func (r *DBRouter) MarkReplicaUnhealthy() {
+	if r.replica != nil && r.replicaHealthy.Swap(false) {
+		log.Printf("Read replica marked as unhealthy, routing reads to primary")
+	}
+}
+
+func (r *DBRouter) CheckReplica(ctx context.Context) bool {
+	if r.replica == nil {
+		return false
+	}
+	if err := r.replica.PingContext(ctx); err != nil {
+		r.MarkReplicaUnhealthy()
+		return false
+	}
+	if !r.replicaHealthy.Swap(true) {
+		log.Printf("Read replica is healthy again, routing reads to replica")
+	}
+	return true
+}
+
+func (r *DBRouter) MonitorReplica(ctx context.Context, interval time.Duration) {
+	if r.replica == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			r.CheckReplica(checkCtx)
+			cancel()
+		}
+	}
+}
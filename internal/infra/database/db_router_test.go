@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+func newOrdersDB(t testing.TB) *sql.DB {
+	return newTestDB(t, createOrdersTable, createOrderHistoryTable)
+}
+
+func TestDBRouter_WithoutReplica(t *testing.T) {
+	primary := newOrdersDB(t)
+	defer primary.Close()
+
+	router := NewDBRouter(primary, nil)
+
+	assert.False(t, router.HasReplica())
+	assert.Same(t, primary, router.Reader())
+	assert.False(t, router.CheckReplica(context.Background()))
+}
+
+func TestDBRouter_ReaderFallsBackToPrimaryWhenReplicaUnhealthy(t *testing.T) {
+	primary := newOrdersDB(t)
+	defer primary.Close()
+	replica := newOrdersDB(t)
+
+	router := NewDBRouter(primary, replica)
+	assert.Same(t, replica, router.Reader())
+
+	router.MarkReplicaUnhealthy()
+	assert.Same(t, primary, router.Reader())
+
+	assert.True(t, router.CheckReplica(context.Background()))
+	assert.Same(t, replica, router.Reader())
+
+	replica.Close()
+	assert.False(t, router.CheckReplica(context.Background()))
+	assert.Same(t, primary, router.Reader())
+}
+
+func TestRoutedOrderRepository_ReadsFromReplica(t *testing.T) {
+	ctx := context.Background()
+	primary := newOrdersDB(t)
+	defer primary.Close()
+	replica := newOrdersDB(t)
+	defer replica.Close()
+
+	repo := NewRoutedOrderRepository(NewDBRouter(primary, replica))
+	order := &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}
+	assert.NoError(t, repo.Save(ctx, order))
+
+	orders, err := repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, orders, "replica has not received the write yet")
+
+	total, err := repo.GetTotal(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	_, err = repo.FindByID(ctx, order.ID)
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+}
+
+func TestRoutedOrderRepository_WithPrimaryReadsYourWrites(t *testing.T) {
+	ctx := context.Background()
+	primary := newOrdersDB(t)
+	defer primary.Close()
+	replica := newOrdersDB(t)
+	defer replica.Close()
+
+	repo := NewRoutedOrderRepository(NewDBRouter(primary, replica))
+	order := &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}
+	assert.NoError(t, repo.Save(ctx, order))
+
+	found, err := repo.FindByID(WithPrimaryRead(ctx), order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, found.ID)
+
+	orders, err := repo.List(WithPrimaryRead(ctx))
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	orders, err = repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestRoutedOrderRepository_FallsBackToPrimaryOnReplicaFailure(t *testing.T) {
+	ctx := context.Background()
+	primary := newOrdersDB(t)
+	defer primary.Close()
+	replica := newOrdersDB(t)
+
+	router := NewDBRouter(primary, replica)
+	repo := NewRoutedOrderRepository(router)
+	assert.NoError(t, repo.Save(ctx, &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}))
+
+	replica.Close()
+
+	orders, err := repo.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.False(t, router.ReplicaHealthy())
+	assert.Same(t, primary, router.Reader())
+}
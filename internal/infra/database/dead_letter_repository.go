@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+var (
+	ErrDeadLetterNotFound            = errors.New("dead letter not found")
+	ErrDeadLetterAlreadyRedispatched = errors.New("dead letter already redispatched")
+)
+
+type DeadLetterRecord struct {
+	ID             int64           `json:"id"`
+	TenantID       string          `json:"tenant_id"`
+	EventName      string          `json:"event_name"`
+	Handler        string          `json:"handler"`
+	Metadata       events.Metadata `json:"metadata"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	Error          string          `json:"error"`
+	FailedAt       time.Time       `json:"failed_at"`
+	RedispatchedAt *time.Time      `json:"redispatched_at,omitempty"`
+}
+
+// DeadLetterRepository is the events.DeadLetterSink backed by the
+// event_dead_letters table. Entries keep the tenant of the failed dispatch so
+// they are redelivered on its behalf.
+type DeadLetterRepository struct {
+	Db *sql.DB
+}
+
+func NewDeadLetterRepository(db *sql.DB) *DeadLetterRepository {
+	return &DeadLetterRepository{Db: db}
+}
+
+func (r *DeadLetterRepository) Store(ctx context.Context, deadLetter events.DeadLetter) error {
+	metadata, err := json.Marshal(deadLetter.Metadata)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(deadLetter.Payload)
+	if err != nil {
+		return err
+	}
+	_, err = r.Db.ExecContext(ctx,
+		"INSERT INTO event_dead_letters (tenant_id, event_name, handler, metadata, payload, attempts, error, failed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		entity.TenantFromContext(ctx), deadLetter.EventName, deadLetter.Handler, string(metadata), string(payload), deadLetter.Attempts, deadLetter.Err, deadLetter.FailedAt,
+	)
+	return err
+}
+
+// List returns the dead letters oldest first, only the ones not redispatched
+// yet unless all is set.
+func (r *DeadLetterRepository) List(ctx context.Context, all bool) ([]DeadLetterRecord, error) {
+	query := "SELECT id, tenant_id, event_name, handler, metadata, payload, attempts, error, failed_at, redispatched_at FROM event_dead_letters"
+	if !all {
+		query += " WHERE redispatched_at IS NULL"
+	}
+	rows, err := r.Db.QueryContext(ctx, query+" ORDER BY failed_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DeadLetterRecord
+	for rows.Next() {
+		record, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+func (r *DeadLetterRepository) FindByID(ctx context.Context, id int64) (*DeadLetterRecord, error) {
+	record, err := scanDeadLetter(r.Db.QueryRowContext(ctx,
+		"SELECT id, tenant_id, event_name, handler, metadata, payload, attempts, error, failed_at, redispatched_at FROM event_dead_letters WHERE id = ?",
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDeadLetterNotFound
+	}
+	return record, err
+}
+
+func (r *DeadLetterRepository) MarkRedispatched(ctx context.Context, id int64, redispatchedAt time.Time) error {
+	result, err := r.Db.ExecContext(ctx,
+		"UPDATE event_dead_letters SET redispatched_at = ? WHERE id = ? AND redispatched_at IS NULL",
+		redispatchedAt, id,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDeadLetterAlreadyRedispatched
+	}
+	return nil
+}
+
+func scanDeadLetter(row interface{ Scan(dest ...any) error }) (*DeadLetterRecord, error) {
+	record := &DeadLetterRecord{}
+	var metadata, payload []byte
+	var redispatchedAt sql.NullTime
+	err := row.Scan(&record.ID, &record.TenantID, &record.EventName, &record.Handler, &metadata, &payload, &record.Attempts, &record.Error, &record.FailedAt, &redispatchedAt)
+	if err != nil {
+		return nil, err
+	}
+	// Dead letters stored before the metadata column have none.
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &record.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	record.Payload = payload
+	if redispatchedAt.Valid {
+		record.RedispatchedAt = &redispatchedAt.Time
+	}
+	return record, nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+const createEventDeadLettersTable = "CREATE TABLE event_dead_letters (id INTEGER PRIMARY KEY AUTOINCREMENT, tenant_id TEXT NOT NULL DEFAULT 'default', event_name TEXT NOT NULL, handler TEXT NOT NULL, metadata TEXT NULL, payload TEXT NOT NULL, attempts INTEGER NOT NULL, error TEXT NOT NULL, failed_at TIMESTAMP NOT NULL, redispatched_at TIMESTAMP NULL)"
+
+func newDeadLetterRepository(t *testing.T) *DeadLetterRepository {
+	return NewDeadLetterRepository(newTestDB(t, createEventDeadLettersTable))
+}
+
+func TestDeadLetterRepository_StoreAndList(t *testing.T) {
+	repository := newDeadLetterRepository(t)
+	ctx := entity.ContextWithTenant(context.Background(), "store-a")
+	failedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	metadata := events.Metadata{ID: "event-1", OccurredAt: failedAt.Add(-time.Minute), CorrelationID: "request-1", CausationID: "request-1", Version: 1, Source: "ordersystem"}
+
+	err := repository.Store(ctx, events.DeadLetter{
+		EventName: "OrderCreated",
+		Handler:   "*handler.OrderCreatedHandler",
+		Metadata:  metadata,
+		Payload:   map[string]interface{}{"id": "1"},
+		Attempts:  5,
+		Err:       "connection refused",
+		FailedAt:  failedAt,
+	})
+	require.NoError(t, err)
+
+	records, err := repository.List(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "store-a", records[0].TenantID)
+	assert.Equal(t, "OrderCreated", records[0].EventName)
+	assert.Equal(t, "*handler.OrderCreatedHandler", records[0].Handler)
+	assert.Equal(t, metadata.ID, records[0].Metadata.ID)
+	assert.Equal(t, metadata.CorrelationID, records[0].Metadata.CorrelationID)
+	assert.True(t, metadata.OccurredAt.Equal(records[0].Metadata.OccurredAt))
+	assert.JSONEq(t, `{"id":"1"}`, string(records[0].Payload))
+	assert.Equal(t, 5, records[0].Attempts)
+	assert.Equal(t, "connection refused", records[0].Error)
+	assert.True(t, failedAt.Equal(records[0].FailedAt))
+	assert.Nil(t, records[0].RedispatchedAt)
+}
+
+func TestDeadLetterRepository_MarkRedispatched(t *testing.T) {
+	repository := newDeadLetterRepository(t)
+	ctx := context.Background()
+	require.NoError(t, repository.Store(ctx, events.DeadLetter{EventName: "OrderCreated", Handler: "h", Payload: "p", Attempts: 1, Err: "e", FailedAt: time.Now()}))
+
+	records, err := repository.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	id := records[0].ID
+
+	require.NoError(t, repository.MarkRedispatched(ctx, id, time.Now()))
+	assert.ErrorIs(t, repository.MarkRedispatched(ctx, id, time.Now()), ErrDeadLetterAlreadyRedispatched)
+
+	pending, err := repository.List(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	all, err := repository.List(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.NotNil(t, all[0].RedispatchedAt)
+
+	record, err := repository.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, record.ID)
+
+	_, err = repository.FindByID(ctx, id+1)
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+}
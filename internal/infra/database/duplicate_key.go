@@ -0,0 +1,23 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry is the MySQL error of an insert violating a primary
+// key or unique index (ER_DUP_ENTRY).
+const mysqlDuplicateEntry = 1062
+
+// isDuplicateKey reports whether err is a primary key or unique index
+// violation. MySQL errors are matched by number; sqlite ones, used by the
+// tests, by message, as the driver cannot be imported without cgo.
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDuplicateEntry
+	}
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
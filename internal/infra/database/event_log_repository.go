@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+type EventLogRecord struct {
+	Seq        int64           `json:"seq"`
+	TenantID   string          `json:"tenant_id"`
+	EventName  string          `json:"event_name"`
+	Metadata   events.Metadata `json:"metadata"`
+	Payload    json.RawMessage `json:"payload"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// EventLogFilter narrows the logged events by when they occurred, To being
+// exclusive, and by name. Zero values do not filter.
+type EventLogFilter struct {
+	From      time.Time
+	To        time.Time
+	EventName string
+}
+
+// EventLogRepository is the events.EventStore backed by the append-only
+// event_log table.
+type EventLogRepository struct {
+	Db *sql.DB
+}
+
+func NewEventLogRepository(db *sql.DB) *EventLogRepository {
+	return &EventLogRepository{Db: db}
+}
+
+func (r *EventLogRepository) Append(ctx context.Context, event events.EventInterface) error {
+	metadata := events.MetadataOf(event)
+	if metadata.ID == "" {
+		metadata.ID = events.NewID()
+		metadata.OccurredAt = event.GetDateTime()
+	}
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event.GetPayload())
+	if err != nil {
+		return err
+	}
+	_, err = r.Db.ExecContext(ctx,
+		"INSERT INTO event_log (event_id, tenant_id, event_name, metadata, payload, occurred_at, recorded_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		metadata.ID, entity.TenantFromContext(ctx), event.GetName(), string(encodedMetadata), string(payload), metadata.OccurredAt.UTC(), time.Now().UTC(),
+	)
+	return err
+}
+
+// Stream calls fn for every logged event matching the filter, in the order
+// they were appended, stopping at the first error.
+func (r *EventLogRepository) Stream(ctx context.Context, filter EventLogFilter, fn func(EventLogRecord) error) error {
+	where, args := filter.where()
+	rows, err := r.Db.QueryContext(ctx,
+		"SELECT seq, tenant_id, event_name, metadata, payload, recorded_at FROM event_log"+where+" ORDER BY seq",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record EventLogRecord
+		var metadata, payload []byte
+		if err := rows.Scan(&record.Seq, &record.TenantID, &record.EventName, &metadata, &payload, &record.RecordedAt); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(metadata, &record.Metadata); err != nil {
+			return err
+		}
+		record.Payload = payload
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *EventLogRepository) Count(ctx context.Context, filter EventLogFilter) (int, error) {
+	where, args := filter.where()
+	var count int
+	err := r.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM event_log"+where, args...).Scan(&count)
+	return count, err
+}
+
+func (f EventLogFilter) where() (string, []any) {
+	var conditions []string
+	var args []any
+	if !f.From.IsZero() {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "occurred_at < ?")
+		args = append(args, f.To.UTC())
+	}
+	if f.EventName != "" {
+		conditions = append(conditions, "event_name = ?")
+		args = append(args, f.EventName)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
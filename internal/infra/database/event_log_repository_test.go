@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+const createEventLogTable = "CREATE TABLE event_log (seq INTEGER PRIMARY KEY AUTOINCREMENT, event_id TEXT NOT NULL, tenant_id TEXT NOT NULL DEFAULT 'default', event_name TEXT NOT NULL, metadata TEXT NOT NULL, payload TEXT NOT NULL, occurred_at TIMESTAMP NOT NULL, recorded_at TIMESTAMP NOT NULL)"
+
+func newEventLogRepository(t *testing.T) *EventLogRepository {
+	return NewEventLogRepository(newTestDB(t, createEventLogTable))
+}
+
+func streamEventLog(t *testing.T, repository *EventLogRepository, filter EventLogFilter) []EventLogRecord {
+	var records []EventLogRecord
+	require.NoError(t, repository.Stream(context.Background(), filter, func(record EventLogRecord) error {
+		records = append(records, record)
+		return nil
+	}))
+	return records
+}
+
+func TestEventLogRepository_AppendAndStream(t *testing.T) {
+	repository := newEventLogRepository(t)
+	ctx := entity.ContextWithTenant(context.Background(), "store-a")
+	event := events.NewEventWithContext(ctx, "OrderCreated", map[string]string{"id": "1"}, events.WithSource("ordersystem"))
+
+	require.NoError(t, repository.Append(ctx, event))
+
+	records := streamEventLog(t, repository, EventLogFilter{})
+	require.Len(t, records, 1)
+	assert.Equal(t, "store-a", records[0].TenantID)
+	assert.Equal(t, "OrderCreated", records[0].EventName)
+	assert.Equal(t, event.GetMetadata().ID, records[0].Metadata.ID)
+	assert.Equal(t, "ordersystem", records[0].Metadata.Source)
+	assert.True(t, event.GetDateTime().Equal(records[0].Metadata.OccurredAt))
+	assert.JSONEq(t, `{"id":"1"}`, string(records[0].Payload))
+}
+
+func TestEventLogRepository_Filter(t *testing.T) {
+	repository := newEventLogRepository(t)
+	ctx := context.Background()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	logged := []struct {
+		name       string
+		occurredAt time.Time
+	}{
+		{"OrderCreated", start},
+		{"OrderCancelled", start.Add(time.Hour)},
+		{"OrderCreated", start.Add(2 * time.Hour)},
+		{"OrderCreated", start.Add(3 * time.Hour)},
+	}
+	for _, l := range logged {
+		event := events.RestoreEvent(l.name, "payload", events.Metadata{ID: events.NewID(), OccurredAt: l.occurredAt, Version: 1})
+		require.NoError(t, repository.Append(ctx, event))
+	}
+
+	filter := EventLogFilter{From: start.Add(time.Hour), To: start.Add(3 * time.Hour), EventName: "OrderCreated"}
+	records := streamEventLog(t, repository, filter)
+	require.Len(t, records, 1)
+	assert.True(t, start.Add(2*time.Hour).Equal(records[0].Metadata.OccurredAt))
+
+	count, err := repository.Count(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = repository.Count(ctx, EventLogFilter{EventName: "OrderCreated"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	records = streamEventLog(t, repository, EventLogFilter{})
+	require.Len(t, records, 4)
+	for i := 1; i < len(records); i++ {
+		assert.Less(t, records[i-1].Seq, records[i].Seq)
+	}
+}
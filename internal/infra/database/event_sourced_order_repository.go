@@ -0,0 +1,393 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+const (
+	OrderCreatedEventType = "OrderCreated"
+	OrderUpdatedEventType = "OrderUpdated"
+	OrderDeletedEventType = "OrderDeleted"
+	// An archived order is gone like a deleted one, but can be restored.
+	OrderArchivedEventType = "OrderArchived"
+	OrderRestoredEventType = "OrderRestored"
+
+	DefaultSnapshotEvery = 50
+)
+
+var ErrOrderVersionConflict = errors.New("order was modified concurrently")
+
+type orderState struct {
+	orderSnapshot
+	Deleted  bool `json:"deleted"`
+	Archived bool `json:"archived,omitempty"`
+}
+
+type orderStream struct {
+	state           orderState
+	version         int
+	snapshotVersion int
+}
+
+func (s *orderStream) exists() bool {
+	return s.version > 0 && !s.state.Deleted
+}
+
+func (s *orderStream) apply(eventType string, payload []byte) error {
+	switch eventType {
+	case OrderCreatedEventType, OrderUpdatedEventType, OrderRestoredEventType:
+		var snapshot orderSnapshot
+		if err := json.Unmarshal(payload, &snapshot); err != nil {
+			return err
+		}
+		if eventType == OrderUpdatedEventType {
+			snapshot.CreatedAt = s.state.CreatedAt
+		}
+		s.state = orderState{orderSnapshot: snapshot}
+	case OrderDeletedEventType:
+		s.state.Deleted = true
+		s.state.Archived = false
+	case OrderArchivedEventType:
+		s.state.Deleted = true
+		s.state.Archived = true
+	}
+	s.version++
+	return nil
+}
+
+func (s *orderStream) order() *entity.Order {
+	return &entity.Order{
+		ID:         s.state.ID,
+		Price:      s.state.Price,
+		Tax:        s.state.Tax,
+		FinalPrice: s.state.FinalPrice,
+		CreatedAt:  s.state.CreatedAt,
+	}
+}
+
+type EventSourcedOrderRepository struct {
+	Db            *sql.DB
+	SnapshotEvery int
+}
+
+func NewEventSourcedOrderRepository(db *sql.DB, snapshotEvery int) *EventSourcedOrderRepository {
+	if snapshotEvery <= 0 {
+		snapshotEvery = DefaultSnapshotEvery
+	}
+	return &EventSourcedOrderRepository{Db: db, SnapshotEvery: snapshotEvery}
+}
+
+func (r *EventSourcedOrderRepository) Save(ctx context.Context, order *entity.Order) error {
+	created := *order
+	if created.CreatedAt.IsZero() {
+		created.CreatedAt = time.Now()
+	}
+	return r.appendEvent(ctx, order.ID, OrderCreatedEventType, &created, requireNewOrder)
+}
+
+func (r *EventSourcedOrderRepository) SaveBatch(ctx context.Context, orders []entity.Order) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, order := range orders {
+		if order.CreatedAt.IsZero() {
+			order.CreatedAt = time.Now()
+		}
+		if err := r.appendEventTx(ctx, tx, order.ID, OrderCreatedEventType, &order, requireNewOrder); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *EventSourcedOrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	return r.appendEvent(ctx, order.ID, OrderUpdatedEventType, order, requireExistingOrder)
+}
+
+func (r *EventSourcedOrderRepository) Delete(ctx context.Context, id string) error {
+	return r.appendEvent(ctx, id, OrderDeletedEventType, nil, requireExistingOrder)
+}
+
+func (r *EventSourcedOrderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	stream, err := r.load(ctx, r.Db, id)
+	if err != nil {
+		return nil, err
+	}
+	if !stream.exists() {
+		return nil, entity.ErrOrderNotFound
+	}
+	return stream.order(), nil
+}
+
+func (r *EventSourcedOrderRepository) List(ctx context.Context) ([]entity.Order, error) {
+	var orders []entity.Order
+	err := r.Stream(ctx, func(order entity.Order) error {
+		orders = append(orders, order)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+// listEventsQuery reads the tenant's streams in order, each one starting at
+// its snapshot when there is one, followed by the events after it only: the
+// ones the snapshot covers are never read.
+const listEventsQuery = `SELECT order_id, version, state, NULL AS event_type, NULL AS payload
+FROM order_snapshots WHERE tenant_id = ?
+UNION ALL
+SELECT e.order_id, e.version, NULL, e.event_type, e.payload
+FROM order_events e
+LEFT JOIN order_snapshots s ON s.tenant_id = e.tenant_id AND s.order_id = e.order_id
+WHERE e.tenant_id = ? AND (s.version IS NULL OR e.version > s.version)
+ORDER BY order_id, version`
+
+// Stream replays the tenant's orders one by one. The events are read in
+// stream order, so each order is handed to fn as soon as its last event is
+// folded, without holding the other streams in memory.
+func (r *EventSourcedOrderRepository) Stream(ctx context.Context, fn func(order entity.Order) error) error {
+	tenant := entity.TenantFromContext(ctx)
+	rows, err := r.Db.QueryContext(ctx, listEventsQuery, tenant, tenant)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var currentID string
+	var current *orderStream
+	emit := func() error {
+		if current == nil || !current.exists() {
+			return nil
+		}
+		return fn(*current.order())
+	}
+	for rows.Next() {
+		var orderID string
+		var version int
+		var state, payload []byte
+		var eventType sql.NullString
+		if err := rows.Scan(&orderID, &version, &state, &eventType, &payload); err != nil {
+			return err
+		}
+		if current == nil || orderID != currentID {
+			if err := emit(); err != nil {
+				return err
+			}
+			currentID, current = orderID, &orderStream{}
+		}
+		if !eventType.Valid {
+			if err := json.Unmarshal(state, &current.state); err != nil {
+				return err
+			}
+			current.version, current.snapshotVersion = version, version
+			continue
+		}
+		if err := current.apply(eventType.String, payload); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return emit()
+}
+
+func (r *EventSourcedOrderRepository) ListByOrderID(ctx context.Context, orderID string) ([]entity.OrderHistory, error) {
+	rows, err := r.Db.QueryContext(ctx,
+		"SELECT id, event_type, payload, actor, occurred_at FROM order_events WHERE tenant_id = ? AND order_id = ? ORDER BY version",
+		entity.TenantFromContext(ctx), orderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stream := &orderStream{}
+	var history []entity.OrderHistory
+	for rows.Next() {
+		var entry entity.OrderHistory
+		var eventType string
+		var payload []byte
+		if err := rows.Scan(&entry.ID, &eventType, &payload, &entry.Actor, &entry.OccurredAt); err != nil {
+			return nil, err
+		}
+		if stream.exists() {
+			entry.Before = stream.order()
+		}
+		if err := stream.apply(eventType, payload); err != nil {
+			return nil, err
+		}
+		if stream.exists() {
+			entry.After = stream.order()
+		}
+		entry.OrderID = orderID
+		entry.Operation = historyOperation(eventType)
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+func (r *EventSourcedOrderRepository) appendEvent(ctx context.Context, orderID, eventType string, order *entity.Order, check func(stream *orderStream) error) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.appendEventTx(ctx, tx, orderID, eventType, order, check); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *EventSourcedOrderRepository) appendEventTx(ctx context.Context, tx *sql.Tx, orderID, eventType string, order *entity.Order, check func(stream *orderStream) error) error {
+	stream, err := r.load(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if err := check(stream); err != nil {
+		return err
+	}
+	return r.insertEvent(ctx, tx, orderID, stream, eventType, order)
+}
+
+// insertEvent appends the event as the next version of the stream. Another
+// writer that appended that version first, invisible to this transaction's
+// snapshot, makes the insert hit the unique stream index, reported as a
+// version conflict.
+func (r *EventSourcedOrderRepository) insertEvent(ctx context.Context, tx *sql.Tx, orderID string, stream *orderStream, eventType string, order *entity.Order) error {
+	payload, err := marshalOrderSnapshot(order)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO order_events (tenant_id, order_id, version, event_type, payload, actor, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		entity.TenantFromContext(ctx), orderID, stream.version+1, eventType, payload, entity.ActorFromContext(ctx), time.Now().UTC(),
+	)
+	if isDuplicateKey(err) {
+		return ErrOrderVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+	if err := stream.apply(eventType, []byte(payload.String)); err != nil {
+		return err
+	}
+
+	if stream.version-stream.snapshotVersion >= r.SnapshotEvery {
+		return saveSnapshot(ctx, tx, orderID, stream)
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r *EventSourcedOrderRepository) load(ctx context.Context, db queryer, orderID string) (*orderStream, error) {
+	tenant := entity.TenantFromContext(ctx)
+	stream := &orderStream{}
+
+	var state []byte
+	err := db.QueryRowContext(ctx, "SELECT version, state FROM order_snapshots WHERE tenant_id = ? AND order_id = ?", tenant, orderID).Scan(&stream.version, &state)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(state, &stream.state); err != nil {
+			return nil, err
+		}
+		stream.snapshotVersion = stream.version
+	}
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT event_type, payload FROM order_events WHERE tenant_id = ? AND order_id = ? AND version > ? ORDER BY version",
+		tenant, orderID, stream.version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventType string
+		var payload []byte
+		if err := rows.Scan(&eventType, &payload); err != nil {
+			return nil, err
+		}
+		if err := stream.apply(eventType, payload); err != nil {
+			return nil, err
+		}
+	}
+	return stream, rows.Err()
+}
+
+func saveSnapshot(ctx context.Context, tx *sql.Tx, orderID string, stream *orderStream) error {
+	state, err := json.Marshal(stream.state)
+	if err != nil {
+		return err
+	}
+	tenant := entity.TenantFromContext(ctx)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_snapshots WHERE tenant_id = ? AND order_id = ?", tenant, orderID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO order_snapshots (tenant_id, order_id, version, state, created_at) VALUES (?, ?, ?, ?, ?)",
+		tenant, orderID, stream.version, string(state), time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	stream.snapshotVersion = stream.version
+	return nil
+}
+
+func requireNewOrder(stream *orderStream) error {
+	if stream.version > 0 {
+		return entity.ErrOrderAlreadyExists
+	}
+	return nil
+}
+
+func requireExistingOrder(stream *orderStream) error {
+	if !stream.exists() {
+		return entity.ErrOrderNotFound
+	}
+	return nil
+}
+
+func requireArchivedOrder(stream *orderStream) error {
+	if stream.version == 0 || !stream.state.Archived {
+		return entity.ErrOrderNotFound
+	}
+	return nil
+}
+
+func historyOperation(eventType string) string {
+	switch eventType {
+	case OrderCreatedEventType:
+		return entity.OrderOperationCreate
+	case OrderDeletedEventType:
+		return entity.OrderOperationDelete
+	case OrderArchivedEventType:
+		return entity.OrderOperationArchive
+	case OrderRestoredEventType:
+		return entity.OrderOperationRestore
+	default:
+		return entity.OrderOperationUpdate
+	}
+}
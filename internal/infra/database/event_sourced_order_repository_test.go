@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+const (
+	createOrderEventsTable    = "CREATE TABLE order_events (id INTEGER PRIMARY KEY AUTOINCREMENT, tenant_id TEXT NOT NULL DEFAULT 'default', order_id TEXT NOT NULL, version INTEGER NOT NULL, event_type TEXT NOT NULL, payload TEXT NULL, actor TEXT NOT NULL, occurred_at TIMESTAMP NOT NULL, UNIQUE (tenant_id, order_id, version))"
+	createOrderSnapshotsTable = "CREATE TABLE order_snapshots (tenant_id TEXT NOT NULL DEFAULT 'default', order_id TEXT NOT NULL, version INTEGER NOT NULL, state TEXT NOT NULL, created_at TIMESTAMP NOT NULL, PRIMARY KEY (tenant_id, order_id))"
+)
+
+type EventSourcedOrderRepositoryTestSuite struct {
+	suite.Suite
+	Db *sql.DB
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) SetupTest() {
+	suite.Db = newTestDB(suite.T(), createOrderEventsTable, createOrderSnapshotsTable)
+}
+
+func TestEventSourcedOrderRepositorySuite(t *testing.T) {
+	suite.Run(t, new(EventSourcedOrderRepositoryTestSuite))
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) countRows(table string) int {
+	var total int
+	suite.NoError(suite.Db.QueryRow("SELECT count(*) FROM " + table).Scan(&total))
+	return total
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenAnOrder_WhenSave_ThenShouldAppendCreatedEvent() {
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(suite.Db, 0)
+
+	order := &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}
+	suite.NoError(repo.Save(ctx, order))
+	suite.Equal(1, suite.countRows("order_events"))
+
+	found, err := repo.FindByID(ctx, order.ID)
+	suite.NoError(err)
+	suite.Equal(order.ID, found.ID)
+	suite.Equal(12.0, found.FinalPrice)
+	suite.False(found.CreatedAt.IsZero())
+
+	suite.ErrorIs(repo.Save(ctx, order), entity.ErrOrderAlreadyExists)
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenOrderEvents_WhenFindByID_ThenShouldRebuildCurrentState() {
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(suite.Db, 0)
+
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12, CreatedAt: createdAt}))
+	suite.NoError(repo.Update(ctx, &entity.Order{ID: "123", Price: 20, Tax: 2, FinalPrice: 22}))
+	suite.NoError(repo.Update(ctx, &entity.Order{ID: "123", Price: 20, Tax: 4, FinalPrice: 24}))
+
+	found, err := repo.FindByID(ctx, "123")
+	suite.NoError(err)
+	suite.Equal(20.0, found.Price)
+	suite.Equal(4.0, found.Tax)
+	suite.Equal(24.0, found.FinalPrice)
+	suite.True(createdAt.Equal(found.CreatedAt))
+
+	suite.NoError(repo.Delete(ctx, "123"))
+	_, err = repo.FindByID(ctx, "123")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	suite.ErrorIs(repo.Update(ctx, &entity.Order{ID: "123", Price: 1, Tax: 1}), entity.ErrOrderNotFound)
+	suite.ErrorIs(repo.Delete(ctx, "123"), entity.ErrOrderNotFound)
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenALongStream_WhenAppending_ThenShouldSnapshot() {
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(suite.Db, 3)
+
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "123", Price: 10, Tax: 1, FinalPrice: 11}))
+	suite.NoError(repo.Update(ctx, &entity.Order{ID: "123", Price: 11, Tax: 1, FinalPrice: 12}))
+	suite.Equal(0, suite.countRows("order_snapshots"))
+
+	suite.NoError(repo.Update(ctx, &entity.Order{ID: "123", Price: 12, Tax: 1, FinalPrice: 13}))
+	suite.Equal(1, suite.countRows("order_snapshots"))
+
+	suite.NoError(repo.Update(ctx, &entity.Order{ID: "123", Price: 13, Tax: 1, FinalPrice: 14}))
+
+	var version int
+	suite.NoError(suite.Db.QueryRow("SELECT version FROM order_snapshots WHERE order_id = ?", "123").Scan(&version))
+	suite.Equal(3, version)
+
+	_, err := suite.Db.Exec("DELETE FROM order_events WHERE version <= 3")
+	suite.NoError(err)
+
+	found, err := repo.FindByID(ctx, "123")
+	suite.NoError(err)
+	suite.Equal(14.0, found.FinalPrice)
+
+	orders, err := repo.List(ctx)
+	suite.NoError(err)
+	suite.Len(orders, 1)
+	suite.Equal(14.0, orders[0].FinalPrice)
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenASnapshotCoveringTheStream_WhenList_ThenShouldStartFromIt() {
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(suite.Db, 2)
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "123", Price: 10, Tax: 1, FinalPrice: 11, CreatedAt: createdAt}))
+	suite.NoError(repo.Update(ctx, &entity.Order{ID: "123", Price: 11, Tax: 1, FinalPrice: 12}))
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "456", Price: 20, Tax: 2, FinalPrice: 22, CreatedAt: createdAt.Add(time.Hour)}))
+	_, err := suite.Db.Exec("DELETE FROM order_events WHERE order_id = ?", "123")
+	suite.NoError(err)
+
+	orders, err := repo.List(ctx)
+	suite.NoError(err)
+	suite.Len(orders, 2)
+	suite.Equal("456", orders[0].ID)
+	suite.Equal("123", orders[1].ID)
+	suite.Equal(12.0, orders[1].FinalPrice)
+	suite.True(createdAt.Equal(orders[1].CreatedAt))
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenOrders_WhenList_ThenShouldReturnLiveOrdersByCreationDate() {
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(suite.Db, 0)
+
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "1", Price: 10, Tax: 1, FinalPrice: 11, CreatedAt: base}))
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "2", Price: 20, Tax: 2, FinalPrice: 22, CreatedAt: base.Add(time.Hour)}))
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "3", Price: 30, Tax: 3, FinalPrice: 33, CreatedAt: base.Add(2 * time.Hour)}))
+	suite.NoError(repo.Delete(ctx, "2"))
+
+	orders, err := repo.List(ctx)
+	suite.NoError(err)
+	suite.Len(orders, 2)
+	suite.Equal("3", orders[0].ID)
+	suite.Equal("1", orders[1].ID)
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenOrderEvents_WhenListHistory_ThenShouldDeriveItFromTheStream() {
+	repo := NewEventSourcedOrderRepository(suite.Db, 0)
+
+	suite.NoError(repo.Save(entity.ContextWithActor(context.Background(), "alice"), &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}))
+	suite.NoError(repo.Update(entity.ContextWithActor(context.Background(), "bob"), &entity.Order{ID: "123", Price: 10, Tax: 3, FinalPrice: 13}))
+	suite.NoError(repo.Delete(context.Background(), "123"))
+
+	history, err := repo.ListByOrderID(context.Background(), "123")
+	suite.NoError(err)
+	suite.Len(history, 3)
+
+	suite.Equal(entity.OrderOperationCreate, history[0].Operation)
+	suite.Equal("alice", history[0].Actor)
+	suite.Nil(history[0].Before)
+	suite.Equal(12.0, history[0].After.FinalPrice)
+
+	suite.Equal(entity.OrderOperationUpdate, history[1].Operation)
+	suite.Equal("bob", history[1].Actor)
+	suite.Equal(2.0, history[1].Before.Tax)
+	suite.Equal(3.0, history[1].After.Tax)
+
+	suite.Equal(entity.OrderOperationDelete, history[2].Operation)
+	suite.Equal(13.0, history[2].Before.FinalPrice)
+	suite.Nil(history[2].After)
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenOrdersFromAnotherTenant_WhenAccessing_ThenShouldNotSeeOrChangeThem() {
+	repo := NewEventSourcedOrderRepository(suite.Db, 2)
+	tenantA := entity.ContextWithTenant(context.Background(), "store-a")
+	tenantB := entity.ContextWithTenant(context.Background(), "store-b")
+
+	suite.NoError(repo.Save(tenantA, &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}))
+	suite.NoError(repo.Update(tenantA, &entity.Order{ID: "123", Price: 10, Tax: 3, FinalPrice: 13}))
+
+	_, err := repo.FindByID(tenantB, "123")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	orders, err := repo.List(tenantB)
+	suite.NoError(err)
+	suite.Empty(orders)
+	history, err := repo.ListByOrderID(tenantB, "123")
+	suite.NoError(err)
+	suite.Empty(history)
+
+	suite.ErrorIs(repo.Update(tenantB, &entity.Order{ID: "123", Price: 99, Tax: 1, FinalPrice: 100}), entity.ErrOrderNotFound)
+	suite.ErrorIs(repo.Delete(tenantB, "123"), entity.ErrOrderNotFound)
+
+	suite.NoError(repo.Save(tenantB, &entity.Order{ID: "123", Price: 50, Tax: 5, FinalPrice: 55}))
+	found, err := repo.FindByID(tenantB, "123")
+	suite.NoError(err)
+	suite.Equal(55.0, found.FinalPrice)
+
+	found, err = repo.FindByID(tenantA, "123")
+	suite.NoError(err)
+	suite.Equal(13.0, found.FinalPrice)
+	orders, err = repo.List(tenantA)
+	suite.NoError(err)
+	suite.Len(orders, 1)
+	suite.Equal(13.0, orders[0].FinalPrice)
+}
+
+func (suite *EventSourcedOrderRepositoryTestSuite) TestGivenABatch_WhenSaveBatch_ThenShouldAppendAllOrNothing() {
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(suite.Db, 0)
+
+	suite.NoError(repo.SaveBatch(ctx, []entity.Order{
+		{ID: "1", Price: 10, Tax: 1, FinalPrice: 11},
+		{ID: "2", Price: 20, Tax: 2, FinalPrice: 22},
+	}))
+	suite.ErrorIs(repo.SaveBatch(ctx, []entity.Order{
+		{ID: "3", Price: 30, Tax: 3, FinalPrice: 33},
+		{ID: "1", Price: 10, Tax: 1, FinalPrice: 11},
+	}), entity.ErrOrderAlreadyExists)
+	suite.Equal(2, suite.countRows("order_events"))
+
+	var ids []string
+	suite.NoError(repo.Stream(ctx, func(order entity.Order) error {
+		ids = append(ids, order.ID)
+		return nil
+	}))
+	suite.ElementsMatch([]string{"1", "2"}, ids)
+}
+
+// TestEventSourcedOrderRepository_ConcurrentAppend runs two transactions on
+// the same stream: A reads version 1, B appends version 2 and commits, then A
+// appends what it believes is version 2. A must get a version conflict, not
+// the driver's duplicate-key error.
+func TestEventSourcedOrderRepository_ConcurrentAppend(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "orders.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	for _, table := range []string{createOrderEventsTable, createOrderSnapshotsTable} {
+		_, err := db.Exec(table)
+		require.NoError(t, err)
+	}
+	ctx := context.Background()
+	repo := NewEventSourcedOrderRepository(db, 0)
+	require.NoError(t, repo.Save(ctx, &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}))
+
+	txA, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer txA.Rollback()
+	stale, err := repo.load(ctx, db, "123")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Update(ctx, &entity.Order{ID: "123", Price: 20, Tax: 2, FinalPrice: 22}))
+
+	err = repo.insertEvent(ctx, txA, "123", stale, OrderUpdatedEventType, &entity.Order{ID: "123", Price: 30, Tax: 3, FinalPrice: 33})
+	assert.ErrorIs(t, err, ErrOrderVersionConflict)
+}
+
+func TestIsDuplicateKey(t *testing.T) {
+	assert.True(t, isDuplicateKey(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'default-123-2' for key 'uq_order_events_stream'"}))
+	assert.True(t, isDuplicateKey(fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1062})))
+	assert.False(t, isDuplicateKey(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}))
+	assert.False(t, isDuplicateKey(errors.New("connection refused")))
+	assert.False(t, isDuplicateKey(nil))
+}
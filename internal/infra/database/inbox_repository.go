@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// InboxRepository is the events.InboxStore backed by the event_inbox table,
+// one row per event and handler.
+type InboxRepository struct {
+	Db *sql.DB
+}
+
+func NewInboxRepository(db *sql.DB) *InboxRepository {
+	return &InboxRepository{Db: db}
+}
+
+func (r *InboxRepository) Claim(ctx context.Context, eventID, handler string, now, leaseUntil time.Time) (bool, error) {
+	_, insertErr := r.Db.ExecContext(ctx,
+		"INSERT INTO event_inbox (event_id, handler, tenant_id, claimed_at, lease_until) VALUES (?, ?, ?, ?, ?)",
+		eventID, handler, entity.TenantFromContext(ctx), now.UTC(), leaseUntil.UTC(),
+	)
+	if insertErr == nil {
+		return true, nil
+	}
+	if !isDuplicateKey(insertErr) {
+		return false, insertErr
+	}
+
+	// The event was claimed before: it is either ours to take over, once its
+	// lease has expired, or a duplicate.
+	result, err := r.Db.ExecContext(ctx,
+		"UPDATE event_inbox SET claimed_at = ?, lease_until = ? WHERE event_id = ? AND handler = ? AND processed_at IS NULL AND lease_until < ?",
+		now.UTC(), leaseUntil.UTC(), eventID, handler, now.UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected > 0 {
+		return true, nil
+	}
+
+	var processedAt sql.NullTime
+	err = r.Db.QueryRowContext(ctx, "SELECT processed_at FROM event_inbox WHERE event_id = ? AND handler = ?", eventID, handler).Scan(&processedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, insertErr
+	}
+	if err != nil {
+		return false, err
+	}
+	if !processedAt.Valid {
+		return false, events.ErrInboxInProgress
+	}
+	return false, nil
+}
+
+func (r *InboxRepository) Complete(ctx context.Context, eventID, handler string, processedAt time.Time) error {
+	_, err := r.Db.ExecContext(ctx,
+		"UPDATE event_inbox SET processed_at = ? WHERE event_id = ? AND handler = ?",
+		processedAt.UTC(), eventID, handler,
+	)
+	return err
+}
+
+func (r *InboxRepository) Release(ctx context.Context, eventID, handler string) error {
+	_, err := r.Db.ExecContext(ctx,
+		"DELETE FROM event_inbox WHERE event_id = ? AND handler = ? AND processed_at IS NULL",
+		eventID, handler,
+	)
+	return err
+}
+
+// Purge deletes the events processed before the cutoff, once a redelivery
+// of them is no longer expected.
+func (r *InboxRepository) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.Db.ExecContext(ctx, "DELETE FROM event_inbox WHERE processed_at < ?", cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+const createEventInboxTable = "CREATE TABLE event_inbox (event_id TEXT NOT NULL, handler TEXT NOT NULL, tenant_id TEXT NOT NULL DEFAULT 'default', claimed_at TIMESTAMP NOT NULL, lease_until TIMESTAMP NOT NULL, processed_at TIMESTAMP NULL, PRIMARY KEY (event_id, handler))"
+
+func newInboxRepository(t *testing.T) *InboxRepository {
+	return NewInboxRepository(newTestDB(t, createEventInboxTable))
+}
+
+func TestInboxRepository_Claim(t *testing.T) {
+	repository := newInboxRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+
+	claimed, err := repository.Claim(ctx, "event-1", "handler-a", now, lease)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repository.Claim(ctx, "event-1", "handler-a", now, lease)
+	assert.ErrorIs(t, err, events.ErrInboxInProgress, "claimed while leased")
+	assert.False(t, claimed)
+
+	claimed, err = repository.Claim(ctx, "event-1", "handler-b", now, lease)
+	require.NoError(t, err)
+	assert.True(t, claimed, "other handlers process the event too")
+
+	later := now.Add(2 * time.Minute)
+	claimed, err = repository.Claim(ctx, "event-1", "handler-a", later, later.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, claimed, "expired lease is taken over")
+
+	require.NoError(t, repository.Complete(ctx, "event-1", "handler-a", later))
+	muchLater := later.Add(time.Hour)
+	claimed, err = repository.Claim(ctx, "event-1", "handler-a", muchLater, muchLater.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, claimed, "processed events stay processed")
+}
+
+func TestInboxRepository_ClaimReturnsInsertFailures(t *testing.T) {
+	repository := newInboxRepository(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, err := repository.Db.Exec("CREATE TRIGGER inbox_read_only BEFORE INSERT ON event_inbox BEGIN SELECT RAISE(ABORT, 'inbox is read-only'); END")
+	require.NoError(t, err)
+
+	claimed, err := repository.Claim(context.Background(), "event-1", "handler-a", now, now.Add(time.Minute))
+	assert.ErrorContains(t, err, "inbox is read-only")
+	assert.NotErrorIs(t, err, events.ErrInboxInProgress)
+	assert.False(t, claimed)
+}
+
+func TestInboxRepository_ReleaseAndPurge(t *testing.T) {
+	repository := newInboxRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+
+	_, err := repository.Claim(ctx, "event-1", "handler-a", now, lease)
+	require.NoError(t, err)
+	require.NoError(t, repository.Release(ctx, "event-1", "handler-a"))
+	claimed, err := repository.Claim(ctx, "event-1", "handler-a", now, lease)
+	require.NoError(t, err)
+	assert.True(t, claimed, "released events can be claimed again")
+
+	require.NoError(t, repository.Complete(ctx, "event-1", "handler-a", now))
+	_, err = repository.Claim(ctx, "event-2", "handler-a", now, lease)
+	require.NoError(t, err)
+
+	purged, err := repository.Purge(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	_, err = repository.Claim(ctx, "event-2", "handler-a", now, lease)
+	assert.ErrorIs(t, err, events.ErrInboxInProgress, "pending claims are not purged")
+}
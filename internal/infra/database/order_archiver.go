@@ -0,0 +1,447 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+// DefaultArchiveBatchSize is how many orders an archive moves per transaction
+// when the archiver has no batch size.
+const DefaultArchiveBatchSize = 500
+
+var (
+	ErrNoOrdersToArchive      = errors.New("no orders to archive")
+	ErrArchiveNotFound        = errors.New("archive not found")
+	ErrArchiveAlreadyRestored = errors.New("archive already restored")
+)
+
+type OrderArchive struct {
+	ID         int64
+	FileName   string
+	Cutoff     time.Time
+	OrderCount int
+	ArchivedAt time.Time
+	RestoredAt *time.Time
+}
+
+type archivedOrder struct {
+	TenantID   string    `json:"tenant_id"`
+	ID         string    `json:"id"`
+	Price      float64   `json:"price"`
+	Tax        float64   `json:"tax"`
+	FinalPrice float64   `json:"final_price"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (o archivedOrder) order() *entity.Order {
+	return &entity.Order{ID: o.ID, Price: o.Price, Tax: o.Tax, FinalPrice: o.FinalPrice, CreatedAt: o.CreatedAt}
+}
+
+// archiveStorage takes the archived orders out of the order storage and puts
+// them back on restore, recording both in the order history. createdBefore
+// returns up to limit live orders; the ones archived leave it, so calling it
+// again after archiving a batch returns the next one.
+type archiveStorage interface {
+	createdBefore(ctx context.Context, tx *sql.Tx, cutoff time.Time, limit int) ([]archivedOrder, error)
+	archive(ctx context.Context, tx *sql.Tx, order archivedOrder) error
+	restore(ctx context.Context, tx *sql.Tx, order archivedOrder) error
+}
+
+// OrderArchiver moves old orders out of the live storage into gzip-compressed
+// JSONL files, keeping a record of every archive in order_archives. It works
+// across all tenants, each line carrying the tenant the order belongs to.
+type OrderArchiver struct {
+	Db  *sql.DB
+	Dir string
+	// BatchSize is how many orders are archived per transaction.
+	BatchSize int
+	// Invalidate, when set, is called with every order archived or restored
+	// once the change is committed, in a context carrying its tenant, so a
+	// cache of the orders drops it.
+	Invalidate func(ctx context.Context, orderID string)
+	storage    archiveStorage
+	now        func() time.Time
+}
+
+func NewOrderArchiver(db *sql.DB, dir string) *OrderArchiver {
+	return &OrderArchiver{Db: db, Dir: dir, BatchSize: DefaultArchiveBatchSize, storage: tableArchiveStorage{}, now: time.Now}
+}
+
+// NewEventSourcedOrderArchiver archives the orders of the event-sourced
+// storage. Their events stay in order_events, as a stream is append-only:
+// archiving appends an OrderArchived event, which hides the order like a
+// delete, and restoring an OrderRestored one.
+func NewEventSourcedOrderArchiver(repository *EventSourcedOrderRepository, dir string) *OrderArchiver {
+	return &OrderArchiver{Db: repository.Db, Dir: dir, BatchSize: DefaultArchiveBatchSize, storage: eventSourcedArchiveStorage{repository}, now: time.Now}
+}
+
+// Archive moves the orders created more than olderThan ago into a new file,
+// BatchSize orders at a time. Each batch is written to the file as a gzip
+// member of its own and synced before its transaction commits, and cut from
+// the file again when the transaction fails, so the file holds exactly the
+// batches committed. A failure after the first batch leaves the archive with
+// the orders committed until then.
+func (a *OrderArchiver) Archive(ctx context.Context, olderThan time.Duration) (*OrderArchive, error) {
+	archivedAt := a.now().UTC()
+	archive := &OrderArchive{
+		Cutoff:     archivedAt.Add(-olderThan),
+		ArchivedAt: archivedAt,
+		FileName:   filepath.Join(a.Dir, fmt.Sprintf("orders-%s.jsonl.gz", archivedAt.Format("20060102T150405.000000000"))),
+	}
+
+	batchSize := a.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultArchiveBatchSize
+	}
+	file := &archiveFile{name: archive.FileName}
+	var err error
+	for archived := batchSize; err == nil && archived == batchSize; {
+		archived, err = a.archiveBatch(ctx, archive, file, batchSize)
+	}
+	err = errors.Join(err, file.close())
+	if archive.OrderCount == 0 {
+		file.remove()
+		if err == nil {
+			err = ErrNoOrdersToArchive
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// archiveBatch archives the next batch of orders, recording the archive with
+// the first one, and returns how many it archived.
+func (a *OrderArchiver) archiveBatch(ctx context.Context, archive *OrderArchive, file *archiveFile, batchSize int) (int, error) {
+	tx, err := a.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	orders, err := a.storage.createdBefore(ctx, tx, archive.Cutoff, batchSize)
+	if err != nil || len(orders) == 0 {
+		return 0, err
+	}
+
+	offset, err := file.append(a.Dir, orders)
+	if err == nil {
+		err = a.recordArchive(ctx, tx, archive, orders)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return 0, errors.Join(err, file.truncate(offset))
+	}
+	archive.OrderCount += len(orders)
+	a.invalidate(ctx, orders)
+	return len(orders), nil
+}
+
+func (a *OrderArchiver) Restore(ctx context.Context, archiveID int64) (*OrderArchive, error) {
+	tx, err := a.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	archive, err := findArchive(ctx, tx, archiveID)
+	if err != nil {
+		return nil, err
+	}
+	if archive.RestoredAt != nil {
+		return nil, ErrArchiveAlreadyRestored
+	}
+
+	orders, err := readArchiveFile(archive.FileName)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		if err := a.storage.restore(entity.ContextWithTenant(ctx, order.TenantID), tx, order); err != nil {
+			return nil, fmt.Errorf("restoring order %s/%s: %w", order.TenantID, order.ID, err)
+		}
+	}
+
+	restoredAt := a.now().UTC()
+	if _, err := tx.ExecContext(ctx, "UPDATE order_archives SET restored_at = ? WHERE id = ?", restoredAt, archive.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	a.invalidate(ctx, orders)
+	archive.RestoredAt = &restoredAt
+	return archive, nil
+}
+
+func (a *OrderArchiver) invalidate(ctx context.Context, orders []archivedOrder) {
+	if a.Invalidate == nil {
+		return
+	}
+	for _, order := range orders {
+		a.Invalidate(entity.ContextWithTenant(ctx, order.TenantID), order.ID)
+	}
+}
+
+func (a *OrderArchiver) recordArchive(ctx context.Context, tx *sql.Tx, archive *OrderArchive, orders []archivedOrder) error {
+	if archive.ID == 0 {
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO order_archives (file_name, cutoff, order_count, archived_at) VALUES (?, ?, ?, ?)",
+			archive.FileName, archive.Cutoff, len(orders), archive.ArchivedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if archive.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	} else {
+		_, err := tx.ExecContext(ctx, "UPDATE order_archives SET order_count = order_count + ? WHERE id = ?", len(orders), archive.ID)
+		if err != nil {
+			return err
+		}
+	}
+	for _, order := range orders {
+		if err := a.storage.archive(entity.ContextWithTenant(ctx, order.TenantID), tx, order); err != nil {
+			return fmt.Errorf("archiving order %s/%s: %w", order.TenantID, order.ID, err)
+		}
+	}
+	return nil
+}
+
+// tableArchiveStorage archives the orders of the orders table.
+type tableArchiveStorage struct{}
+
+func (tableArchiveStorage) createdBefore(ctx context.Context, tx *sql.Tx, cutoff time.Time, limit int) ([]archivedOrder, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT tenant_id, id, price, tax, final_price, created_at FROM orders WHERE created_at < ? ORDER BY created_at, tenant_id, id LIMIT ?",
+		cutoff, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []archivedOrder
+	for rows.Next() {
+		var order archivedOrder
+		if err := rows.Scan(&order.TenantID, &order.ID, &order.Price, &order.Tax, &order.FinalPrice, &order.CreatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func (tableArchiveStorage) archive(ctx context.Context, tx *sql.Tx, order archivedOrder) error {
+	if _, err := tx.ExecContext(ctx, deleteOrderQuery, order.TenantID, order.ID); err != nil {
+		return err
+	}
+	return insertHistory(ctx, tx, order.ID, entity.OrderOperationArchive, order.order(), nil)
+}
+
+func (tableArchiveStorage) restore(ctx context.Context, tx *sql.Tx, order archivedOrder) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO orders (tenant_id, id, price, tax, final_price, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		order.TenantID, order.ID, order.Price, order.Tax, order.FinalPrice, order.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return insertHistory(ctx, tx, order.ID, entity.OrderOperationRestore, nil, order.order())
+}
+
+func insertHistory(ctx context.Context, tx *sql.Tx, orderID, operation string, before, after *entity.Order) error {
+	args, err := historyArgs(ctx, orderID, operation, before, after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertOrderHistoryQuery, args...)
+	return err
+}
+
+// eventSourcedArchiveStorage archives the order streams created before the
+// cutoff that still hold an order, which are the ones whose last event does
+// not delete or archive it.
+type eventSourcedArchiveStorage struct {
+	repository *EventSourcedOrderRepository
+}
+
+func (s eventSourcedArchiveStorage) createdBefore(ctx context.Context, tx *sql.Tx, cutoff time.Time, limit int) ([]archivedOrder, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT e.tenant_id, e.order_id FROM order_events e
+		WHERE e.event_type = ? AND e.occurred_at < ?
+		AND (SELECT l.event_type FROM order_events l WHERE l.tenant_id = e.tenant_id AND l.order_id = e.order_id ORDER BY l.version DESC LIMIT 1) NOT IN (?, ?)
+		ORDER BY e.occurred_at, e.id LIMIT ?`,
+		OrderCreatedEventType, cutoff, OrderDeletedEventType, OrderArchivedEventType, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var created []archivedOrder
+	for rows.Next() {
+		var order archivedOrder
+		if err := rows.Scan(&order.TenantID, &order.ID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		created = append(created, order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The streams are loaded once the rows are closed, as a connection
+	// serves one query at a time.
+	var orders []archivedOrder
+	for _, order := range created {
+		stream, err := s.repository.load(entity.ContextWithTenant(ctx, order.TenantID), tx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !stream.exists() {
+			continue
+		}
+		current := stream.order()
+		orders = append(orders, archivedOrder{
+			TenantID:   order.TenantID,
+			ID:         current.ID,
+			Price:      current.Price,
+			Tax:        current.Tax,
+			FinalPrice: current.FinalPrice,
+			CreatedAt:  current.CreatedAt,
+		})
+	}
+	return orders, nil
+}
+
+func (s eventSourcedArchiveStorage) archive(ctx context.Context, tx *sql.Tx, order archivedOrder) error {
+	return s.repository.appendEventTx(ctx, tx, order.ID, OrderArchivedEventType, nil, requireExistingOrder)
+}
+
+func (s eventSourcedArchiveStorage) restore(ctx context.Context, tx *sql.Tx, order archivedOrder) error {
+	return s.repository.appendEventTx(ctx, tx, order.ID, OrderRestoredEventType, order.order(), requireArchivedOrder)
+}
+
+func findArchive(ctx context.Context, tx *sql.Tx, archiveID int64) (*OrderArchive, error) {
+	archive := &OrderArchive{}
+	var restoredAt sql.NullTime
+	err := tx.QueryRowContext(ctx,
+		"SELECT id, file_name, cutoff, order_count, archived_at, restored_at FROM order_archives WHERE id = ?",
+		archiveID,
+	).Scan(&archive.ID, &archive.FileName, &archive.Cutoff, &archive.OrderCount, &archive.ArchivedAt, &restoredAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrArchiveNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if restoredAt.Valid {
+		archive.RestoredAt = &restoredAt.Time
+	}
+	return archive, nil
+}
+
+// archiveFile is the file of an archive, created with its first batch.
+type archiveFile struct {
+	name string
+	file *os.File
+}
+
+// append writes the orders at the end of the file as a gzip member, which
+// readers of the file take as the continuation of the previous ones, and
+// syncs it. It returns the offset the batch starts at.
+func (f *archiveFile) append(dir string, orders []archivedOrder) (int64, error) {
+	if f.file == nil {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return 0, err
+		}
+		file, err := os.OpenFile(f.name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return 0, err
+		}
+		f.file = file
+	}
+	offset, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	gz := gzip.NewWriter(f.file)
+	encoder := json.NewEncoder(gz)
+	for _, order := range orders {
+		if err := encoder.Encode(order); err != nil {
+			return offset, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return offset, err
+	}
+	return offset, f.file.Sync()
+}
+
+// truncate cuts the batch written from offset on, whose transaction did not
+// commit.
+func (f *archiveFile) truncate(offset int64) error {
+	if f.file == nil {
+		return nil
+	}
+	if err := f.file.Truncate(offset); err != nil {
+		return err
+	}
+	_, err := f.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+func (f *archiveFile) close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// remove deletes the file of an archive left without orders.
+func (f *archiveFile) remove() {
+	if f.file != nil {
+		os.Remove(f.name)
+	}
+}
+
+func readArchiveFile(fileName string) ([]archivedOrder, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var orders []archivedOrder
+	decoder := json.NewDecoder(gz)
+	for decoder.More() {
+		var order archivedOrder
+		if err := decoder.Decode(&order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
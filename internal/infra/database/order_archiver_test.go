@@ -0,0 +1,248 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+const createOrderArchivesTable = "CREATE TABLE order_archives (id INTEGER PRIMARY KEY AUTOINCREMENT, file_name TEXT NOT NULL, cutoff TIMESTAMP NOT NULL, order_count INTEGER NOT NULL, archived_at TIMESTAMP NOT NULL, restored_at TIMESTAMP NULL)"
+
+func newArchiverTest(t *testing.T) (*sql.DB, *OrderArchiver, time.Time) {
+	db := newTestDB(t, createOrdersTable, createOrderHistoryTable, createOrderArchivesTable)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	archiver := NewOrderArchiver(db, t.TempDir())
+	archiver.now = func() time.Time { return now }
+
+	for _, order := range []struct {
+		tenant, id string
+		createdAt  time.Time
+	}{
+		{"store-a", "1", now.AddDate(0, -6, 0)},
+		{"store-b", "1", now.AddDate(0, -4, 0)},
+		{"store-a", "2", now.AddDate(0, 0, -1)},
+	} {
+		_, err := db.Exec("INSERT INTO orders (tenant_id, id, price, tax, final_price, created_at) VALUES (?, ?, 10, 1, 11, ?)", order.tenant, order.id, order.createdAt)
+		require.NoError(t, err)
+	}
+	return db, archiver, now
+}
+
+func countOrders(t *testing.T, db *sql.DB) int {
+	var total int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM orders").Scan(&total))
+	return total
+}
+
+func TestOrderArchiver_ArchiveMovesOldOrdersToCompressedFile(t *testing.T) {
+	ctx := context.Background()
+	db, archiver, now := newArchiverTest(t)
+
+	archive, err := archiver.Archive(ctx, 90*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, archive.OrderCount)
+	assert.Equal(t, now.Add(-90*24*time.Hour), archive.Cutoff)
+	assert.Nil(t, archive.RestoredAt)
+	assert.Equal(t, 1, countOrders(t, db))
+
+	file, err := os.Open(archive.FileName)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"tenant_id":"store-a"`)
+	assert.Contains(t, lines[1], `"tenant_id":"store-b"`)
+
+	var recorded int
+	require.NoError(t, db.QueryRow("SELECT order_count FROM order_archives WHERE id = ?", archive.ID).Scan(&recorded))
+	assert.Equal(t, 2, recorded)
+
+	history, err := NewOrderHistoryRepository(db).ListByOrderID(entity.ContextWithTenant(ctx, "store-b"), "1")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, entity.OrderOperationArchive, history[0].Operation)
+	assert.Equal(t, 11.0, history[0].Before.FinalPrice)
+	assert.Nil(t, history[0].After)
+
+	_, err = archiver.Archive(ctx, 90*24*time.Hour)
+	assert.ErrorIs(t, err, ErrNoOrdersToArchive)
+}
+
+func readArchiveLines(t *testing.T, fileName string) []string {
+	file, err := os.Open(fileName)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestOrderArchiver_ArchivesInBatches(t *testing.T) {
+	ctx := context.Background()
+	db, archiver, _ := newArchiverTest(t)
+	archiver.BatchSize = 1
+
+	archive, err := archiver.Archive(ctx, 90*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, archive.OrderCount)
+	assert.Equal(t, 1, countOrders(t, db))
+	assert.Len(t, readArchiveLines(t, archive.FileName), 2)
+
+	var recorded int
+	require.NoError(t, db.QueryRow("SELECT order_count FROM order_archives WHERE id = ?", archive.ID).Scan(&recorded))
+	assert.Equal(t, 2, recorded)
+
+	_, err = archiver.Restore(ctx, archive.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, countOrders(t, db))
+}
+
+func TestOrderArchiver_KeepsTheBatchesCommittedBeforeAFailure(t *testing.T) {
+	ctx := context.Background()
+	db, archiver, _ := newArchiverTest(t)
+	archiver.BatchSize = 1
+	_, err := db.Exec("CREATE TRIGGER keep_store_b BEFORE DELETE ON orders WHEN OLD.tenant_id = 'store-b' BEGIN SELECT RAISE(ABORT, 'store-b is locked'); END")
+	require.NoError(t, err)
+
+	_, err = archiver.Archive(ctx, 90*24*time.Hour)
+	assert.ErrorContains(t, err, "store-b is locked")
+	assert.Equal(t, 2, countOrders(t, db))
+
+	var fileName string
+	var recorded int
+	require.NoError(t, db.QueryRow("SELECT file_name, order_count FROM order_archives").Scan(&fileName, &recorded))
+	assert.Equal(t, 1, recorded)
+	lines := readArchiveLines(t, fileName)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"tenant_id":"store-a"`)
+}
+
+func TestOrderArchiver_RestoreBringsOrdersBack(t *testing.T) {
+	ctx := context.Background()
+	db, archiver, _ := newArchiverTest(t)
+
+	archive, err := archiver.Archive(ctx, 90*24*time.Hour)
+	require.NoError(t, err)
+
+	restored, err := archiver.Restore(ctx, archive.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, restored.RestoredAt)
+	assert.Equal(t, 3, countOrders(t, db))
+
+	order, err := NewOrderRepository(db).FindByID(entity.ContextWithTenant(ctx, "store-b"), "1")
+	require.NoError(t, err)
+	assert.Equal(t, 11.0, order.FinalPrice)
+
+	history, err := NewOrderHistoryRepository(db).ListByOrderID(entity.ContextWithTenant(ctx, "store-b"), "1")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, entity.OrderOperationRestore, history[1].Operation)
+	assert.Nil(t, history[1].Before)
+	assert.Equal(t, 11.0, history[1].After.FinalPrice)
+
+	_, err = archiver.Restore(ctx, archive.ID)
+	assert.ErrorIs(t, err, ErrArchiveAlreadyRestored)
+	_, err = archiver.Restore(ctx, 42)
+	assert.ErrorIs(t, err, ErrArchiveNotFound)
+}
+
+func TestOrderArchiver_InvalidatesTheCachedOrders(t *testing.T) {
+	ctx := context.Background()
+	db, archiver, _ := newArchiverTest(t)
+	cached := NewCachedOrderRepository(NewOrderRepository(db), 10, time.Minute)
+	archiver.Invalidate = cached.Invalidate
+	storeB := entity.ContextWithTenant(ctx, "store-b")
+
+	_, err := cached.FindByID(storeB, "1")
+	require.NoError(t, err)
+	orders, err := cached.List(storeB)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	archive, err := archiver.Archive(ctx, 90*24*time.Hour)
+	require.NoError(t, err)
+	_, err = cached.FindByID(storeB, "1")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	orders, err = cached.List(storeB)
+	require.NoError(t, err)
+	assert.Empty(t, orders)
+
+	_, err = archiver.Restore(ctx, archive.ID)
+	require.NoError(t, err)
+	orders, err = cached.List(storeB)
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+}
+
+func TestOrderArchiver_RestoreConflictRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db, archiver, _ := newArchiverTest(t)
+
+	archive, err := archiver.Archive(ctx, 90*24*time.Hour)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO orders (tenant_id, id, price, tax, final_price) VALUES ('store-b', '1', 1, 1, 2)")
+	require.NoError(t, err)
+
+	_, err = archiver.Restore(ctx, archive.ID)
+	assert.Error(t, err)
+	assert.Equal(t, 2, countOrders(t, db))
+
+	var restoredAt sql.NullTime
+	require.NoError(t, db.QueryRow("SELECT restored_at FROM order_archives WHERE id = ?", archive.ID).Scan(&restoredAt))
+	assert.False(t, restoredAt.Valid)
+}
+
+func TestOrderArchiver_EventSourced(t *testing.T) {
+	db := newTestDB(t, createOrderEventsTable, createOrderSnapshotsTable, createOrderArchivesTable)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repository := NewEventSourcedOrderRepository(db, 0)
+	archiver := NewEventSourcedOrderArchiver(repository, t.TempDir())
+	archiver.now = func() time.Time { return now }
+	// Archived streams must leave the next batch.
+	archiver.BatchSize = 1
+	ctx := entity.ContextWithTenant(context.Background(), "store-a")
+	require.NoError(t, repository.Save(ctx, &entity.Order{ID: "1", Price: 10, Tax: 1, FinalPrice: 11}))
+	require.NoError(t, repository.Save(ctx, &entity.Order{ID: "2", Price: 20, Tax: 2, FinalPrice: 22}))
+	_, err := db.Exec("UPDATE order_events SET occurred_at = ? WHERE order_id = '1'", now.AddDate(0, -6, 0))
+	require.NoError(t, err)
+
+	archive, err := archiver.Archive(context.Background(), 90*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archive.OrderCount)
+	_, err = repository.FindByID(ctx, "1")
+	assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+	orders, err := repository.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	_, err = archiver.Restore(context.Background(), archive.ID)
+	require.NoError(t, err)
+	order, err := repository.FindByID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 11.0, order.FinalPrice)
+
+	history, err := repository.ListByOrderID(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, entity.OrderOperationArchive, history[1].Operation)
+	assert.Nil(t, history[1].After)
+	assert.Equal(t, entity.OrderOperationRestore, history[2].Operation)
+	assert.Equal(t, 11.0, history[2].After.FinalPrice)
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+type orderSnapshot struct {
+	ID         string    `json:"id"`
+	Price      float64   `json:"price"`
+	Tax        float64   `json:"tax"`
+	FinalPrice float64   `json:"final_price"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OrderHistoryRepository struct {
+	Db *sql.DB
+}
+
+func NewOrderHistoryRepository(db *sql.DB) *OrderHistoryRepository {
+	return &OrderHistoryRepository{Db: db}
+}
+
+func (r *OrderHistoryRepository) ListByOrderID(ctx context.Context, orderID string) ([]entity.OrderHistory, error) {
+	rows, err := r.Db.QueryContext(ctx,
+		"SELECT id, order_id, actor, operation, occurred_at, before_snapshot, after_snapshot FROM order_history WHERE tenant_id = ? AND order_id = ? ORDER BY occurred_at, id",
+		entity.TenantFromContext(ctx), orderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []entity.OrderHistory
+	for rows.Next() {
+		var entry entity.OrderHistory
+		var before, after sql.NullString
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OrderID,
+			&entry.Actor,
+			&entry.Operation,
+			&entry.OccurredAt,
+			&before,
+			&after,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Before, err = unmarshalOrderSnapshot(before); err != nil {
+			return nil, err
+		}
+		if entry.After, err = unmarshalOrderSnapshot(after); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+func marshalOrderSnapshot(order *entity.Order) (sql.NullString, error) {
+	if order == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(orderSnapshot{
+		ID:         order.ID,
+		Price:      order.Price,
+		Tax:        order.Tax,
+		FinalPrice: order.FinalPrice,
+		CreatedAt:  order.CreatedAt,
+	})
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalOrderSnapshot(data sql.NullString) (*entity.Order, error) {
+	if !data.Valid || data.String == "" {
+		return nil, nil
+	}
+	var snapshot orderSnapshot
+	if err := json.Unmarshal([]byte(data.String), &snapshot); err != nil {
+		return nil, err
+	}
+	return &entity.Order{
+		ID:         snapshot.ID,
+		Price:      snapshot.Price,
+		Tax:        snapshot.Tax,
+		FinalPrice: snapshot.FinalPrice,
+		CreatedAt:  snapshot.CreatedAt,
+	}, nil
+}
@@ -1,39 +1,158 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/vs0uz4/clean_architecture/internal/entity"
 )
 
 type OrderRepository struct {
-	Db *sql.DB
+	Db         *sql.DB
+	Router     *DBRouter
+	statements *statementCache
 }
 
 func NewOrderRepository(db *sql.DB) *OrderRepository {
-	return &OrderRepository{Db: db}
+	return &OrderRepository{Db: db, statements: newStatementCache()}
 }
 
-func (r *OrderRepository) Save(order *entity.Order) error {
-	stmt, err := r.Db.Prepare("INSERT INTO orders (id, price, tax, final_price) VALUES (?, ?, ?, ?)")
-	if err != nil {
+func NewRoutedOrderRepository(router *DBRouter) *OrderRepository {
+	return &OrderRepository{Db: router.Primary(), Router: router, statements: newStatementCache()}
+}
+
+func (r *OrderRepository) Close() error {
+	return r.statements.close()
+}
+
+func (r *OrderRepository) Save(ctx context.Context, order *entity.Order) error {
+	tenant := entity.TenantFromContext(ctx)
+	return r.inTx(ctx, func(tx *sql.Tx, stmts *orderStatements) error {
+		_, err := tx.StmtContext(ctx, stmts.insertOrder).ExecContext(ctx, tenant, order.ID, order.Price, order.Tax, order.FinalPrice)
+		if isDuplicateKey(err) {
+			return entity.ErrOrderAlreadyExists
+		}
+		if err != nil {
+			return err
+		}
+		return recordHistory(ctx, tx, stmts, order.ID, entity.OrderOperationCreate, nil, order)
+	})
+}
+
+func (r *OrderRepository) SaveBatch(ctx context.Context, orders []entity.Order) error {
+	tenant := entity.TenantFromContext(ctx)
+	return r.inTx(ctx, func(tx *sql.Tx, stmts *orderStatements) error {
+		insertOrder := tx.StmtContext(ctx, stmts.insertOrder)
+		for i := range orders {
+			order := &orders[i]
+			_, err := insertOrder.ExecContext(ctx, tenant, order.ID, order.Price, order.Tax, order.FinalPrice)
+			if isDuplicateKey(err) {
+				return entity.ErrOrderAlreadyExists
+			}
+			if err != nil {
+				return err
+			}
+			if err := recordHistory(ctx, tx, stmts, order.ID, entity.OrderOperationCreate, nil, order); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *OrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	tenant := entity.TenantFromContext(ctx)
+	return r.inTx(ctx, func(tx *sql.Tx, stmts *orderStatements) error {
+		before, err := scanOrder(tx.StmtContext(ctx, stmts.findOrder).QueryRowContext(ctx, tenant, order.ID))
+		if err != nil {
+			return err
+		}
+		_, err = tx.StmtContext(ctx, stmts.updateOrder).ExecContext(ctx, order.Price, order.Tax, order.FinalPrice, tenant, order.ID)
+		if err != nil {
+			return err
+		}
+		after := *order
+		after.CreatedAt = before.CreatedAt
+		return recordHistory(ctx, tx, stmts, order.ID, entity.OrderOperationUpdate, before, &after)
+	})
+}
+
+func (r *OrderRepository) Delete(ctx context.Context, id string) error {
+	tenant := entity.TenantFromContext(ctx)
+	return r.inTx(ctx, func(tx *sql.Tx, stmts *orderStatements) error {
+		before, err := scanOrder(tx.StmtContext(ctx, stmts.findOrder).QueryRowContext(ctx, tenant, id))
+		if err != nil {
+			return err
+		}
+		_, err = tx.StmtContext(ctx, stmts.deleteOrder).ExecContext(ctx, tenant, id)
+		if err != nil {
+			return err
+		}
+		return recordHistory(ctx, tx, stmts, id, entity.OrderOperationDelete, before, nil)
+	})
+}
+
+func (r *OrderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	var order *entity.Order
+	err := r.read(ctx, func(stmts *orderStatements) error {
+		var err error
+		order, err = scanOrder(stmts.findOrder.QueryRowContext(ctx, entity.TenantFromContext(ctx), id))
 		return err
-	}
-	defer stmt.Close()
-	_, err = stmt.Exec(order.ID, order.Price, order.Tax, order.FinalPrice)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return order, nil
 }
 
-func (r *OrderRepository) List() ([]entity.Order, error) {
+func (r *OrderRepository) List(ctx context.Context) ([]entity.Order, error) {
 	var orders []entity.Order
 
-	rows, err := r.Db.Query("SELECT id, price, tax, final_price, created_at FROM orders ORDER BY created_at DESC")
+	err := r.read(ctx, func(stmts *orderStatements) error {
+		orders = nil
+		rows, err := stmts.listOrders.QueryContext(ctx, entity.TenantFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			order := entity.Order{}
+			err := rows.Scan(
+				&order.ID,
+				&order.Price,
+				&order.Tax,
+				&order.FinalPrice,
+				&order.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+			orders = append(orders, order)
+		}
+		return rows.Err()
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return orders, nil
+}
+
+// Stream walks the tenant's orders row by row without loading them all in
+// memory. It does not fall back to the primary, since fn may already have
+// consumed part of the result when the replica fails.
+func (r *OrderRepository) Stream(ctx context.Context, fn func(order entity.Order) error) error {
+	stmts, err := r.statements.get(ctx, r.reader(ctx))
+	if err != nil {
+		return err
+	}
+	rows, err := stmts.listOrders.QueryContext(ctx, entity.TenantFromContext(ctx))
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
 	for rows.Next() {
@@ -46,19 +165,104 @@ func (r *OrderRepository) List() ([]entity.Order, error) {
 			&order.CreatedAt,
 		)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(order); err != nil {
+			return err
 		}
-		orders = append(orders, order)
 	}
-
-	return orders, nil
+	return rows.Err()
 }
 
-func (r *OrderRepository) GetTotal() (int, error) {
+func (r *OrderRepository) GetTotal(ctx context.Context) (int, error) {
 	var total int
-	err := r.Db.QueryRow("SELECT count(*) FROM orders").Scan(&total)
+	err := r.read(ctx, func(stmts *orderStatements) error {
+		return stmts.countOrders.QueryRowContext(ctx, entity.TenantFromContext(ctx)).Scan(&total)
+	})
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
+
+func (r *OrderRepository) reader(ctx context.Context) *sql.DB {
+	if r.Router == nil || isPrimaryRead(ctx) {
+		return r.Db
+	}
+	return r.Router.Reader()
+}
+
+func (r *OrderRepository) read(ctx context.Context, query func(stmts *orderStatements) error) error {
+	db := r.reader(ctx)
+	stmts, err := r.statements.get(ctx, db)
+	if err == nil {
+		err = query(stmts)
+	}
+	if err == nil || errors.Is(err, entity.ErrOrderNotFound) || db == r.Db {
+		return err
+	}
+	r.Router.MarkReplicaUnhealthy()
+
+	stmts, err = r.statements.get(ctx, r.Db)
+	if err != nil {
+		return err
+	}
+	return query(stmts)
+}
+
+func (r *OrderRepository) inTx(ctx context.Context, fn func(tx *sql.Tx, stmts *orderStatements) error) error {
+	stmts, err := r.statements.get(ctx, r.Db)
+	if err != nil {
+		return err
+	}
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx, stmts); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanOrder(row *sql.Row) (*entity.Order, error) {
+	order := entity.Order{}
+	err := row.Scan(
+		&order.ID,
+		&order.Price,
+		&order.Tax,
+		&order.FinalPrice,
+		&order.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func recordHistory(ctx context.Context, tx *sql.Tx, stmts *orderStatements, orderID, operation string, before, after *entity.Order) error {
+	args, err := historyArgs(ctx, orderID, operation, before, after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.StmtContext(ctx, stmts.insertOrderHistory).ExecContext(ctx, args...)
+	return err
+}
+
+// historyArgs are the insertOrderHistoryQuery arguments of a change made by
+// the tenant and actor in ctx.
+func historyArgs(ctx context.Context, orderID, operation string, before, after *entity.Order) ([]any, error) {
+	beforeSnapshot, err := marshalOrderSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	afterSnapshot, err := marshalOrderSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+	return []any{entity.TenantFromContext(ctx), orderID, entity.ActorFromContext(ctx), operation, time.Now().UTC(), beforeSnapshot, afterSnapshot}, nil
+}
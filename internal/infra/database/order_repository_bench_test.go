@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+// saveOrderPreparingEachCall reproduces the previous Save implementation,
+// which prepared its statements on every call, as the benchmark baseline.
+func saveOrderPreparingEachCall(ctx context.Context, db *sql.DB, order *entity.Order) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, exec := range []struct {
+		query string
+		args  []any
+	}{
+		{insertOrderQuery, []any{order.ID, order.Price, order.Tax, order.FinalPrice}},
+		{insertOrderHistoryQuery, []any{order.ID, entity.AnonymousActor, entity.OrderOperationCreate, time.Now().UTC(), nil, nil}},
+	} {
+		stmt, err := tx.PrepareContext(ctx, exec.query)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.ExecContext(ctx, exec.args...)
+		stmt.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func benchmarkOrder(i int) *entity.Order {
+	return &entity.Order{ID: strconv.Itoa(i), Price: 10, Tax: 2, FinalPrice: 12}
+}
+
+func BenchmarkOrderRepository_Save_PreparedOnce(b *testing.B) {
+	db := newOrdersDB(b)
+	defer db.Close()
+	repo := NewOrderRepository(db)
+	defer repo.Close()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := repo.Save(ctx, benchmarkOrder(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderRepository_Save_PreparedEachCall(b *testing.B) {
+	db := newOrdersDB(b)
+	defer db.Close()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := saveOrderPreparingEachCall(ctx, db, benchmarkOrder(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderRepository_FindByID_PreparedOnce(b *testing.B) {
+	db := newOrdersDB(b)
+	defer db.Close()
+	repo := NewOrderRepository(db)
+	defer repo.Close()
+	ctx := context.Background()
+	if err := repo.Save(ctx, benchmarkOrder(1)); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.FindByID(ctx, "1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderRepository_FindByID_PreparedEachCall(b *testing.B) {
+	db := newOrdersDB(b)
+	defer db.Close()
+	ctx := context.Background()
+	if err := saveOrderPreparingEachCall(ctx, db, benchmarkOrder(1)); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stmt, err := db.PrepareContext(ctx, findOrderQuery)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = scanOrder(stmt.QueryRowContext(ctx, "1"))
+		stmt.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
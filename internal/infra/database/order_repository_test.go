@@ -1,14 +1,21 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/vs0uz4/clean_architecture/internal/entity"
 )
 
+const (
+	createOrdersTable       = "CREATE TABLE orders (tenant_id TEXT NOT NULL DEFAULT 'default', id TEXT NOT NULL, price REAL NOT NULL, tax REAL NOT NULL, final_price REAL NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (tenant_id, id))"
+	createOrderHistoryTable = "CREATE TABLE order_history (id INTEGER PRIMARY KEY AUTOINCREMENT, tenant_id TEXT NOT NULL DEFAULT 'default', order_id TEXT NOT NULL, actor TEXT NOT NULL, operation TEXT NOT NULL, occurred_at TIMESTAMP NOT NULL, before_snapshot TEXT NULL, after_snapshot TEXT NULL)"
+)
+
 type OrderRepositoryTestSuite struct {
 	suite.Suite
 	Db *sql.DB
@@ -17,7 +24,9 @@ type OrderRepositoryTestSuite struct {
 func (suite *OrderRepositoryTestSuite) SetupSuite() {
 	db, err := sql.Open("sqlite3", ":memory:")
 	suite.NoError(err)
-	db.Exec("CREATE TABLE orders (id TEXT PRIMARY KEY NOT NULL, price REAL NOT NULL, tax REAL NOT NULL, final_price REAL NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)")
+	db.SetMaxOpenConns(1)
+	db.Exec(createOrdersTable)
+	db.Exec(createOrderHistoryTable)
 	suite.Db = db
 }
 
@@ -28,9 +37,11 @@ func (suite *OrderRepositoryTestSuite) TearDownSuite() {
 }
 
 func (suite *OrderRepositoryTestSuite) TearDownTest() {
-	_, err := suite.Db.Exec("DELETE FROM orders")
-	if err != nil {
-		suite.T().Errorf("Error when clearing table data: %v", err)
+	for _, table := range []string{"orders", "order_history"} {
+		_, err := suite.Db.Exec("DELETE FROM " + table)
+		if err != nil {
+			suite.T().Errorf("Error when clearing table data: %v", err)
+		}
 	}
 }
 
@@ -44,16 +55,16 @@ func (suite *OrderRepositoryTestSuite) TestGivenOrders_WhenGetTotal_ThenShouldRe
 	order1, err := entity.NewOrder("123", 10.0, 2.0)
 	suite.NoError(err)
 	suite.NoError(order1.CalculateFinalPrice())
-	err = repo.Save(order1)
+	err = repo.Save(context.Background(), order1)
 	suite.NoError(err)
 
 	order2, err := entity.NewOrder("456", 20.0, 4.0)
 	suite.NoError(err)
 	suite.NoError(order2.CalculateFinalPrice())
-	err = repo.Save(order2)
+	err = repo.Save(context.Background(), order2)
 	suite.NoError(err)
 
-	total, err := repo.GetTotal()
+	total, err := repo.GetTotal(context.Background())
 	suite.NoError(err)
 	suite.Equal(2, total)
 }
@@ -61,7 +72,7 @@ func (suite *OrderRepositoryTestSuite) TestGivenOrders_WhenGetTotal_ThenShouldRe
 func (suite *OrderRepositoryTestSuite) TestGivenNoOrders_WhenGetTotal_ThenShouldReturnZero() {
 	repo := NewOrderRepository(suite.Db)
 
-	total, err := repo.GetTotal()
+	total, err := repo.GetTotal(context.Background())
 	suite.NoError(err)
 	suite.Equal(0, total)
 }
@@ -72,16 +83,16 @@ func (suite *OrderRepositoryTestSuite) TestGivenOrders_WhenList_ThenShouldReturn
 	order1, err := entity.NewOrder("123", 10.0, 2.0)
 	suite.NoError(err)
 	suite.NoError(order1.CalculateFinalPrice())
-	err = repo.Save(order1)
+	err = repo.Save(context.Background(), order1)
 	suite.NoError(err)
 
 	order2, err := entity.NewOrder("456", 20.0, 4.0)
 	suite.NoError(err)
 	suite.NoError(order2.CalculateFinalPrice())
-	err = repo.Save(order2)
+	err = repo.Save(context.Background(), order2)
 	suite.NoError(err)
 
-	orders, err := repo.List()
+	orders, err := repo.List(context.Background())
 	suite.NoError(err)
 	suite.Len(orders, 2)
 
@@ -101,7 +112,7 @@ func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenSave_ThenShouldSaveO
 	suite.NoError(err)
 	suite.NoError(order.CalculateFinalPrice())
 	repo := NewOrderRepository(suite.Db)
-	err = repo.Save(order)
+	err = repo.Save(context.Background(), order)
 	suite.NoError(err)
 
 	var orderResult entity.Order
@@ -114,3 +125,212 @@ func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenSave_ThenShouldSaveO
 	suite.Equal(order.Tax, orderResult.Tax)
 	suite.Equal(order.FinalPrice, orderResult.FinalPrice)
 }
+
+func (suite *OrderRepositoryTestSuite) TestGivenAnExistingOrder_WhenSave_ThenShouldReturnAlreadyExists() {
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.Db)
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}))
+
+	err := repo.Save(ctx, &entity.Order{ID: "123", Price: 20, Tax: 2, FinalPrice: 22})
+
+	suite.ErrorIs(err, entity.ErrOrderAlreadyExists)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenFindByID_ThenShouldReturnOrder() {
+	repo := NewOrderRepository(suite.Db)
+
+	order, err := entity.NewOrder("123", 10.0, 2.0)
+	suite.NoError(err)
+	suite.NoError(order.CalculateFinalPrice())
+	suite.NoError(repo.Save(context.Background(), order))
+
+	found, err := repo.FindByID(context.Background(), order.ID)
+	suite.NoError(err)
+	suite.Equal(order.ID, found.ID)
+	suite.Equal(order.Price, found.Price)
+	suite.Equal(order.Tax, found.Tax)
+	suite.Equal(order.FinalPrice, found.FinalPrice)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenNoOrder_WhenFindByID_ThenShouldReturnNotFound() {
+	repo := NewOrderRepository(suite.Db)
+
+	found, err := repo.FindByID(context.Background(), "missing")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	suite.Nil(found)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenUpdate_ThenShouldUpdateOrder() {
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.Db)
+
+	order, err := entity.NewOrder("123", 10.0, 2.0)
+	suite.NoError(err)
+	suite.NoError(order.CalculateFinalPrice())
+	suite.NoError(repo.Save(ctx, order))
+
+	order.Price = 20.0
+	suite.NoError(order.CalculateFinalPrice())
+	suite.NoError(repo.Update(ctx, order))
+
+	found, err := repo.FindByID(ctx, order.ID)
+	suite.NoError(err)
+	suite.Equal(20.0, found.Price)
+	suite.Equal(22.0, found.FinalPrice)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenNoOrder_WhenUpdateOrDelete_ThenShouldReturnNotFound() {
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.Db)
+
+	suite.ErrorIs(repo.Update(ctx, &entity.Order{ID: "missing", Price: 10, Tax: 1}), entity.ErrOrderNotFound)
+	suite.ErrorIs(repo.Delete(ctx, "missing"), entity.ErrOrderNotFound)
+
+	history, err := NewOrderHistoryRepository(suite.Db).ListByOrderID(ctx, "missing")
+	suite.NoError(err)
+	suite.Empty(history)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenOrderChanges_WhenListHistory_ThenShouldReturnEveryOperation() {
+	repo := NewOrderRepository(suite.Db)
+	historyRepo := NewOrderHistoryRepository(suite.Db)
+
+	order, err := entity.NewOrder("123", 10.0, 2.0)
+	suite.NoError(err)
+	suite.NoError(order.CalculateFinalPrice())
+	suite.NoError(repo.Save(entity.ContextWithActor(context.Background(), "alice"), order))
+
+	order.Tax = 3.0
+	suite.NoError(order.CalculateFinalPrice())
+	suite.NoError(repo.Update(entity.ContextWithActor(context.Background(), "bob"), order))
+
+	suite.NoError(repo.Delete(context.Background(), order.ID))
+
+	history, err := historyRepo.ListByOrderID(context.Background(), order.ID)
+	suite.NoError(err)
+	suite.Len(history, 3)
+
+	suite.Equal(entity.OrderOperationCreate, history[0].Operation)
+	suite.Equal("alice", history[0].Actor)
+	suite.Nil(history[0].Before)
+	suite.Equal(12.0, history[0].After.FinalPrice)
+
+	suite.Equal(entity.OrderOperationUpdate, history[1].Operation)
+	suite.Equal("bob", history[1].Actor)
+	suite.Equal(2.0, history[1].Before.Tax)
+	suite.Equal(3.0, history[1].After.Tax)
+
+	suite.Equal(entity.OrderOperationDelete, history[2].Operation)
+	suite.Equal(entity.AnonymousActor, history[2].Actor)
+	suite.Equal(13.0, history[2].Before.FinalPrice)
+	suite.Nil(history[2].After)
+	suite.False(history[2].OccurredAt.IsZero())
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenHistoryInsertFails_WhenSave_ThenShouldRollbackOrder() {
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.Db)
+	defer repo.Close()
+
+	_, err := repo.GetTotal(context.Background())
+	suite.NoError(err)
+
+	_, err = suite.Db.Exec("ALTER TABLE order_history RENAME TO order_history_tmp")
+	suite.NoError(err)
+
+	order, err := entity.NewOrder("123", 10.0, 2.0)
+	suite.NoError(err)
+	suite.Error(repo.Save(ctx, order))
+
+	_, err = suite.Db.Exec("ALTER TABLE order_history_tmp RENAME TO order_history")
+	suite.NoError(err)
+
+	_, err = repo.FindByID(ctx, order.ID)
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenOrdersFromAnotherTenant_WhenAccessing_ThenShouldNotSeeOrChangeThem() {
+	repo := NewOrderRepository(suite.Db)
+	historyRepo := NewOrderHistoryRepository(suite.Db)
+	tenantA := entity.ContextWithTenant(context.Background(), "store-a")
+	tenantB := entity.ContextWithTenant(context.Background(), "store-b")
+
+	suite.NoError(repo.Save(tenantA, &entity.Order{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}))
+
+	_, err := repo.FindByID(tenantB, "123")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	orders, err := repo.List(tenantB)
+	suite.NoError(err)
+	suite.Empty(orders)
+	total, err := repo.GetTotal(tenantB)
+	suite.NoError(err)
+	suite.Equal(0, total)
+	history, err := historyRepo.ListByOrderID(tenantB, "123")
+	suite.NoError(err)
+	suite.Empty(history)
+
+	suite.ErrorIs(repo.Update(tenantB, &entity.Order{ID: "123", Price: 99, Tax: 1, FinalPrice: 100}), entity.ErrOrderNotFound)
+	suite.ErrorIs(repo.Delete(tenantB, "123"), entity.ErrOrderNotFound)
+
+	suite.NoError(repo.Save(tenantB, &entity.Order{ID: "123", Price: 50, Tax: 5, FinalPrice: 55}))
+	found, err := repo.FindByID(tenantB, "123")
+	suite.NoError(err)
+	suite.Equal(55.0, found.FinalPrice)
+
+	found, err = repo.FindByID(tenantA, "123")
+	suite.NoError(err)
+	suite.Equal(12.0, found.FinalPrice)
+	history, err = historyRepo.ListByOrderID(tenantA, "123")
+	suite.NoError(err)
+	suite.Len(history, 1)
+
+	_, err = repo.FindByID(context.Background(), "123")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenABatch_WhenSaveBatch_ThenShouldSaveAllOrNothing() {
+	ctx := entity.ContextWithTenant(context.Background(), "store-a")
+	repo := NewOrderRepository(suite.Db)
+
+	suite.NoError(repo.SaveBatch(ctx, []entity.Order{
+		{ID: "1", Price: 10, Tax: 1, FinalPrice: 11},
+		{ID: "2", Price: 20, Tax: 2, FinalPrice: 22},
+	}))
+	total, err := repo.GetTotal(ctx)
+	suite.NoError(err)
+	suite.Equal(2, total)
+	history, err := NewOrderHistoryRepository(suite.Db).ListByOrderID(ctx, "2")
+	suite.NoError(err)
+	suite.Len(history, 1)
+
+	suite.ErrorIs(repo.SaveBatch(ctx, []entity.Order{
+		{ID: "3", Price: 30, Tax: 3, FinalPrice: 33},
+		{ID: "1", Price: 10, Tax: 1, FinalPrice: 11},
+	}), entity.ErrOrderAlreadyExists)
+	total, err = repo.GetTotal(ctx)
+	suite.NoError(err)
+	suite.Equal(2, total)
+}
+
+func (suite *OrderRepositoryTestSuite) TestGivenOrders_WhenStream_ThenShouldVisitTenantOrders() {
+	tenantA := entity.ContextWithTenant(context.Background(), "store-a")
+	repo := NewOrderRepository(suite.Db)
+
+	suite.NoError(repo.SaveBatch(tenantA, []entity.Order{
+		{ID: "1", Price: 10, Tax: 1, FinalPrice: 11},
+		{ID: "2", Price: 20, Tax: 2, FinalPrice: 22},
+	}))
+	suite.NoError(repo.Save(context.Background(), &entity.Order{ID: "3", Price: 30, Tax: 3, FinalPrice: 33}))
+
+	var ids []string
+	suite.NoError(repo.Stream(tenantA, func(order entity.Order) error {
+		ids = append(ids, order.ID)
+		return nil
+	}))
+	suite.ElementsMatch([]string{"1", "2"}, ids)
+
+	err := repo.Stream(tenantA, func(order entity.Order) error {
+		return assert.AnError
+	})
+	suite.ErrorIs(err, assert.AnError)
+}
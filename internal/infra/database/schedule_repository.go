@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+type ScheduledEventRecord struct {
+	ID           string          `json:"id"`
+	TenantID     string          `json:"tenant_id"`
+	EventName    string          `json:"event_name"`
+	Metadata     events.Metadata `json:"metadata"`
+	Payload      json.RawMessage `json:"payload"`
+	DueAt        time.Time       `json:"due_at"`
+	ScheduledAt  time.Time       `json:"scheduled_at"`
+	DispatchedAt *time.Time      `json:"dispatched_at,omitempty"`
+	CancelledAt  *time.Time      `json:"cancelled_at,omitempty"`
+	FailedAt     *time.Time      `json:"failed_at,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// pendingSchedule matches the events not yet dispatched, cancelled or failed.
+const pendingSchedule = "dispatched_at IS NULL AND cancelled_at IS NULL AND failed_at IS NULL"
+
+// ScheduleRepository is the events.ScheduleStore backed by the
+// scheduled_events table. Events keep the tenant that scheduled them and are
+// dispatched on its behalf.
+type ScheduleRepository struct {
+	Db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{Db: db}
+}
+
+func (r *ScheduleRepository) Schedule(ctx context.Context, scheduled events.ScheduledEvent) error {
+	metadata, err := json.Marshal(scheduled.Metadata)
+	if err != nil {
+		return err
+	}
+	_, err = r.Db.ExecContext(ctx,
+		"INSERT INTO scheduled_events (id, tenant_id, event_name, metadata, payload, due_at, scheduled_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		scheduled.ID, entity.TenantFromContext(ctx), scheduled.EventName, string(metadata), string(scheduled.Payload), scheduled.DueAt.UTC(), time.Now().UTC(),
+	)
+	return err
+}
+
+// Due loads the batch before calling fn, which claims each event with
+// another statement.
+func (r *ScheduleRepository) Due(ctx context.Context, now time.Time, limit int, fn func(ctx context.Context, scheduled events.ScheduledEvent) error) error {
+	records, err := r.list(ctx,
+		" WHERE "+pendingSchedule+" AND due_at <= ? AND (lease_until IS NULL OR lease_until <= ?) ORDER BY due_at, id LIMIT ?",
+		now.UTC(), now.UTC(), limit,
+	)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		scheduled := events.ScheduledEvent{
+			ID:        record.ID,
+			EventName: record.EventName,
+			Metadata:  record.Metadata,
+			Payload:   record.Payload,
+			DueAt:     record.DueAt,
+		}
+		if err := fn(entity.ContextWithTenant(ctx, record.TenantID), scheduled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ScheduleRepository) Claim(ctx context.Context, id string, now, leaseUntil time.Time) error {
+	return r.settle(ctx, id,
+		"UPDATE scheduled_events SET lease_until = ? WHERE id = ? AND "+pendingSchedule+" AND (lease_until IS NULL OR lease_until <= ?)",
+		leaseUntil.UTC(), id, now.UTC(),
+	)
+}
+
+func (r *ScheduleRepository) MarkDispatched(ctx context.Context, id string, dispatchedAt time.Time) error {
+	return r.settle(ctx, id, "UPDATE scheduled_events SET dispatched_at = ? WHERE id = ? AND "+pendingSchedule, dispatchedAt.UTC(), id)
+}
+
+func (r *ScheduleRepository) MarkFailed(ctx context.Context, id string, failedAt time.Time, reason string) error {
+	return r.settle(ctx, id, "UPDATE scheduled_events SET failed_at = ?, error = ? WHERE id = ? AND "+pendingSchedule, failedAt.UTC(), reason, id)
+}
+
+func (r *ScheduleRepository) Cancel(ctx context.Context, id string, cancelledAt time.Time) error {
+	return r.settle(ctx, id, "UPDATE scheduled_events SET cancelled_at = ? WHERE id = ? AND "+pendingSchedule, cancelledAt.UTC(), id)
+}
+
+// List returns the scheduled events by due time, only the pending ones
+// unless all is set.
+func (r *ScheduleRepository) List(ctx context.Context, all bool) ([]ScheduledEventRecord, error) {
+	where := " WHERE " + pendingSchedule
+	if all {
+		where = ""
+	}
+	return r.list(ctx, where+" ORDER BY due_at, id")
+}
+
+// settle runs an update of the pending event id, telling an event that is no
+// longer pending from an unknown one when it updates nothing.
+func (r *ScheduleRepository) settle(ctx context.Context, id, query string, args ...any) error {
+	result, err := r.Db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists int
+	err = r.Db.QueryRowContext(ctx, "SELECT 1 FROM scheduled_events WHERE id = ?", id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return events.ErrScheduleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return events.ErrScheduleNotPending
+}
+
+func (r *ScheduleRepository) list(ctx context.Context, clauses string, args ...any) ([]ScheduledEventRecord, error) {
+	rows, err := r.Db.QueryContext(ctx,
+		"SELECT id, tenant_id, event_name, metadata, payload, due_at, scheduled_at, dispatched_at, cancelled_at, failed_at, error FROM scheduled_events"+clauses,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []ScheduledEventRecord
+	for rows.Next() {
+		var record ScheduledEventRecord
+		var metadata, payload []byte
+		var dispatchedAt, cancelledAt, failedAt sql.NullTime
+		var reason sql.NullString
+		err := rows.Scan(&record.ID, &record.TenantID, &record.EventName, &metadata, &payload, &record.DueAt, &record.ScheduledAt, &dispatchedAt, &cancelledAt, &failedAt, &reason)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(metadata, &record.Metadata); err != nil {
+			return nil, err
+		}
+		record.Payload = payload
+		if dispatchedAt.Valid {
+			record.DispatchedAt = &dispatchedAt.Time
+		}
+		if cancelledAt.Valid {
+			record.CancelledAt = &cancelledAt.Time
+		}
+		if failedAt.Valid {
+			record.FailedAt = &failedAt.Time
+		}
+		record.Error = reason.String
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
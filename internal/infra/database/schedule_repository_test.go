@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+const createScheduledEventsTable = "CREATE TABLE scheduled_events (id TEXT PRIMARY KEY, tenant_id TEXT NOT NULL DEFAULT 'default', event_name TEXT NOT NULL, metadata TEXT NOT NULL, payload TEXT NOT NULL, due_at TIMESTAMP NOT NULL, scheduled_at TIMESTAMP NOT NULL, lease_until TIMESTAMP NULL, dispatched_at TIMESTAMP NULL, cancelled_at TIMESTAMP NULL, failed_at TIMESTAMP NULL, error TEXT NULL)"
+
+func newScheduleRepository(t *testing.T) *ScheduleRepository {
+	return NewScheduleRepository(newTestDB(t, createScheduledEventsTable))
+}
+
+func scheduleEvent(t *testing.T, repository *ScheduleRepository, ctx context.Context, id string, dueAt time.Time) {
+	require.NoError(t, repository.Schedule(ctx, events.ScheduledEvent{
+		ID:        id,
+		EventName: "OrderUnpaid",
+		Metadata:  events.Metadata{ID: id, CorrelationID: "request-1", Version: 1},
+		Payload:   []byte(`{"id":"1"}`),
+		DueAt:     dueAt,
+	}))
+}
+
+func TestScheduleRepository_Due(t *testing.T) {
+	repository := newScheduleRepository(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	scheduleEvent(t, repository, entity.ContextWithTenant(context.Background(), "store-a"), "event-2", now.Add(-time.Minute))
+	scheduleEvent(t, repository, context.Background(), "event-1", now.Add(-time.Hour))
+	scheduleEvent(t, repository, context.Background(), "event-3", now.Add(time.Minute))
+
+	var due []events.ScheduledEvent
+	var tenants []string
+	err := repository.Due(context.Background(), now, 10, func(ctx context.Context, scheduled events.ScheduledEvent) error {
+		due = append(due, scheduled)
+		tenants = append(tenants, entity.TenantFromContext(ctx))
+		return repository.MarkDispatched(ctx, scheduled.ID, now)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, due, 2)
+	assert.Equal(t, "event-1", due[0].ID)
+	assert.Equal(t, "event-2", due[1].ID)
+	assert.Equal(t, []string{entity.DefaultTenant, "store-a"}, tenants)
+	assert.Equal(t, "OrderUnpaid", due[1].EventName)
+	assert.Equal(t, "request-1", due[1].Metadata.CorrelationID)
+	assert.JSONEq(t, `{"id":"1"}`, string(due[1].Payload))
+	assert.True(t, now.Add(-time.Minute).Equal(due[1].DueAt))
+
+	assert.ErrorIs(t, repository.MarkDispatched(context.Background(), "event-1", now), events.ErrScheduleNotPending)
+
+	pending, err := repository.List(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "event-3", pending[0].ID)
+
+	all, err := repository.List(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.NotNil(t, all[0].DispatchedAt)
+}
+
+func TestScheduleRepository_Cancel(t *testing.T) {
+	repository := newScheduleRepository(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	scheduleEvent(t, repository, context.Background(), "event-1", now.Add(-time.Minute))
+
+	require.NoError(t, repository.Cancel(context.Background(), "event-1", now))
+	assert.ErrorIs(t, repository.Cancel(context.Background(), "event-1", now), events.ErrScheduleNotPending)
+	assert.ErrorIs(t, repository.Cancel(context.Background(), "unknown", now), events.ErrScheduleNotFound)
+
+	err := repository.Due(context.Background(), now, 10, func(ctx context.Context, scheduled events.ScheduledEvent) error {
+		t.Errorf("cancelled event %s is due", scheduled.ID)
+		return nil
+	})
+	require.NoError(t, err)
+
+	all, err := repository.List(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.NotNil(t, all[0].CancelledAt)
+	assert.Nil(t, all[0].DispatchedAt)
+}
+
+func TestScheduleRepository_Claim(t *testing.T) {
+	repository := newScheduleRepository(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	scheduleEvent(t, repository, context.Background(), "event-1", now.Add(-time.Minute))
+
+	require.NoError(t, repository.Claim(context.Background(), "event-1", now, now.Add(time.Minute)))
+	assert.ErrorIs(t, repository.Claim(context.Background(), "event-1", now, now.Add(time.Minute)), events.ErrScheduleNotPending)
+	assert.ErrorIs(t, repository.Claim(context.Background(), "unknown", now, now.Add(time.Minute)), events.ErrScheduleNotFound)
+
+	err := repository.Due(context.Background(), now, 10, func(ctx context.Context, scheduled events.ScheduledEvent) error {
+		t.Errorf("leased event %s is due", scheduled.ID)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Once the lease expires another scheduler takes the event over.
+	later := now.Add(time.Minute)
+	var due []string
+	err = repository.Due(context.Background(), later, 10, func(ctx context.Context, scheduled events.ScheduledEvent) error {
+		due = append(due, scheduled.ID)
+		return repository.Claim(ctx, scheduled.ID, later, later.Add(time.Minute))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"event-1"}, due)
+
+	require.NoError(t, repository.MarkDispatched(context.Background(), "event-1", later))
+	assert.ErrorIs(t, repository.Claim(context.Background(), "event-1", later.Add(time.Hour), later.Add(2*time.Hour)), events.ErrScheduleNotPending)
+}
+
+func TestScheduleRepository_MarkFailed(t *testing.T) {
+	repository := newScheduleRepository(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	scheduleEvent(t, repository, context.Background(), "event-1", now.Add(-time.Minute))
+
+	require.NoError(t, repository.Claim(context.Background(), "event-1", now, now.Add(time.Minute)))
+	require.NoError(t, repository.MarkFailed(context.Background(), "event-1", now, "no decoder for event OrderUnpaid"))
+	assert.ErrorIs(t, repository.Claim(context.Background(), "event-1", now.Add(time.Hour), now.Add(2*time.Hour)), events.ErrScheduleNotPending)
+	assert.ErrorIs(t, repository.Cancel(context.Background(), "event-1", now), events.ErrScheduleNotPending)
+
+	pending, err := repository.List(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	all, err := repository.List(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.NotNil(t, all[0].FailedAt)
+	assert.Equal(t, "no decoder for event OrderUnpaid", all[0].Error)
+}
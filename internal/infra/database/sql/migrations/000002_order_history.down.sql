@@ -0,0 +1 @@
+DROP TABLE IF EXISTS order_history;
\ No newline at end of file
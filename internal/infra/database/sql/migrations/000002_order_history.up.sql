@@ -0,0 +1,11 @@
+CREATE TABLE order_history (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    order_id VARCHAR(255) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    occurred_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    before_snapshot JSON NULL,
+    after_snapshot JSON NULL,
+    PRIMARY KEY (id),
+    INDEX idx_order_history_order_id (order_id, occurred_at)
+);
\ No newline at end of file
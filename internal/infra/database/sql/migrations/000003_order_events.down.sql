@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_snapshots;
+DROP TABLE IF EXISTS order_events;
\ No newline at end of file
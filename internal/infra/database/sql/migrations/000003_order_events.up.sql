@@ -0,0 +1,19 @@
+CREATE TABLE order_events (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    order_id VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSON NULL,
+    actor VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_order_events_stream (order_id, version)
+);
+
+CREATE TABLE order_snapshots (
+    order_id VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    state JSON NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (order_id)
+);
\ No newline at end of file
@@ -0,0 +1,19 @@
+ALTER TABLE order_snapshots
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (order_id),
+    DROP COLUMN tenant_id;
+
+ALTER TABLE order_events
+    DROP INDEX uq_order_events_stream,
+    ADD UNIQUE KEY uq_order_events_stream (order_id, version),
+    DROP COLUMN tenant_id;
+
+ALTER TABLE order_history
+    DROP INDEX idx_order_history_order_id,
+    ADD INDEX idx_order_history_order_id (order_id, occurred_at),
+    DROP COLUMN tenant_id;
+
+ALTER TABLE orders
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (id),
+    DROP COLUMN tenant_id;
@@ -0,0 +1,19 @@
+ALTER TABLE orders
+    ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default' FIRST,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (tenant_id, id);
+
+ALTER TABLE order_history
+    ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default' AFTER id,
+    DROP INDEX idx_order_history_order_id,
+    ADD INDEX idx_order_history_order_id (tenant_id, order_id, occurred_at);
+
+ALTER TABLE order_events
+    ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default' AFTER id,
+    DROP INDEX uq_order_events_stream,
+    ADD UNIQUE KEY uq_order_events_stream (tenant_id, order_id, version);
+
+ALTER TABLE order_snapshots
+    ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default' FIRST,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (tenant_id, order_id);
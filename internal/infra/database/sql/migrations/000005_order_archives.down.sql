@@ -0,0 +1 @@
+DROP TABLE IF EXISTS order_archives;
\ No newline at end of file
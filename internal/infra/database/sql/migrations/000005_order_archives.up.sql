@@ -0,0 +1,9 @@
+CREATE TABLE order_archives (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    file_name VARCHAR(1024) NOT NULL,
+    cutoff TIMESTAMP(6) NOT NULL,
+    order_count INT NOT NULL,
+    archived_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    restored_at TIMESTAMP(6) NULL,
+    PRIMARY KEY (id)
+);
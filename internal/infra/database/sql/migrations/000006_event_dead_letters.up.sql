@@ -0,0 +1,13 @@
+CREATE TABLE event_dead_letters (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT 'default',
+    event_name VARCHAR(255) NOT NULL,
+    handler VARCHAR(255) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL,
+    error TEXT NOT NULL,
+    failed_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    redispatched_at TIMESTAMP(6) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_event_dead_letters_pending (redispatched_at, failed_at)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE event_dead_letters
+    ADD COLUMN metadata JSON NULL AFTER handler;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS event_log;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE event_log (
+    seq BIGINT NOT NULL AUTO_INCREMENT,
+    event_id VARCHAR(36) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT 'default',
+    event_name VARCHAR(255) NOT NULL,
+    metadata JSON NOT NULL,
+    payload JSON NOT NULL,
+    occurred_at TIMESTAMP(6) NOT NULL,
+    recorded_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (seq),
+    INDEX idx_event_log_occurred_at (occurred_at),
+    INDEX idx_event_log_name_occurred_at (event_name, occurred_at)
+);
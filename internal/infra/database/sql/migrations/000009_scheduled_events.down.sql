@@ -0,0 +1 @@
+DROP TABLE IF EXISTS scheduled_events;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE scheduled_events (
+    id VARCHAR(36) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT 'default',
+    event_name VARCHAR(255) NOT NULL,
+    metadata JSON NOT NULL,
+    payload JSON NOT NULL,
+    due_at TIMESTAMP(6) NOT NULL,
+    scheduled_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    dispatched_at TIMESTAMP(6) NULL,
+    cancelled_at TIMESTAMP(6) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_scheduled_events_pending (dispatched_at, cancelled_at, due_at)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS event_inbox;
\ No newline at end of file
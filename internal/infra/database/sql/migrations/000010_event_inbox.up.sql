@@ -0,0 +1,10 @@
+CREATE TABLE event_inbox (
+    event_id VARCHAR(36) NOT NULL,
+    handler VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT 'default',
+    claimed_at TIMESTAMP(6) NOT NULL,
+    lease_until TIMESTAMP(6) NOT NULL,
+    processed_at TIMESTAMP(6) NULL,
+    PRIMARY KEY (event_id, handler),
+    INDEX idx_event_inbox_processed_at (processed_at)
+);
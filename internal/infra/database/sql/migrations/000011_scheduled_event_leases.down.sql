@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_events
+    DROP COLUMN lease_until;
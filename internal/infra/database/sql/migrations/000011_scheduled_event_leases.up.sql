@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_events
+    ADD COLUMN lease_until TIMESTAMP(6) NULL AFTER scheduled_at;
@@ -0,0 +1,3 @@
+ALTER TABLE scheduled_events
+    DROP COLUMN error,
+    DROP COLUMN failed_at;
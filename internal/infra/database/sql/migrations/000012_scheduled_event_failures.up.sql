@@ -0,0 +1,3 @@
+ALTER TABLE scheduled_events
+    ADD COLUMN failed_at TIMESTAMP(6) NULL AFTER cancelled_at,
+    ADD COLUMN error TEXT NULL AFTER failed_at;
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+const (
+	insertOrderQuery        = "INSERT INTO orders (tenant_id, id, price, tax, final_price) VALUES (?, ?, ?, ?, ?)"
+	updateOrderQuery        = "UPDATE orders SET price = ?, tax = ?, final_price = ? WHERE tenant_id = ? AND id = ?"
+	deleteOrderQuery        = "DELETE FROM orders WHERE tenant_id = ? AND id = ?"
+	findOrderQuery          = "SELECT id, price, tax, final_price, created_at FROM orders WHERE tenant_id = ? AND id = ?"
+	listOrdersQuery         = "SELECT id, price, tax, final_price, created_at FROM orders WHERE tenant_id = ? ORDER BY created_at DESC"
+	countOrdersQuery        = "SELECT count(*) FROM orders WHERE tenant_id = ?"
+	insertOrderHistoryQuery = "INSERT INTO order_history (tenant_id, order_id, actor, operation, occurred_at, before_snapshot, after_snapshot) VALUES (?, ?, ?, ?, ?, ?, ?)"
+)
+
+type orderStatements struct {
+	insertOrder        *sql.Stmt
+	updateOrder        *sql.Stmt
+	deleteOrder        *sql.Stmt
+	findOrder          *sql.Stmt
+	listOrders         *sql.Stmt
+	countOrders        *sql.Stmt
+	insertOrderHistory *sql.Stmt
+}
+
+func prepareOrderStatements(ctx context.Context, db *sql.DB) (*orderStatements, error) {
+	stmts := &orderStatements{}
+	targets := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&stmts.insertOrder, insertOrderQuery},
+		{&stmts.updateOrder, updateOrderQuery},
+		{&stmts.deleteOrder, deleteOrderQuery},
+		{&stmts.findOrder, findOrderQuery},
+		{&stmts.listOrders, listOrdersQuery},
+		{&stmts.countOrders, countOrdersQuery},
+		{&stmts.insertOrderHistory, insertOrderHistoryQuery},
+	}
+	for _, target := range targets {
+		stmt, err := db.PrepareContext(ctx, target.query)
+		if err != nil {
+			stmts.close()
+			return nil, err
+		}
+		*target.stmt = stmt
+	}
+	return stmts, nil
+}
+
+func (s *orderStatements) close() error {
+	var errs []error
+	for _, stmt := range []*sql.Stmt{s.insertOrder, s.updateOrder, s.deleteOrder, s.findOrder, s.listOrders, s.countOrders, s.insertOrderHistory} {
+		if stmt != nil {
+			errs = append(errs, stmt.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// statementCache prepares the repository statements once per connection pool
+// (primary and replica) and reuses them for every call. The statements are
+// prepared outside the lock, so a pool that is slow or down does not hold up
+// the calls to the other one.
+type statementCache struct {
+	mu   sync.Mutex
+	byDB map[*sql.DB]*orderStatements
+}
+
+func newStatementCache() *statementCache {
+	return &statementCache{byDB: make(map[*sql.DB]*orderStatements)}
+}
+
+func (c *statementCache) get(ctx context.Context, db *sql.DB) (*orderStatements, error) {
+	c.mu.Lock()
+	stmts, ok := c.byDB[db]
+	c.mu.Unlock()
+	if ok {
+		return stmts, nil
+	}
+
+	prepared, err := prepareOrderStatements(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Calls racing on the first use of a pool keep the statements prepared
+	// first and close their own.
+	if stmts, ok := c.byDB[db]; ok {
+		prepared.close()
+		return stmts, nil
+	}
+	c.byDB[db] = prepared
+	return prepared, nil
+}
+
+func (c *statementCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for db, stmts := range c.byDB {
+		errs = append(errs, stmts.close())
+		delete(c.byDB, db)
+	}
+	return errors.Join(errs...)
+}
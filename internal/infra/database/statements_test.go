@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementCache_ConcurrentFirstUse(t *testing.T) {
+	db := newTestDB(t, createOrdersTable, createOrderHistoryTable)
+	cache := newStatementCache()
+	t.Cleanup(func() { cache.close() })
+
+	const callers = 8
+	got := make([]*orderStatements, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stmts, err := cache.get(context.Background(), db)
+			assert.NoError(t, err)
+			got[i] = stmts
+		}()
+	}
+	wg.Wait()
+
+	require.NotNil(t, got[0])
+	for _, stmts := range got {
+		assert.Same(t, got[0], stmts)
+	}
+	var count int
+	require.NoError(t, got[0].countOrders.QueryRow("default").Scan(&count))
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,24 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB opens an in-memory sqlite database with the given tables, closed
+// when the test ends. It keeps a single connection, as each connection to
+// ":memory:" is a database of its own.
+func newTestDB(t testing.TB, schema ...string) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	for _, table := range schema {
+		_, err := db.Exec(table)
+		require.NoError(t, err)
+	}
+	return db
+}
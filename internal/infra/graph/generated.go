@@ -38,6 +38,7 @@ type Config struct {
 
 type ResolverRoot interface {
 	Mutation() MutationResolver
+	Order() OrderResolver
 	Query() QueryResolver
 }
 
@@ -50,6 +51,25 @@ type ComplexityRoot struct {
 	}
 
 	Order struct {
+		CreatedAt  func(childComplexity int) int
+		FinalPrice func(childComplexity int) int
+		History    func(childComplexity int) int
+		ID         func(childComplexity int) int
+		Price      func(childComplexity int) int
+		Tax        func(childComplexity int) int
+	}
+
+	OrderHistoryEntry struct {
+		Actor      func(childComplexity int) int
+		After      func(childComplexity int) int
+		Before     func(childComplexity int) int
+		ID         func(childComplexity int) int
+		OccurredAt func(childComplexity int) int
+		Operation  func(childComplexity int) int
+		OrderID    func(childComplexity int) int
+	}
+
+	OrderSnapshot struct {
 		CreatedAt  func(childComplexity int) int
 		FinalPrice func(childComplexity int) int
 		ID         func(childComplexity int) int
@@ -65,6 +85,9 @@ type ComplexityRoot struct {
 type MutationResolver interface {
 	CreateOrder(ctx context.Context, input *model.OrderInput) (*model.Order, error)
 }
+type OrderResolver interface {
+	History(ctx context.Context, obj *model.Order) ([]*model.OrderHistoryEntry, error)
+}
 type QueryResolver interface {
 	ListOrders(ctx context.Context) ([]*model.Order, error)
 }
@@ -110,6 +133,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Order.FinalPrice(childComplexity), true
 
+	case "Order.history":
+		if e.complexity.Order.History == nil {
+			break
+		}
+
+		return e.complexity.Order.History(childComplexity), true
+
 	case "Order.id":
 		if e.complexity.Order.ID == nil {
 			break
@@ -131,6 +161,90 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Order.Tax(childComplexity), true
 
+	case "OrderHistoryEntry.Actor":
+		if e.complexity.OrderHistoryEntry.Actor == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.Actor(childComplexity), true
+
+	case "OrderHistoryEntry.After":
+		if e.complexity.OrderHistoryEntry.After == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.After(childComplexity), true
+
+	case "OrderHistoryEntry.Before":
+		if e.complexity.OrderHistoryEntry.Before == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.Before(childComplexity), true
+
+	case "OrderHistoryEntry.id":
+		if e.complexity.OrderHistoryEntry.ID == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.ID(childComplexity), true
+
+	case "OrderHistoryEntry.OccurredAt":
+		if e.complexity.OrderHistoryEntry.OccurredAt == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.OccurredAt(childComplexity), true
+
+	case "OrderHistoryEntry.Operation":
+		if e.complexity.OrderHistoryEntry.Operation == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.Operation(childComplexity), true
+
+	case "OrderHistoryEntry.OrderID":
+		if e.complexity.OrderHistoryEntry.OrderID == nil {
+			break
+		}
+
+		return e.complexity.OrderHistoryEntry.OrderID(childComplexity), true
+
+	case "OrderSnapshot.CreatedAt":
+		if e.complexity.OrderSnapshot.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.OrderSnapshot.CreatedAt(childComplexity), true
+
+	case "OrderSnapshot.FinalPrice":
+		if e.complexity.OrderSnapshot.FinalPrice == nil {
+			break
+		}
+
+		return e.complexity.OrderSnapshot.FinalPrice(childComplexity), true
+
+	case "OrderSnapshot.id":
+		if e.complexity.OrderSnapshot.ID == nil {
+			break
+		}
+
+		return e.complexity.OrderSnapshot.ID(childComplexity), true
+
+	case "OrderSnapshot.Price":
+		if e.complexity.OrderSnapshot.Price == nil {
+			break
+		}
+
+		return e.complexity.OrderSnapshot.Price(childComplexity), true
+
+	case "OrderSnapshot.Tax":
+		if e.complexity.OrderSnapshot.Tax == nil {
+			break
+		}
+
+		return e.complexity.OrderSnapshot.Tax(childComplexity), true
+
 	case "Query.listOrders":
 		if e.complexity.Query.ListOrders == nil {
 			break
@@ -294,8 +408,571 @@ func (ec *executionContext) field___Type_fields_args(ctx context.Context, rawArg
 
 // region    **************************** field.gotpl *****************************
 
-func (ec *executionContext) _Mutation_createOrder(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_createOrder(ctx, field)
+func (ec *executionContext) _Mutation_createOrder(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_createOrder(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().CreateOrder(rctx, fc.Args["input"].(*model.OrderInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.Order)
+	fc.Result = res
+	return ec.marshalOOrder2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrder(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_createOrder(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_Order_id(ctx, field)
+			case "Price":
+				return ec.fieldContext_Order_Price(ctx, field)
+			case "Tax":
+				return ec.fieldContext_Order_Tax(ctx, field)
+			case "FinalPrice":
+				return ec.fieldContext_Order_FinalPrice(ctx, field)
+			case "CreatedAt":
+				return ec.fieldContext_Order_CreatedAt(ctx, field)
+			case "history":
+				return ec.fieldContext_Order_history(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type Order", field.Name)
+		},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_Mutation_createOrder_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Order_id(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Order_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Order_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Order",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Order_Price(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Order_Price(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Price, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(float64)
+	fc.Result = res
+	return ec.marshalNFloat2float64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Order_Price(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Order",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Float does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Order_Tax(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Order_Tax(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Tax, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(float64)
+	fc.Result = res
+	return ec.marshalNFloat2float64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Order_Tax(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Order",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Float does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Order_FinalPrice(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Order_FinalPrice(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.FinalPrice, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(float64)
+	fc.Result = res
+	return ec.marshalNFloat2float64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Order_FinalPrice(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Order",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Float does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Order_CreatedAt(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Order_CreatedAt(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CreatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Order_CreatedAt(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Order",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Order_history(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Order_history(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Order().History(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.OrderHistoryEntry)
+	fc.Result = res
+	return ec.marshalNOrderHistoryEntry2ᚕᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderHistoryEntryᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Order_history(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Order",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_OrderHistoryEntry_id(ctx, field)
+			case "OrderID":
+				return ec.fieldContext_OrderHistoryEntry_OrderID(ctx, field)
+			case "Actor":
+				return ec.fieldContext_OrderHistoryEntry_Actor(ctx, field)
+			case "Operation":
+				return ec.fieldContext_OrderHistoryEntry_Operation(ctx, field)
+			case "OccurredAt":
+				return ec.fieldContext_OrderHistoryEntry_OccurredAt(ctx, field)
+			case "Before":
+				return ec.fieldContext_OrderHistoryEntry_Before(ctx, field)
+			case "After":
+				return ec.fieldContext_OrderHistoryEntry_After(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type OrderHistoryEntry", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_id(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_OrderHistoryEntry_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "OrderHistoryEntry",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_OrderID(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_OrderID(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OrderID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_OrderHistoryEntry_OrderID(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "OrderHistoryEntry",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_Actor(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_Actor(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Actor, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_OrderHistoryEntry_Actor(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "OrderHistoryEntry",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_Operation(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_Operation(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Operation, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_OrderHistoryEntry_Operation(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "OrderHistoryEntry",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_OccurredAt(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_OccurredAt(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OccurredAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_OrderHistoryEntry_OccurredAt(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "OrderHistoryEntry",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_Before(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_Before(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -308,7 +985,7 @@ func (ec *executionContext) _Mutation_createOrder(ctx context.Context, field gra
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().CreateOrder(rctx, fc.Args["input"].(*model.OrderInput))
+		return obj.Before, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -317,49 +994,91 @@ func (ec *executionContext) _Mutation_createOrder(ctx context.Context, field gra
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*model.Order)
+	res := resTmp.(*model.OrderSnapshot)
 	fc.Result = res
-	return ec.marshalOOrder2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrder(ctx, field.Selections, res)
+	return ec.marshalOOrderSnapshot2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderSnapshot(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_createOrder(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_OrderHistoryEntry_Before(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "OrderHistoryEntry",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
 			switch field.Name {
 			case "id":
-				return ec.fieldContext_Order_id(ctx, field)
+				return ec.fieldContext_OrderSnapshot_id(ctx, field)
 			case "Price":
-				return ec.fieldContext_Order_Price(ctx, field)
+				return ec.fieldContext_OrderSnapshot_Price(ctx, field)
 			case "Tax":
-				return ec.fieldContext_Order_Tax(ctx, field)
+				return ec.fieldContext_OrderSnapshot_Tax(ctx, field)
 			case "FinalPrice":
-				return ec.fieldContext_Order_FinalPrice(ctx, field)
+				return ec.fieldContext_OrderSnapshot_FinalPrice(ctx, field)
 			case "CreatedAt":
-				return ec.fieldContext_Order_CreatedAt(ctx, field)
+				return ec.fieldContext_OrderSnapshot_CreatedAt(ctx, field)
 			}
-			return nil, fmt.Errorf("no field named %q was found under type Order", field.Name)
+			return nil, fmt.Errorf("no field named %q was found under type OrderSnapshot", field.Name)
 		},
 	}
+	return fc, nil
+}
+
+func (ec *executionContext) _OrderHistoryEntry_After(ctx context.Context, field graphql.CollectedField, obj *model.OrderHistoryEntry) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderHistoryEntry_After(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
 	defer func() {
 		if r := recover(); r != nil {
-			err = ec.Recover(ctx, r)
-			ec.Error(ctx, err)
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
 		}
 	}()
-	ctx = graphql.WithFieldContext(ctx, fc)
-	if fc.Args, err = ec.field_Mutation_createOrder_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.After, nil
+	})
+	if err != nil {
 		ec.Error(ctx, err)
-		return
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.OrderSnapshot)
+	fc.Result = res
+	return ec.marshalOOrderSnapshot2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderSnapshot(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_OrderHistoryEntry_After(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "OrderHistoryEntry",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "id":
+				return ec.fieldContext_OrderSnapshot_id(ctx, field)
+			case "Price":
+				return ec.fieldContext_OrderSnapshot_Price(ctx, field)
+			case "Tax":
+				return ec.fieldContext_OrderSnapshot_Tax(ctx, field)
+			case "FinalPrice":
+				return ec.fieldContext_OrderSnapshot_FinalPrice(ctx, field)
+			case "CreatedAt":
+				return ec.fieldContext_OrderSnapshot_CreatedAt(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type OrderSnapshot", field.Name)
+		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Order_id(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Order_id(ctx, field)
+func (ec *executionContext) _OrderSnapshot_id(ctx context.Context, field graphql.CollectedField, obj *model.OrderSnapshot) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderSnapshot_id(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -389,9 +1108,9 @@ func (ec *executionContext) _Order_id(ctx context.Context, field graphql.Collect
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Order_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_OrderSnapshot_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Order",
+		Object:     "OrderSnapshot",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -402,8 +1121,8 @@ func (ec *executionContext) fieldContext_Order_id(ctx context.Context, field gra
 	return fc, nil
 }
 
-func (ec *executionContext) _Order_Price(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Order_Price(ctx, field)
+func (ec *executionContext) _OrderSnapshot_Price(ctx context.Context, field graphql.CollectedField, obj *model.OrderSnapshot) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderSnapshot_Price(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -433,9 +1152,9 @@ func (ec *executionContext) _Order_Price(ctx context.Context, field graphql.Coll
 	return ec.marshalNFloat2float64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Order_Price(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_OrderSnapshot_Price(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Order",
+		Object:     "OrderSnapshot",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -446,8 +1165,8 @@ func (ec *executionContext) fieldContext_Order_Price(ctx context.Context, field
 	return fc, nil
 }
 
-func (ec *executionContext) _Order_Tax(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Order_Tax(ctx, field)
+func (ec *executionContext) _OrderSnapshot_Tax(ctx context.Context, field graphql.CollectedField, obj *model.OrderSnapshot) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderSnapshot_Tax(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -477,9 +1196,9 @@ func (ec *executionContext) _Order_Tax(ctx context.Context, field graphql.Collec
 	return ec.marshalNFloat2float64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Order_Tax(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_OrderSnapshot_Tax(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Order",
+		Object:     "OrderSnapshot",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -490,8 +1209,8 @@ func (ec *executionContext) fieldContext_Order_Tax(ctx context.Context, field gr
 	return fc, nil
 }
 
-func (ec *executionContext) _Order_FinalPrice(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Order_FinalPrice(ctx, field)
+func (ec *executionContext) _OrderSnapshot_FinalPrice(ctx context.Context, field graphql.CollectedField, obj *model.OrderSnapshot) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderSnapshot_FinalPrice(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -521,9 +1240,9 @@ func (ec *executionContext) _Order_FinalPrice(ctx context.Context, field graphql
 	return ec.marshalNFloat2float64(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Order_FinalPrice(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_OrderSnapshot_FinalPrice(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Order",
+		Object:     "OrderSnapshot",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -534,8 +1253,8 @@ func (ec *executionContext) fieldContext_Order_FinalPrice(ctx context.Context, f
 	return fc, nil
 }
 
-func (ec *executionContext) _Order_CreatedAt(ctx context.Context, field graphql.CollectedField, obj *model.Order) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Order_CreatedAt(ctx, field)
+func (ec *executionContext) _OrderSnapshot_CreatedAt(ctx context.Context, field graphql.CollectedField, obj *model.OrderSnapshot) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_OrderSnapshot_CreatedAt(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -562,9 +1281,9 @@ func (ec *executionContext) _Order_CreatedAt(ctx context.Context, field graphql.
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Order_CreatedAt(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_OrderSnapshot_CreatedAt(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Order",
+		Object:     "OrderSnapshot",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -624,6 +1343,8 @@ func (ec *executionContext) fieldContext_Query_listOrders(ctx context.Context, f
 				return ec.fieldContext_Order_FinalPrice(ctx, field)
 			case "CreatedAt":
 				return ec.fieldContext_Order_CreatedAt(ctx, field)
+			case "history":
+				return ec.fieldContext_Order_history(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Order", field.Name)
 		},
@@ -2636,33 +3357,170 @@ func (ec *executionContext) _Order(ctx context.Context, sel ast.SelectionSet, ob
 			out.Values[i] = ec._Order_id(ctx, field, obj)
 
 			if out.Values[i] == graphql.Null {
-				invalids++
+				atomic.AddUint32(&invalids, 1)
 			}
 		case "Price":
 
 			out.Values[i] = ec._Order_Price(ctx, field, obj)
 
 			if out.Values[i] == graphql.Null {
-				invalids++
+				atomic.AddUint32(&invalids, 1)
 			}
 		case "Tax":
 
 			out.Values[i] = ec._Order_Tax(ctx, field, obj)
 
 			if out.Values[i] == graphql.Null {
-				invalids++
+				atomic.AddUint32(&invalids, 1)
 			}
 		case "FinalPrice":
 
 			out.Values[i] = ec._Order_FinalPrice(ctx, field, obj)
 
 			if out.Values[i] == graphql.Null {
-				invalids++
+				atomic.AddUint32(&invalids, 1)
 			}
 		case "CreatedAt":
 
 			out.Values[i] = ec._Order_CreatedAt(ctx, field, obj)
 
+		case "history":
+			field := field
+
+			innerFunc := func(ctx context.Context) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Order_history(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			}
+
+			out.Concurrently(i, func() graphql.Marshaler {
+				return innerFunc(ctx)
+
+			})
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var orderHistoryEntryImplementors = []string{"OrderHistoryEntry"}
+
+func (ec *executionContext) _OrderHistoryEntry(ctx context.Context, sel ast.SelectionSet, obj *model.OrderHistoryEntry) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, orderHistoryEntryImplementors)
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("OrderHistoryEntry")
+		case "id":
+
+			out.Values[i] = ec._OrderHistoryEntry_id(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "OrderID":
+
+			out.Values[i] = ec._OrderHistoryEntry_OrderID(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "Actor":
+
+			out.Values[i] = ec._OrderHistoryEntry_Actor(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "Operation":
+
+			out.Values[i] = ec._OrderHistoryEntry_Operation(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "OccurredAt":
+
+			out.Values[i] = ec._OrderHistoryEntry_OccurredAt(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "Before":
+
+			out.Values[i] = ec._OrderHistoryEntry_Before(ctx, field, obj)
+
+		case "After":
+
+			out.Values[i] = ec._OrderHistoryEntry_After(ctx, field, obj)
+
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var orderSnapshotImplementors = []string{"OrderSnapshot"}
+
+func (ec *executionContext) _OrderSnapshot(ctx context.Context, sel ast.SelectionSet, obj *model.OrderSnapshot) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, orderSnapshotImplementors)
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("OrderSnapshot")
+		case "id":
+
+			out.Values[i] = ec._OrderSnapshot_id(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "Price":
+
+			out.Values[i] = ec._OrderSnapshot_Price(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "Tax":
+
+			out.Values[i] = ec._OrderSnapshot_Tax(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "FinalPrice":
+
+			out.Values[i] = ec._OrderSnapshot_FinalPrice(ctx, field, obj)
+
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "CreatedAt":
+
+			out.Values[i] = ec._OrderSnapshot_CreatedAt(ctx, field, obj)
+
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -3087,6 +3945,21 @@ func (ec *executionContext) marshalNFloat2float64(ctx context.Context, sel ast.S
 	return graphql.WrapContextMarshaler(ctx, res)
 }
 
+func (ec *executionContext) unmarshalNInt2int(ctx context.Context, v interface{}) (int, error) {
+	res, err := graphql.UnmarshalInt(v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.SelectionSet, v int) graphql.Marshaler {
+	res := graphql.MarshalInt(v)
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+	}
+	return res
+}
+
 func (ec *executionContext) marshalNOrder2ᚕᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.Order) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
@@ -3141,6 +4014,60 @@ func (ec *executionContext) marshalNOrder2ᚖgithubᚗcomᚋvs0uz4ᚋclean_archi
 	return ec._Order(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNOrderHistoryEntry2ᚕᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderHistoryEntryᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.OrderHistoryEntry) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNOrderHistoryEntry2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderHistoryEntry(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNOrderHistoryEntry2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderHistoryEntry(ctx context.Context, sel ast.SelectionSet, v *model.OrderHistoryEntry) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._OrderHistoryEntry(ctx, sel, v)
+}
+
 func (ec *executionContext) unmarshalNString2string(ctx context.Context, v interface{}) (string, error) {
 	res, err := graphql.UnmarshalString(v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -3450,6 +4377,13 @@ func (ec *executionContext) unmarshalOOrderInput2ᚖgithubᚗcomᚋvs0uz4ᚋclea
 	return &res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) marshalOOrderSnapshot2ᚖgithubᚗcomᚋvs0uz4ᚋclean_architectureᚋinternalᚋinfraᚋgraphᚋmodelᚐOrderSnapshot(ctx context.Context, sel ast.SelectionSet, v *model.OrderSnapshot) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._OrderSnapshot(ctx, sel, v)
+}
+
 func (ec *executionContext) unmarshalOString2ᚖstring(ctx context.Context, v interface{}) (*string, error) {
 	if v == nil {
 		return nil, nil
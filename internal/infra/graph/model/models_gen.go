@@ -3,11 +3,22 @@
 package model
 
 type Order struct {
-	ID         string  `json:"id"`
-	Price      float64 `json:"Price"`
-	Tax        float64 `json:"Tax"`
-	FinalPrice float64 `json:"FinalPrice"`
-	CreatedAt  *string `json:"CreatedAt"`
+	ID         string               `json:"id"`
+	Price      float64              `json:"Price"`
+	Tax        float64              `json:"Tax"`
+	FinalPrice float64              `json:"FinalPrice"`
+	CreatedAt  *string              `json:"CreatedAt"`
+	History    []*OrderHistoryEntry `json:"history"`
+}
+
+type OrderHistoryEntry struct {
+	ID         int            `json:"id"`
+	OrderID    string         `json:"OrderID"`
+	Actor      string         `json:"Actor"`
+	Operation  string         `json:"Operation"`
+	OccurredAt string         `json:"OccurredAt"`
+	Before     *OrderSnapshot `json:"Before"`
+	After      *OrderSnapshot `json:"After"`
 }
 
 type OrderInput struct {
@@ -15,3 +26,11 @@ type OrderInput struct {
 	Price float64 `json:"Price"`
 	Tax   float64 `json:"Tax"`
 }
+
+type OrderSnapshot struct {
+	ID         string  `json:"id"`
+	Price      float64 `json:"Price"`
+	Tax        float64 `json:"Tax"`
+	FinalPrice float64 `json:"FinalPrice"`
+	CreatedAt  *string `json:"CreatedAt"`
+}
@@ -1,12 +1,30 @@
 package graph
 
-import "github.com/vs0uz4/clean_architecture/internal/usecase"
+import (
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/infra/graph/model"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	CreateOrderUseCase usecase.CreateOrderUseCase
-	ListOrderUseCase   usecase.ListOrderUseCase
+	CreateOrderUseCase     usecase.CreateOrderUseCase
+	ListOrderUseCase       usecase.ListOrderUseCase
+	GetOrderHistoryUseCase usecase.GetOrderHistoryUseCase
+}
+
+func toOrderSnapshot(order *dto.OrderOutputDTO) *model.OrderSnapshot {
+	if order == nil {
+		return nil
+	}
+	return &model.OrderSnapshot{
+		ID:         order.ID,
+		Price:      order.Price,
+		Tax:        order.Tax,
+		FinalPrice: order.FinalPrice,
+		CreatedAt:  &order.CreatedAt,
+	}
 }
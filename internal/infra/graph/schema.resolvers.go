@@ -6,9 +6,12 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"log"
 
 	"github.com/vs0uz4/clean_architecture/internal/dto"
 	"github.com/vs0uz4/clean_architecture/internal/infra/graph/model"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
 )
 
 // CreateOrder is the resolver for the createOrder field.
@@ -18,7 +21,11 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, input *model.OrderIn
 		Price: float64(input.Price),
 		Tax:   float64(input.Tax),
 	}
-	output, err := r.CreateOrderUseCase.Execute(dto)
+	output, err := r.CreateOrderUseCase.Execute(ctx, dto)
+	if errors.Is(err, usecase.ErrOrderCreatedNotDispatched) {
+		log.Printf("Order %s: %v", output.ID, err)
+		err = nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -31,17 +38,31 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, input *model.OrderIn
 	}, nil
 }
 
-// Mutation returns MutationResolver implementation.
-func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
-
-// Query returns QueryResolver implementation.
-func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+// History is the resolver for the history field.
+func (r *orderResolver) History(ctx context.Context, obj *model.Order) ([]*model.OrderHistoryEntry, error) {
+	history, err := r.GetOrderHistoryUseCase.Execute(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
 
-type mutationResolver struct{ *Resolver }
-type queryResolver struct{ *Resolver }
+	result := []*model.OrderHistoryEntry{}
+	for _, entry := range history {
+		result = append(result, &model.OrderHistoryEntry{
+			ID:         int(entry.ID),
+			OrderID:    entry.OrderID,
+			Actor:      entry.Actor,
+			Operation:  entry.Operation,
+			OccurredAt: entry.OccurredAt,
+			Before:     toOrderSnapshot(entry.Before),
+			After:      toOrderSnapshot(entry.After),
+		})
+	}
+	return result, nil
+}
 
+// ListOrders is the resolver for the listOrders field.
 func (r *queryResolver) ListOrders(ctx context.Context) ([]*model.Order, error) {
-	orders, err := r.ListOrderUseCase.Execute()
+	orders, err := r.ListOrderUseCase.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -58,3 +79,16 @@ func (r *queryResolver) ListOrders(ctx context.Context) ([]*model.Order, error)
 	}
 	return result, nil
 }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Order returns OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const ActorMetadataKey = "x-actor"
+
+func ActorUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ActorMetadataKey); len(values) > 0 {
+			ctx = entity.ContextWithActor(ctx, values[0])
+		}
+	}
+	return handler(ctx, req)
+}
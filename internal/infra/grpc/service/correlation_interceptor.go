@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	CorrelationIDMetadataKey = "x-correlation-id"
+	RequestIDMetadataKey     = "x-request-id"
+)
+
+func CorrelationUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var requestID, correlationID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+		if values := md.Get(CorrelationIDMetadataKey); len(values) > 0 {
+			correlationID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = events.NewID()
+	}
+	if correlationID == "" {
+		correlationID = requestID
+	}
+	ctx = events.ContextWithCausationID(events.ContextWithCorrelationID(ctx, correlationID), requestID)
+	return handler(ctx, req)
+}
@@ -2,23 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
 
 	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
 	"github.com/vs0uz4/clean_architecture/internal/infra/grpc/pb"
 	"github.com/vs0uz4/clean_architecture/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type OrderService struct {
 	pb.UnimplementedOrderServiceServer
-	CreateOrderUseCase usecase.CreateOrderUseCase
-	ListOrderUseCase   usecase.ListOrderUseCase
+	CreateOrderUseCase     usecase.CreateOrderUseCase
+	ListOrderUseCase       usecase.ListOrderUseCase
+	GetOrderHistoryUseCase usecase.GetOrderHistoryUseCase
 }
 
-func NewOrderService(createOrderUseCase usecase.CreateOrderUseCase, listOrderUseCase usecase.ListOrderUseCase) *OrderService {
+func NewOrderService(createOrderUseCase usecase.CreateOrderUseCase, listOrderUseCase usecase.ListOrderUseCase, getOrderHistoryUseCase usecase.GetOrderHistoryUseCase) *OrderService {
 	return &OrderService{
-		CreateOrderUseCase: createOrderUseCase,
-		ListOrderUseCase:   listOrderUseCase,
+		CreateOrderUseCase:     createOrderUseCase,
+		ListOrderUseCase:       listOrderUseCase,
+		GetOrderHistoryUseCase: getOrderHistoryUseCase,
 	}
 }
 
@@ -28,7 +35,11 @@ func (s *OrderService) CreateOrder(ctx context.Context, in *pb.CreateOrderReques
 		Price: float64(in.Price),
 		Tax:   float64(in.Tax),
 	}
-	output, err := s.CreateOrderUseCase.Execute(dto)
+	output, err := s.CreateOrderUseCase.Execute(ctx, dto)
+	if errors.Is(err, usecase.ErrOrderCreatedNotDispatched) {
+		log.Printf("Order %s: %v", output.ID, err)
+		err = nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +53,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, in *pb.CreateOrderReques
 }
 
 func (s *OrderService) ListOrders(ctx context.Context, in *emptypb.Empty) (*pb.ListOrdersResponse, error) {
-	output, err := s.ListOrderUseCase.Execute()
+	output, err := s.ListOrderUseCase.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -64,3 +75,41 @@ func (s *OrderService) ListOrders(ctx context.Context, in *emptypb.Empty) (*pb.L
 
 	return &pb.ListOrdersResponse{Orders: orders}, nil
 }
+
+func (s *OrderService) GetOrderHistory(ctx context.Context, in *pb.GetOrderHistoryRequest) (*pb.OrderHistoryResponse, error) {
+	output, err := s.GetOrderHistoryUseCase.Execute(ctx, in.Id)
+	if errors.Is(err, entity.ErrOrderNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*pb.OrderHistoryEntry, len(output))
+	for i, h := range output {
+		history[i] = &pb.OrderHistoryEntry{
+			Id:         h.ID,
+			OrderId:    h.OrderID,
+			Actor:      h.Actor,
+			Operation:  h.Operation,
+			OccurredAt: h.OccurredAt,
+			Before:     toOrderResponse(h.Before),
+			After:      toOrderResponse(h.After),
+		}
+	}
+
+	return &pb.OrderHistoryResponse{History: history}, nil
+}
+
+func toOrderResponse(o *dto.OrderOutputDTO) *pb.OrderResponse {
+	if o == nil {
+		return nil
+	}
+	return &pb.OrderResponse{
+		Id:         o.ID,
+		Price:      float32(o.Price),
+		Tax:        float32(o.Tax),
+		FinalPrice: float32(o.FinalPrice),
+		CreatedAt:  o.CreatedAt,
+	}
+}
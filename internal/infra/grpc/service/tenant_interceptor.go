@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/infra/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	TenantMetadataKey        = "x-tenant-id"
+	AuthorizationMetadataKey = "authorization"
+)
+
+// TenantClaimUnaryInterceptor sets the tenant from the tenant_id claim of the
+// bearer token in the authorization metadata, taking precedence over the
+// x-tenant-id metadata read by TenantUnaryInterceptor after it. Invalid
+// tokens are rejected. Calls without a token, or whose token has no tenant
+// claim, are left to the metadata, unless the verifier requires the claim,
+// which rejects them too.
+func TenantClaimUnaryInterceptor(verifier *auth.TokenVerifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(AuthorizationMetadataKey)
+		if len(values) == 0 || auth.BearerToken(values[0]) == "" {
+			if verifier.RequireTenant {
+				return nil, status.Error(codes.Unauthenticated, auth.ErrTenantClaimRequired.Error())
+			}
+			return handler(ctx, req)
+		}
+		tenant, err := verifier.Tenant(auth.BearerToken(values[0]))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if tenant != "" {
+			ctx = entity.ContextWithTenant(ctx, tenant)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func TenantUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if _, ok := entity.LookupTenant(ctx); ok {
+		return handler(ctx, req)
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TenantMetadataKey); len(values) > 0 {
+			ctx = entity.ContextWithTenant(ctx, values[0])
+		}
+	}
+	return handler(ctx, req)
+}
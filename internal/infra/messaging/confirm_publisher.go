@@ -0,0 +1,121 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	ErrPublishNotConfirmed = errors.New("message not confirmed by the broker")
+	ErrPublishNacked       = errors.New("message rejected by the broker")
+	ErrPublishReturned     = errors.New("message returned by the broker as unroutable")
+	ErrChannelClosed       = errors.New("channel closed")
+)
+
+// notifyBuffer leaves room for the confirmations and returns of messages that
+// timed out, which nobody reads until the next publish; a full channel would
+// block the connection.
+const notifyBuffer = 64
+
+// defaultConfirmTimeout is used when no confirm timeout is configured, which
+// would otherwise fail every publish before the broker could confirm it.
+const defaultConfirmTimeout = 5 * time.Second
+
+// confirmChannel is the part of *amqp.Channel the publisher uses.
+type confirmChannel interface {
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(returns chan amqp.Return) chan amqp.Return
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// ConfirmPublisher publishes mandatory messages on a channel in confirm mode
+// and waits for the broker to take each one. Publishes are serialized, which
+// keeps matching confirmations and returns to their message simple at the
+// throughput an event handler needs.
+type ConfirmPublisher struct {
+	mu       sync.Mutex
+	channel  confirmChannel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+	// deliveryTag is the tag of the last message published, which the
+	// broker numbers from 1 on each channel.
+	deliveryTag uint64
+	timeout     time.Duration
+}
+
+func NewConfirmPublisher(channel *amqp.Channel, timeout time.Duration) (*ConfirmPublisher, error) {
+	return newConfirmPublisher(channel, timeout)
+}
+
+func newConfirmPublisher(channel confirmChannel, timeout time.Duration) (*ConfirmPublisher, error) {
+	if err := channel.Confirm(false); err != nil {
+		return nil, fmt.Errorf("enabling publisher confirms: %w", err)
+	}
+	if timeout <= 0 {
+		timeout = defaultConfirmTimeout
+	}
+	return &ConfirmPublisher{
+		channel:  channel,
+		confirms: channel.NotifyPublish(make(chan amqp.Confirmation, notifyBuffer)),
+		returns:  channel.NotifyReturn(make(chan amqp.Return, notifyBuffer)),
+		timeout:  timeout,
+	}, nil
+}
+
+// Publish sends the message and returns once the broker acked it, or with an
+// error when it was nacked, returned as unroutable, or not confirmed within
+// the timeout.
+func (p *ConfirmPublisher) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.channel.Publish(exchange, key, true, false, msg); err != nil {
+		return err
+	}
+	p.deliveryTag++
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case confirmation, ok := <-p.confirms:
+			if !ok {
+				return ErrChannelClosed
+			}
+			// Confirmations of messages that timed out earlier arrive late.
+			if confirmation.DeliveryTag < p.deliveryTag {
+				continue
+			}
+			if !confirmation.Ack {
+				return fmt.Errorf("%w: %s to %s", ErrPublishNacked, msg.MessageId, exchange)
+			}
+			return p.returned(exchange, key, msg)
+		case <-timer.C:
+			return fmt.Errorf("%w within %s: %s to %s", ErrPublishNotConfirmed, p.timeout, msg.MessageId, exchange)
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrPublishNotConfirmed, ctx.Err())
+		}
+	}
+}
+
+// returned reports whether the broker returned the message. It sends the
+// return before the ack, so by now it is waiting in the channel.
+func (p *ConfirmPublisher) returned(exchange, key string, msg amqp.Publishing) error {
+	for {
+		select {
+		case ret := <-p.returns:
+			if ret.MessageId != msg.MessageId {
+				continue
+			}
+			return fmt.Errorf("%w: %s to %s with key %q: %s", ErrPublishReturned, msg.MessageId, exchange, key, ret.ReplyText)
+		default:
+			return nil
+		}
+	}
+}
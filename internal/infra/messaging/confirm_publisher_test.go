@@ -0,0 +1,209 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChannel plays the broker: reply decides how it answers each message,
+// identified by its delivery tag.
+type fakeChannel struct {
+	confirms   chan amqp.Confirmation
+	returns    chan amqp.Return
+	confirmErr error
+	publishErr error
+	published  []amqp.Publishing
+	mandatory  []bool
+	reply      func(ch *fakeChannel, tag uint64, msg amqp.Publishing)
+	// consumers receives the channel when a consumer starts on it, and
+	// deliveries is what it consumes.
+	consumers  chan<- *fakeChannel
+	deliveries chan amqp.Delivery
+	prefetch   int
+	closeOnce  sync.Once
+}
+
+func ack(ch *fakeChannel, tag uint64, msg amqp.Publishing) {
+	ch.confirms <- amqp.Confirmation{DeliveryTag: tag, Ack: true}
+}
+
+func (c *fakeChannel) Confirm(noWait bool) error {
+	return c.confirmErr
+}
+
+func (c *fakeChannel) NotifyPublish(confirms chan amqp.Confirmation) chan amqp.Confirmation {
+	c.confirms = confirms
+	return confirms
+}
+
+func (c *fakeChannel) NotifyReturn(returns chan amqp.Return) chan amqp.Return {
+	c.returns = returns
+	return returns
+}
+
+func (c *fakeChannel) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if c.publishErr != nil {
+		return c.publishErr
+	}
+	c.published = append(c.published, msg)
+	c.mandatory = append(c.mandatory, mandatory)
+	if c.reply != nil {
+		c.reply(c, uint64(len(c.published)), msg)
+	}
+	return nil
+}
+
+func (c *fakeChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	return nil
+}
+
+func (c *fakeChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	return amqp.Queue{Name: name}, nil
+}
+
+func (c *fakeChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	return nil
+}
+
+func (c *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	c.prefetch = prefetchCount
+	return nil
+}
+
+func (c *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	c.deliveries = make(chan amqp.Delivery)
+	if c.consumers != nil {
+		c.consumers <- c
+	}
+	return c.deliveries, nil
+}
+
+func (c *fakeChannel) Cancel(consumer string, noWait bool) error {
+	c.closeDeliveries()
+	return nil
+}
+
+func (c *fakeChannel) closeDeliveries() {
+	c.closeOnce.Do(func() { close(c.deliveries) })
+}
+
+func (c *fakeChannel) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	return receiver
+}
+
+func (c *fakeChannel) Close() error {
+	return nil
+}
+
+func newTestPublisher(t *testing.T, channel *fakeChannel) *ConfirmPublisher {
+	publisher, err := newConfirmPublisher(channel, 20*time.Millisecond)
+	require.NoError(t, err)
+	return publisher
+}
+
+func TestConfirmPublisher_Ack(t *testing.T) {
+	channel := &fakeChannel{reply: ack}
+	publisher := newTestPublisher(t, channel)
+
+	err := publisher.Publish(context.Background(), "amq.direct", "", amqp.Publishing{MessageId: "event-1"})
+
+	assert.NoError(t, err)
+	require.Len(t, channel.published, 1)
+	assert.True(t, channel.mandatory[0])
+}
+
+func TestConfirmPublisher_DefaultTimeout(t *testing.T) {
+	channel := &fakeChannel{reply: ack}
+	publisher, err := newConfirmPublisher(channel, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultConfirmTimeout, publisher.timeout)
+	assert.NoError(t, publisher.Publish(context.Background(), "amq.direct", "", amqp.Publishing{MessageId: "event-1"}))
+}
+
+func TestConfirmPublisher_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    func(ch *fakeChannel, tag uint64, msg amqp.Publishing)
+		expected error
+	}{
+		{
+			name: "nack",
+			reply: func(ch *fakeChannel, tag uint64, msg amqp.Publishing) {
+				ch.confirms <- amqp.Confirmation{DeliveryTag: tag, Ack: false}
+			},
+			expected: ErrPublishNacked,
+		},
+		{
+			name: "returned",
+			reply: func(ch *fakeChannel, tag uint64, msg amqp.Publishing) {
+				ch.returns <- amqp.Return{MessageId: msg.MessageId, ReplyText: "NO_ROUTE"}
+				ack(ch, tag, msg)
+			},
+			expected: ErrPublishReturned,
+		},
+		{
+			name:     "not confirmed",
+			reply:    func(ch *fakeChannel, tag uint64, msg amqp.Publishing) {},
+			expected: ErrPublishNotConfirmed,
+		},
+		{
+			name: "channel closed",
+			reply: func(ch *fakeChannel, tag uint64, msg amqp.Publishing) {
+				close(ch.confirms)
+			},
+			expected: ErrChannelClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := newTestPublisher(t, &fakeChannel{reply: tt.reply})
+
+			err := publisher.Publish(context.Background(), "amq.direct", "", amqp.Publishing{MessageId: "event-1"})
+
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestConfirmPublisher_SkipsLateConfirmations(t *testing.T) {
+	var late []amqp.Confirmation
+	channel := &fakeChannel{reply: func(ch *fakeChannel, tag uint64, msg amqp.Publishing) {
+		if tag == 1 {
+			late = append(late, amqp.Confirmation{DeliveryTag: tag, Ack: false})
+			return
+		}
+		for _, confirmation := range late {
+			ch.confirms <- confirmation
+		}
+		ch.returns <- amqp.Return{MessageId: "event-1", ReplyText: "NO_ROUTE"}
+		ack(ch, tag, msg)
+	}}
+	publisher := newTestPublisher(t, channel)
+
+	assert.ErrorIs(t, publisher.Publish(context.Background(), "amq.direct", "", amqp.Publishing{MessageId: "event-1"}), ErrPublishNotConfirmed)
+	assert.NoError(t, publisher.Publish(context.Background(), "amq.direct", "", amqp.Publishing{MessageId: "event-2"}))
+}
+
+func TestConfirmPublisher_Errors(t *testing.T) {
+	_, err := newConfirmPublisher(&fakeChannel{confirmErr: amqp.ErrClosed}, time.Second)
+	assert.ErrorIs(t, err, amqp.ErrClosed)
+
+	publishErr := errors.New("connection reset")
+	publisher := newTestPublisher(t, &fakeChannel{publishErr: publishErr})
+	assert.ErrorIs(t, publisher.Publish(context.Background(), "amq.direct", "", amqp.Publishing{}), publishErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	publisher = newTestPublisher(t, &fakeChannel{})
+	err = publisher.Publish(ctx, "amq.direct", "", amqp.Publishing{})
+	assert.ErrorIs(t, err, ErrPublishNotConfirmed)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,305 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	ErrNotConnected       = errors.New("not connected to the broker")
+	ErrPublishBufferFull  = errors.New("too many publishes waiting for the broker")
+	ErrConnectionShutdown = errors.New("connection shut down")
+)
+
+// OutageMode decides what a publish does while the connection is down.
+type OutageMode string
+
+const (
+	// OutageBuffer holds the publish until the connection is back, up to
+	// OutageBuffer publishes at a time and for as long as its context allows.
+	OutageBuffer OutageMode = "buffer"
+	// OutageFailFast returns ErrNotConnected right away, leaving the retries
+	// to the dispatcher.
+	OutageFailFast OutageMode = "fail"
+)
+
+func ParseOutageMode(value string) (OutageMode, error) {
+	switch mode := OutageMode(value); mode {
+	case OutageBuffer, OutageFailFast:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown outage mode %q", value)
+}
+
+// Channel is the part of *amqp.Channel the connection, the topology and the
+// consumers use.
+type Channel interface {
+	confirmChannel
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+// Topology declares the exchanges, queues and bindings the application
+// needs. It runs on every (re)connection, so it must be idempotent.
+type Topology func(channel Channel) error
+
+type ConnectionConfig struct {
+	URL string
+	// ConnectAttempts bounds the first connection only; once connected the
+	// connection is recovered for as long as it takes.
+	ConnectAttempts int
+	InitialBackoff  time.Duration
+	MaxBackoff      time.Duration
+	ConfirmTimeout  time.Duration
+	OutageMode      OutageMode
+	OutageBuffer    int
+	Topology        Topology
+}
+
+func (c ConnectionConfig) backoff(attempt int) time.Duration {
+	backoff := c.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if c.MaxBackoff > 0 && backoff >= c.MaxBackoff {
+			return c.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// broker opens the channels of one AMQP connection.
+type broker interface {
+	channel() (Channel, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+type amqpBroker struct {
+	*amqp.Connection
+}
+
+func (b amqpBroker) channel() (Channel, error) {
+	return b.Connection.Channel()
+}
+
+func dialAMQP(url string) (broker, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return amqpBroker{conn}, nil
+}
+
+// Connection is an AMQP connection that recovers itself: when the broker
+// connection or its channel closes it reconnects with exponential backoff,
+// declares the topology again and resumes publishing with confirms.
+type Connection struct {
+	config ConnectionConfig
+	dial   func(url string) (broker, error)
+
+	mu        sync.RWMutex
+	broker    broker
+	publisher *ConfirmPublisher
+	// ready is closed once connected, and replaced when the connection drops.
+	ready        chan struct{}
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	buffered     chan struct{}
+	done         chan struct{}
+}
+
+// closeNotifications receives when the connection or its channel closes.
+// They need one Go channel each, as the library closes them on shutdown.
+type closeNotifications struct {
+	connection chan *amqp.Error
+	channel    chan *amqp.Error
+}
+
+// Dial connects to the broker, retrying up to ConnectAttempts times, and
+// keeps the connection up until Close.
+func Dial(ctx context.Context, config ConnectionConfig) (*Connection, error) {
+	return dial(ctx, config, dialAMQP)
+}
+
+func dial(ctx context.Context, config ConnectionConfig, dialer func(url string) (broker, error)) (*Connection, error) {
+	if config.OutageMode == "" {
+		config.OutageMode = OutageBuffer
+	}
+	if config.OutageBuffer <= 0 {
+		config.OutageBuffer = 1
+	}
+	c := &Connection{
+		config:   config,
+		dial:     dialer,
+		ready:    make(chan struct{}),
+		shutdown: make(chan struct{}),
+		buffered: make(chan struct{}, config.OutageBuffer),
+		done:     make(chan struct{}),
+	}
+
+	attempts := max(config.ConnectAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		closed, err := c.connect()
+		if err == nil {
+			go c.watch(closed)
+			return c, nil
+		}
+		if attempt >= attempts {
+			return nil, err
+		}
+		backoff := config.backoff(attempt)
+		log.Printf("Failed to connect to RabbitMQ (attempt %d/%d): %v. Retrying in %s...", attempt, attempts, err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// connect opens the connection and its publishing channel.
+func (c *Connection) connect() (closeNotifications, error) {
+	var closed closeNotifications
+	broker, err := c.dial(c.config.URL)
+	if err != nil {
+		return closed, err
+	}
+	channel, err := broker.channel()
+	if err != nil {
+		broker.Close()
+		return closed, err
+	}
+	if c.config.Topology != nil {
+		if err := c.config.Topology(channel); err != nil {
+			broker.Close()
+			return closed, fmt.Errorf("declaring topology: %w", err)
+		}
+	}
+	publisher, err := newConfirmPublisher(channel, c.config.ConfirmTimeout)
+	if err != nil {
+		broker.Close()
+		return closed, err
+	}
+
+	closed.connection = broker.NotifyClose(make(chan *amqp.Error, 1))
+	closed.channel = channel.NotifyClose(make(chan *amqp.Error, 1))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.broker = broker
+	c.publisher = publisher
+	close(c.ready)
+	return closed, nil
+}
+
+// watch reconnects whenever the connection or the channel closes, until
+// Close.
+func (c *Connection) watch(closed closeNotifications) {
+	defer close(c.done)
+	for {
+		var reason *amqp.Error
+		select {
+		case <-c.shutdown:
+			return
+		case reason = <-closed.connection:
+		case reason = <-closed.channel:
+		}
+		c.disconnect()
+		log.Printf("RabbitMQ connection lost: %v. Reconnecting...", reason)
+
+		for attempt := 1; ; attempt++ {
+			var err error
+			if closed, err = c.connect(); err == nil {
+				log.Printf("RabbitMQ connection recovered after %d attempts", attempt)
+				break
+			}
+			backoff := c.config.backoff(attempt)
+			log.Printf("Failed to reconnect to RabbitMQ (attempt %d): %v. Retrying in %s...", attempt, err, backoff)
+			select {
+			case <-c.shutdown:
+				return
+			case <-time.After(backoff):
+			}
+		}
+	}
+}
+
+func (c *Connection) disconnect() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broker != nil {
+		c.broker.Close()
+	}
+	c.broker = nil
+	c.publisher = nil
+	c.ready = make(chan struct{})
+}
+
+// Publish sends the message with confirms. While the connection is down it
+// waits for it or fails fast, depending on the OutageMode.
+func (c *Connection) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	for {
+		c.mu.RLock()
+		publisher, ready := c.publisher, c.ready
+		c.mu.RUnlock()
+
+		select {
+		case <-c.shutdown:
+			return ErrConnectionShutdown
+		default:
+		}
+		if publisher != nil {
+			return publisher.Publish(ctx, exchange, key, msg)
+		}
+		if c.config.OutageMode == OutageFailFast {
+			return ErrNotConnected
+		}
+		if err := c.wait(ctx, ready); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Connection) wait(ctx context.Context, ready <-chan struct{}) error {
+	select {
+	case c.buffered <- struct{}{}:
+		defer func() { <-c.buffered }()
+	default:
+		return ErrPublishBufferFull
+	}
+	select {
+	case <-ready:
+		return nil
+	case <-c.shutdown:
+		return ErrConnectionShutdown
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrNotConnected, ctx.Err())
+	}
+}
+
+// Close stops recovering the connection and closes it.
+func (c *Connection) Close() error {
+	c.shutdownOnce.Do(func() { close(c.shutdown) })
+	<-c.done
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broker == nil {
+		return nil
+	}
+	err := c.broker.Close()
+	c.broker = nil
+	c.publisher = nil
+	return err
+}
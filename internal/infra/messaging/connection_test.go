@@ -0,0 +1,206 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker hands out connections that ack every message, and refuses new
+// ones while down.
+type fakeBroker struct {
+	mu        sync.Mutex
+	down      bool
+	dials     int
+	current   *fakeConnection
+	consumers chan *fakeChannel
+}
+
+type fakeConnection struct {
+	closed    chan *amqp.Error
+	consumers chan<- *fakeChannel
+
+	mu       sync.Mutex
+	channels []*fakeChannel
+}
+
+func (c *fakeConnection) channel() (Channel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	channel := &fakeChannel{reply: ack, consumers: c.consumers}
+	c.channels = append(c.channels, channel)
+	return channel, nil
+}
+
+func (c *fakeConnection) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	c.closed = receiver
+	return receiver
+}
+
+func (c *fakeConnection) Close() error {
+	return nil
+}
+
+func (b *fakeBroker) dial(url string) (broker, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dials++
+	if b.down {
+		return nil, errors.New("connection refused")
+	}
+	if b.consumers == nil {
+		b.consumers = make(chan *fakeChannel, 10)
+	}
+	b.current = &fakeConnection{consumers: b.consumers}
+	return b.current, nil
+}
+
+// drop closes the current connection, and the consumers on it, and keeps
+// the broker down.
+func (b *fakeBroker) drop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down = true
+	b.current.mu.Lock()
+	for _, channel := range b.current.channels {
+		if channel.deliveries != nil {
+			channel.closeDeliveries()
+		}
+	}
+	b.current.mu.Unlock()
+	b.current.closed <- &amqp.Error{Code: amqp.ConnectionForced, Reason: "broker restarted"}
+}
+
+func (b *fakeBroker) recover() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down = false
+}
+
+func newTestConnection(t *testing.T, broker *fakeBroker, config ConnectionConfig) *Connection {
+	config.InitialBackoff = time.Millisecond
+	config.MaxBackoff = 5 * time.Millisecond
+	config.ConfirmTimeout = time.Second
+	connection, err := dial(context.Background(), config, broker.dial)
+	require.NoError(t, err)
+	t.Cleanup(func() { connection.Close() })
+	return connection
+}
+
+func waitDisconnected(t *testing.T, connection *Connection) {
+	require.Eventually(t, func() bool {
+		connection.mu.RLock()
+		defer connection.mu.RUnlock()
+		return connection.publisher == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestConnection_Reconnects(t *testing.T) {
+	broker := &fakeBroker{}
+	var mu sync.Mutex
+	declared := 0
+	connection := newTestConnection(t, broker, ConnectionConfig{Topology: func(channel Channel) error {
+		mu.Lock()
+		defer mu.Unlock()
+		declared++
+		return nil
+	}})
+	require.NoError(t, connection.Publish(context.Background(), "amq.direct", "", amqp.Publishing{}))
+
+	broker.drop()
+	waitDisconnected(t, connection)
+	broker.recover()
+
+	assert.Eventually(t, func() bool {
+		return connection.Publish(context.Background(), "amq.direct", "", amqp.Publishing{}) == nil
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, declared, "topology declared again")
+}
+
+func TestConnection_OutageFailFast(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{OutageMode: OutageFailFast})
+
+	broker.drop()
+	waitDisconnected(t, connection)
+
+	assert.ErrorIs(t, connection.Publish(context.Background(), "amq.direct", "", amqp.Publishing{}), ErrNotConnected)
+}
+
+func TestConnection_OutageBuffer(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{OutageMode: OutageBuffer, OutageBuffer: 1})
+
+	broker.drop()
+	waitDisconnected(t, connection)
+
+	published := make(chan error)
+	go func() {
+		published <- connection.Publish(context.Background(), "amq.direct", "", amqp.Publishing{MessageId: "event-1"})
+	}()
+	require.Eventually(t, func() bool { return len(connection.buffered) == 1 }, time.Second, time.Millisecond)
+
+	assert.ErrorIs(t, connection.Publish(context.Background(), "amq.direct", "", amqp.Publishing{}), ErrPublishBufferFull)
+
+	broker.recover()
+	select {
+	case err := <-published:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("buffered publish not sent after reconnecting")
+	}
+}
+
+func TestConnection_OutageBuffer_ContextDone(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{OutageBuffer: 10})
+
+	broker.drop()
+	waitDisconnected(t, connection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := connection.Publish(ctx, "amq.direct", "", amqp.Publishing{})
+	assert.ErrorIs(t, err, ErrNotConnected)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDial_Fails(t *testing.T) {
+	broker := &fakeBroker{down: true}
+
+	_, err := dial(context.Background(), ConnectionConfig{ConnectAttempts: 3, InitialBackoff: time.Millisecond}, broker.dial)
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, broker.dials)
+
+	broker = &fakeBroker{}
+	_, err = dial(context.Background(), ConnectionConfig{Topology: func(channel Channel) error { return assert.AnError }}, broker.dial)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestConnection_Close(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+
+	require.NoError(t, connection.Close())
+	require.NoError(t, connection.Close())
+
+	assert.ErrorIs(t, connection.Publish(context.Background(), "amq.direct", "", amqp.Publishing{}), ErrConnectionShutdown)
+}
+
+func TestParseOutageMode(t *testing.T) {
+	mode, err := ParseOutageMode("fail")
+	assert.NoError(t, err)
+	assert.Equal(t, OutageFailFast, mode)
+
+	_, err = ParseOutageMode("drop")
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+var errDeliveriesClosed = errors.New("deliveries channel closed")
+
+// RetryCountHeader counts the failed attempts of a message put back in its
+// queue by the consumer.
+const RetryCountHeader = "retry_count"
+
+// DeliveryHandler processes one message. A nil error acks it; a permanent
+// error (events.Permanent) rejects it to the dead-letter queue; any other
+// error puts it back in the queue until it fails ConsumerConfig.MaxAttempts
+// times, and then rejects it, except events.ErrInboxInProgress, which
+// requeues it after ConsumerConfig.RetryDelay until the delivery holding it
+// is done.
+type DeliveryHandler func(ctx context.Context, delivery amqp.Delivery) error
+
+type ConsumerConfig struct {
+	Queue string
+	// Prefetch is how many unacknowledged messages the broker sends ahead;
+	// it should be at least Concurrency to keep every worker busy.
+	Prefetch    int
+	Concurrency int
+	// RetryDelay is how long a message held by another delivery waits
+	// before going back to the queue, so it is not redelivered in a tight
+	// loop until that delivery is done. Defaults to a second.
+	RetryDelay time.Duration
+	// MaxAttempts is how many times a message is handled before a failure
+	// rejects it. Defaults to 2.
+	MaxAttempts int
+}
+
+const (
+	defaultRetryDelay  = time.Second
+	defaultMaxAttempts = 2
+)
+
+func (c ConsumerConfig) retryDelay() time.Duration {
+	if c.RetryDelay <= 0 {
+		return defaultRetryDelay
+	}
+	return c.RetryDelay
+}
+
+func (c ConsumerConfig) maxAttempts() int {
+	if c.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+var consumerSeq atomic.Int64
+
+// Consume runs handler on the messages of the queue, Concurrency at a time,
+// until ctx is done. It resumes on a new channel whenever the connection is
+// recovered. On shutdown it stops receiving and waits for the messages being
+// handled, which run on a context that is not cancelled with ctx; the ones
+// received but not handled yet are returned to the queue by the broker.
+func (c *Connection) Consume(ctx context.Context, config ConsumerConfig, handler DeliveryHandler) error {
+	for attempt := 1; ; attempt++ {
+		started, err := c.consume(ctx, config, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrConnectionShutdown) {
+			return err
+		}
+		if started {
+			attempt = 1
+		}
+		backoff := c.config.backoff(attempt)
+		log.Printf("Consumer of %s interrupted: %v. Resuming in %s...", config.Queue, err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.shutdown:
+			return ErrConnectionShutdown
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// consume receives from one channel until ctx is done or the channel
+// closes, reporting whether it got to start.
+func (c *Connection) consume(ctx context.Context, config ConsumerConfig, handler DeliveryHandler) (bool, error) {
+	channel, err := c.channel(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer channel.Close()
+
+	if err := channel.Qos(max(config.Prefetch, 1), 0, false); err != nil {
+		return false, fmt.Errorf("setting prefetch: %w", err)
+	}
+	// Failed messages are published back to the queue with their attempt
+	// count, which a requeue cannot change.
+	retries, err := newConfirmPublisher(channel, 0)
+	if err != nil {
+		return false, err
+	}
+	settler := &settler{queue: config.Queue, retries: retries, retryDelay: config.retryDelay(), maxAttempts: config.maxAttempts()}
+	tag := fmt.Sprintf("ordersystem-%d-%d", os.Getpid(), consumerSeq.Add(1))
+	deliveries, err := channel.Consume(config.Queue, tag, false, false, false, false, nil)
+	if err != nil {
+		return false, fmt.Errorf("consuming %s: %w", config.Queue, err)
+	}
+
+	handlerCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for range max(config.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range deliveries {
+				settler.settle(handlerCtx, delivery, handler(handlerCtx, delivery))
+			}
+		}()
+	}
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return true, errDeliveriesClosed
+	case <-ctx.Done():
+		// Cancelling closes deliveries once the broker stops sending.
+		if err := channel.Cancel(tag, false); err != nil {
+			log.Printf("Failed to cancel consumer of %s: %v", config.Queue, err)
+		}
+		<-stopped
+		return true, ctx.Err()
+	}
+}
+
+// channel opens a new channel, waiting for the connection while it is down.
+func (c *Connection) channel(ctx context.Context) (Channel, error) {
+	for {
+		c.mu.RLock()
+		broker, ready := c.broker, c.ready
+		c.mu.RUnlock()
+
+		select {
+		case <-c.shutdown:
+			return nil, ErrConnectionShutdown
+		default:
+		}
+		if broker != nil {
+			return broker.channel()
+		}
+		select {
+		case <-ready:
+		case <-c.shutdown:
+			return nil, ErrConnectionShutdown
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+type settler struct {
+	queue       string
+	retries     *ConfirmPublisher
+	retryDelay  time.Duration
+	maxAttempts int
+}
+
+// settle acks or nacks the delivery by the handler's outcome. A message held
+// by another delivery keeps its worker for retryDelay before being requeued,
+// which also holds back the prefetch slot it takes. A failed message that
+// has attempts left is published again, to the back of the queue, with its
+// count raised, and falls back to a requeue when that publish fails.
+func (s *settler) settle(ctx context.Context, delivery amqp.Delivery, err error) {
+	var settleErr error
+	switch {
+	case err == nil:
+		settleErr = delivery.Ack(false)
+	case errors.Is(err, events.ErrInboxInProgress):
+		time.Sleep(s.retryDelay)
+		settleErr = delivery.Nack(false, true)
+	case events.IsPermanent(err) || retryCount(delivery.Headers)+1 >= s.maxAttempts:
+		log.Printf("Rejecting message %s from %s: %v", delivery.MessageId, delivery.RoutingKey, err)
+		settleErr = delivery.Nack(false, false)
+	default:
+		log.Printf("Retrying message %s from %s: %v", delivery.MessageId, delivery.RoutingKey, err)
+		if retryErr := s.retries.Publish(ctx, "", s.queue, retryPublishing(delivery)); retryErr != nil {
+			log.Printf("Failed to retry message %s, requeueing it: %v", delivery.MessageId, retryErr)
+			settleErr = delivery.Nack(false, true)
+			break
+		}
+		settleErr = delivery.Ack(false)
+	}
+	if settleErr != nil {
+		log.Printf("Failed to settle message %s: %v", delivery.MessageId, settleErr)
+	}
+}
+
+// retryPublishing copies the delivered message with its retry count raised.
+func retryPublishing(delivery amqp.Delivery) amqp.Publishing {
+	headers := amqp.Table{}
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+	headers[RetryCountHeader] = int32(retryCount(delivery.Headers) + 1)
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     delivery.ContentType,
+		ContentEncoding: delivery.ContentEncoding,
+		DeliveryMode:    delivery.DeliveryMode,
+		Priority:        delivery.Priority,
+		CorrelationId:   delivery.CorrelationId,
+		ReplyTo:         delivery.ReplyTo,
+		Expiration:      delivery.Expiration,
+		MessageId:       delivery.MessageId,
+		Timestamp:       delivery.Timestamp,
+		Type:            delivery.Type,
+		UserId:          delivery.UserId,
+		AppId:           delivery.AppId,
+		Body:            delivery.Body,
+	}
+}
+
+func retryCount(headers amqp.Table) int {
+	switch value := headers[RetryCountHeader].(type) {
+	case int8:
+		return int(value)
+	case int16:
+		return int(value)
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// settlements records how each delivery, by its tag, was settled.
+type settlements struct {
+	mu      sync.Mutex
+	settled map[uint64]string
+}
+
+func (s *settlements) record(tag uint64, outcome string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.settled == nil {
+		s.settled = make(map[uint64]string)
+	}
+	s.settled[tag] = outcome
+	return nil
+}
+
+func (s *settlements) Ack(tag uint64, multiple bool) error {
+	return s.record(tag, "ack")
+}
+
+func (s *settlements) Nack(tag uint64, multiple, requeue bool) error {
+	if requeue {
+		return s.record(tag, "requeue")
+	}
+	return s.record(tag, "reject")
+}
+
+func (s *settlements) Reject(tag uint64, requeue bool) error {
+	return s.Nack(tag, false, requeue)
+}
+
+func (s *settlements) of(tag uint64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settled[tag]
+}
+
+func startConsumer(t *testing.T, connection *Connection, config ConsumerConfig, handler DeliveryHandler) (context.CancelFunc, <-chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	consumed := make(chan error, 1)
+	go func() { consumed <- connection.Consume(ctx, config, handler) }()
+	t.Cleanup(cancel)
+	return cancel, consumed
+}
+
+func nextConsumer(t *testing.T, broker *fakeBroker) *fakeChannel {
+	select {
+	case channel := <-broker.consumers:
+		return channel
+	case <-time.After(time.Second):
+		t.Fatal("consumer not started")
+		return nil
+	}
+}
+
+func TestConnection_Consume_SettlesDeliveries(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+	acknowledger := &settlements{}
+	startConsumer(t, connection, ConsumerConfig{Queue: "order_commands", Prefetch: 10, Concurrency: 2, RetryDelay: time.Millisecond}, func(ctx context.Context, delivery amqp.Delivery) error {
+		switch string(delivery.Body) {
+		case "invalid":
+			return events.Permanent(errors.New("invalid order"))
+		case "failing":
+			return errors.New("database unavailable")
+		case "in progress":
+			return events.ErrInboxInProgress
+		}
+		return nil
+	})
+	channel := nextConsumer(t, broker)
+	assert.Equal(t, 10, channel.prefetch)
+
+	deliveries := []amqp.Delivery{
+		{DeliveryTag: 1, Body: []byte("ok")},
+		{DeliveryTag: 2, Body: []byte("invalid")},
+		{DeliveryTag: 3, Body: []byte("failing"), MessageId: "3", Redelivered: true},
+		{DeliveryTag: 4, Body: []byte("failing"), Headers: amqp.Table{RetryCountHeader: int32(1)}},
+		{DeliveryTag: 5, Body: []byte("in progress"), Headers: amqp.Table{RetryCountHeader: int32(1)}},
+	}
+	for _, delivery := range deliveries {
+		delivery.Acknowledger = acknowledger
+		channel.deliveries <- delivery
+	}
+
+	expected := map[uint64]string{1: "ack", 2: "reject", 3: "ack", 4: "reject", 5: "requeue"}
+	for tag, outcome := range expected {
+		assert.Eventually(t, func() bool { return acknowledger.of(tag) == outcome }, time.Second, time.Millisecond, "delivery %d", tag)
+	}
+	// The failed delivery is published back to its queue as its second
+	// attempt, even though the broker redelivered it.
+	require.Len(t, channel.published, 1)
+	assert.Equal(t, "3", channel.published[0].MessageId)
+	assert.Equal(t, []byte("failing"), channel.published[0].Body)
+	assert.Equal(t, int32(1), channel.published[0].Headers[RetryCountHeader])
+}
+
+func TestConnection_Consume_RequeuesWhenTheRetryIsNotPublished(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+	acknowledger := &settlements{}
+	startConsumer(t, connection, ConsumerConfig{Queue: "order_commands", MaxAttempts: 3}, func(ctx context.Context, delivery amqp.Delivery) error {
+		return errors.New("database unavailable")
+	})
+	channel := nextConsumer(t, broker)
+	channel.publishErr = errors.New("channel closed")
+
+	channel.deliveries <- amqp.Delivery{DeliveryTag: 1, Headers: amqp.Table{RetryCountHeader: int32(1)}, Acknowledger: acknowledger}
+	assert.Eventually(t, func() bool { return acknowledger.of(1) == "requeue" }, time.Second, time.Millisecond)
+}
+
+func TestConnection_Consume_WaitsForDeliveriesOnShutdown(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+	acknowledger := &settlements{}
+	handling, release := make(chan struct{}), make(chan struct{})
+	cancel, consumed := startConsumer(t, connection, ConsumerConfig{Queue: "order_commands"}, func(ctx context.Context, delivery amqp.Delivery) error {
+		close(handling)
+		<-release
+		return ctx.Err()
+	})
+	channel := nextConsumer(t, broker)
+	channel.deliveries <- amqp.Delivery{DeliveryTag: 1, Acknowledger: acknowledger}
+	<-handling
+
+	cancel()
+	select {
+	case <-consumed:
+		t.Fatal("consumer returned before the delivery was handled")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-consumed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("consumer did not stop")
+	}
+	assert.Equal(t, "ack", acknowledger.of(1))
+}
+
+func TestConnection_Consume_ResumesAfterReconnecting(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+	acknowledger := &settlements{}
+	startConsumer(t, connection, ConsumerConfig{Queue: "order_commands"}, func(ctx context.Context, delivery amqp.Delivery) error {
+		return nil
+	})
+	nextConsumer(t, broker)
+
+	broker.drop()
+	waitDisconnected(t, connection)
+	broker.recover()
+
+	channel := nextConsumer(t, broker)
+	channel.deliveries <- amqp.Delivery{DeliveryTag: 1, Acknowledger: acknowledger}
+	assert.Eventually(t, func() bool { return acknowledger.of(1) == "ack" }, time.Second, time.Millisecond)
+}
+
+func TestConnection_Consume_Shutdown(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+	require.NoError(t, connection.Close())
+
+	err := connection.Consume(context.Background(), ConsumerConfig{Queue: "order_commands"}, func(ctx context.Context, delivery amqp.Delivery) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrConnectionShutdown)
+}
+
+func TestConnection_Consume_DelaysInProgressRequeues(t *testing.T) {
+	broker := &fakeBroker{}
+	connection := newTestConnection(t, broker, ConnectionConfig{})
+	acknowledger := &settlements{}
+	startConsumer(t, connection, ConsumerConfig{Queue: "order_commands", RetryDelay: 50 * time.Millisecond}, func(ctx context.Context, delivery amqp.Delivery) error {
+		return events.ErrInboxInProgress
+	})
+	channel := nextConsumer(t, broker)
+
+	delivered := time.Now()
+	channel.deliveries <- amqp.Delivery{DeliveryTag: 1, Acknowledger: acknowledger}
+	assert.Eventually(t, func() bool { return acknowledger.of(1) == "requeue" }, time.Second, time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(delivered), 50*time.Millisecond)
+}
@@ -0,0 +1,170 @@
+package messaging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/streadway/amqp"
+)
+
+// Route is where the messages of an event are published.
+type Route struct {
+	Exchange   string
+	RoutingKey string
+}
+
+// Routes maps event names to their Route. Events without one go to the
+// default exchange with their name as routing key.
+type Routes struct {
+	defaultExchange string
+	routes          map[string]Route
+}
+
+// ParseRoutes reads routes written as "Event=exchange:key" or "Event=key",
+// the latter using the default exchange, separated by commas.
+func ParseRoutes(value, defaultExchange string) (Routes, error) {
+	routes := Routes{defaultExchange: defaultExchange, routes: make(map[string]Route)}
+	for _, entry := range splitList(value) {
+		eventName, target, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(eventName) == "" {
+			return Routes{}, fmt.Errorf("invalid event route %q, expected Event=exchange:key", entry)
+		}
+		eventName, target = strings.TrimSpace(eventName), strings.TrimSpace(target)
+		route := Route{Exchange: defaultExchange, RoutingKey: target}
+		if exchange, key, ok := strings.Cut(target, ":"); ok {
+			route = Route{Exchange: exchange, RoutingKey: key}
+		}
+		if route.Exchange == "" {
+			return Routes{}, fmt.Errorf("invalid event route %q, no exchange", entry)
+		}
+		routes.routes[eventName] = route
+	}
+	return routes, nil
+}
+
+func (r Routes) For(eventName string) Route {
+	if route, ok := r.routes[eventName]; ok {
+		return route
+	}
+	return Route{Exchange: r.defaultExchange, RoutingKey: eventName}
+}
+
+// Exchanges returns the default exchange and the ones the routes use, all
+// of the given kind.
+func (r Routes) Exchanges(kind string) []Exchange {
+	names := map[string]bool{r.defaultExchange: true}
+	for _, route := range r.routes {
+		names[route.Exchange] = true
+	}
+	exchanges := make([]Exchange, 0, len(names))
+	for name := range names {
+		exchanges = append(exchanges, Exchange{Name: name, Kind: kind})
+	}
+	sort.Slice(exchanges, func(i, j int) bool { return exchanges[i].Name < exchanges[j].Name })
+	return exchanges
+}
+
+type Exchange struct {
+	Name string
+	Kind string
+}
+
+// Queue is a durable queue declared with its dead-letter queue: messages
+// rejected without requeue are moved to DeadLetterQueue, through the default
+// exchange, instead of being dropped.
+type Queue struct {
+	Name            string
+	DeadLetterQueue string
+}
+
+// Binding routes the messages published to Exchange with a routing key
+// matching Key to Queue.
+type Binding struct {
+	Queue    string
+	Exchange string
+	Key      string
+}
+
+// ParseBindings reads bindings to the given exchange written as "queue:key",
+// separated by commas. A queue bound with several keys is repeated.
+func ParseBindings(value, exchange string) ([]Binding, error) {
+	var bindings []Binding
+	for _, entry := range splitList(value) {
+		queue, key, ok := strings.Cut(entry, ":")
+		if !ok || queue == "" {
+			return nil, fmt.Errorf("invalid queue binding %q, expected queue:key", entry)
+		}
+		bindings = append(bindings, Binding{Queue: queue, Exchange: exchange, Key: key})
+	}
+	return bindings, nil
+}
+
+func ParseExchangeKind(value string) (string, error) {
+	switch value {
+	case amqp.ExchangeDirect, amqp.ExchangeTopic, amqp.ExchangeFanout, amqp.ExchangeHeaders:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown exchange type %q", value)
+}
+
+// TopologyConfig is the durable exchanges, queues and bindings the
+// application publishes to, declared on every connection so it runs against
+// a broker with nothing set up.
+type TopologyConfig struct {
+	Exchanges []Exchange
+	Queues    []Queue
+	Bindings  []Binding
+}
+
+func (t TopologyConfig) Declare(channel Channel) error {
+	for _, exchange := range t.Exchanges {
+		// The amq.* exchanges always exist and cannot be declared.
+		if strings.HasPrefix(exchange.Name, "amq.") {
+			continue
+		}
+		if err := channel.ExchangeDeclare(exchange.Name, exchange.Kind, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("declaring exchange %s: %w", exchange.Name, err)
+		}
+	}
+	declared := make(map[string]bool)
+	for _, queue := range t.Queues {
+		var args amqp.Table
+		if queue.DeadLetterQueue != "" {
+			if _, err := channel.QueueDeclare(queue.DeadLetterQueue, true, false, false, false, nil); err != nil {
+				return fmt.Errorf("declaring queue %s: %w", queue.DeadLetterQueue, err)
+			}
+			declared[queue.DeadLetterQueue] = true
+			args = amqp.Table{
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queue.DeadLetterQueue,
+			}
+		}
+		if _, err := channel.QueueDeclare(queue.Name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("declaring queue %s: %w", queue.Name, err)
+		}
+		declared[queue.Name] = true
+	}
+	for _, binding := range t.Bindings {
+		if !declared[binding.Queue] {
+			if _, err := channel.QueueDeclare(binding.Queue, true, false, false, false, nil); err != nil {
+				return fmt.Errorf("declaring queue %s: %w", binding.Queue, err)
+			}
+			declared[binding.Queue] = true
+		}
+		if err := channel.QueueBind(binding.Queue, binding.Key, binding.Exchange, false, nil); err != nil {
+			return fmt.Errorf("binding queue %s to %s with key %q: %w", binding.Queue, binding.Exchange, binding.Key, err)
+		}
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
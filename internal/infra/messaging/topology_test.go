@@ -0,0 +1,101 @@
+package messaging
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// declaringChannel records what is declared on it.
+type declaringChannel struct {
+	fakeChannel
+	declared []string
+}
+
+func (c *declaringChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	c.declared = append(c.declared, fmt.Sprintf("exchange %s %s durable=%t", name, kind, durable))
+	return nil
+}
+
+func (c *declaringChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	declared := fmt.Sprintf("queue %s durable=%t", name, durable)
+	if deadLetterQueue, ok := args["x-dead-letter-routing-key"]; ok {
+		declared += fmt.Sprintf(" dead-letter=%v", deadLetterQueue)
+	}
+	c.declared = append(c.declared, declared)
+	return amqp.Queue{Name: name}, nil
+}
+
+func (c *declaringChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	c.declared = append(c.declared, fmt.Sprintf("bind %s %s %s", name, exchange, key))
+	return nil
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("OrderCreated=order.created, OrderCancelled = audit:order.cancelled", "orders")
+	require.NoError(t, err)
+
+	assert.Equal(t, Route{Exchange: "orders", RoutingKey: "order.created"}, routes.For("OrderCreated"))
+	assert.Equal(t, Route{Exchange: "audit", RoutingKey: "order.cancelled"}, routes.For("OrderCancelled"))
+	assert.Equal(t, Route{Exchange: "orders", RoutingKey: "OrderPaid"}, routes.For("OrderPaid"))
+	assert.Equal(t, []Exchange{{Name: "audit", Kind: "topic"}, {Name: "orders", Kind: "topic"}}, routes.Exchanges("topic"))
+
+	routes, err = ParseRoutes("", "amq.direct")
+	require.NoError(t, err)
+	assert.Equal(t, Route{Exchange: "amq.direct", RoutingKey: "OrderCreated"}, routes.For("OrderCreated"))
+
+	for _, invalid := range []string{"OrderCreated", "=order.created", "OrderCreated=:order.created"} {
+		_, err := ParseRoutes(invalid, "orders")
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseBindings(t *testing.T) {
+	bindings, err := ParseBindings("orders:order.*, audit:#, orders:payment.*", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, []Binding{
+		{Queue: "orders", Exchange: "orders", Key: "order.*"},
+		{Queue: "audit", Exchange: "orders", Key: "#"},
+		{Queue: "orders", Exchange: "orders", Key: "payment.*"},
+	}, bindings)
+
+	_, err = ParseBindings("orders", "orders")
+	assert.Error(t, err)
+}
+
+func TestParseExchangeKind(t *testing.T) {
+	kind, err := ParseExchangeKind("topic")
+	assert.NoError(t, err)
+	assert.Equal(t, amqp.ExchangeTopic, kind)
+
+	_, err = ParseExchangeKind("x-delayed")
+	assert.Error(t, err)
+}
+
+func TestTopologyConfig_Declare(t *testing.T) {
+	channel := &declaringChannel{}
+	topology := TopologyConfig{
+		Exchanges: []Exchange{{Name: "amq.direct", Kind: "direct"}, {Name: "orders", Kind: "topic"}},
+		Queues:    []Queue{{Name: "order_commands", DeadLetterQueue: "order_commands.dead-letter"}},
+		Bindings: []Binding{
+			{Queue: "orders", Exchange: "orders", Key: "order.*"},
+			{Queue: "orders", Exchange: "amq.direct", Key: ""},
+			{Queue: "order_commands", Exchange: "orders", Key: "command.order.create"},
+		},
+	}
+
+	require.NoError(t, topology.Declare(channel))
+
+	assert.Equal(t, []string{
+		"exchange orders topic durable=true",
+		"queue order_commands.dead-letter durable=true",
+		"queue order_commands durable=true dead-letter=order_commands.dead-letter",
+		"queue orders durable=true",
+		"bind orders orders order.*",
+		"bind orders amq.direct ",
+		"bind order_commands orders command.order.create",
+	}, channel.declared)
+}
@@ -0,0 +1,16 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+const ActorHeader = "X-Actor"
+
+func ActorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := entity.ContextWithActor(r.Context(), r.Header.Get(ActorHeader))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
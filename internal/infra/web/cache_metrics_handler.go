@@ -0,0 +1,32 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vs0uz4/clean_architecture/pkg/cache"
+)
+
+// CacheStats is a cache reporting its hits and misses, such as
+// database.CachedOrderRepository.
+type CacheStats interface {
+	Stats() cache.Stats
+}
+
+type WebCacheMetricsHandler struct {
+	Cache CacheStats
+}
+
+func NewWebCacheMetricsHandler(cache CacheStats) *WebCacheMetricsHandler {
+	return &WebCacheMetricsHandler{
+		Cache: cache,
+	}
+}
+
+func (h *WebCacheMetricsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Cache.Stats()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/pkg/cache"
+)
+
+type fixedCacheStats cache.Stats
+
+func (s fixedCacheStats) Stats() cache.Stats { return cache.Stats(s) }
+
+func TestWebCacheMetricsHandler_Get(t *testing.T) {
+	handler := NewWebCacheMetricsHandler(fixedCacheStats{Hits: 8, Misses: 2, Evictions: 1, Size: 5, Capacity: 1000})
+	rr := httptest.NewRecorder()
+	handler.Get(rr, httptest.NewRequest(http.MethodGet, "/orders/cache/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"hits":8,"misses":2,"evictions":1,"size":5,"capacity":1000}`, rr.Body.String())
+}
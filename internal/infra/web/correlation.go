@@ -0,0 +1,35 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	RequestIDHeader     = "X-Request-ID"
+)
+
+// CorrelationMiddleware puts the correlation of the request in the context,
+// so the events it creates carry it. The caller's X-Correlation-ID is kept,
+// otherwise the request starts a new correlation; the request itself, by its
+// X-Request-ID or a new ID, is the cause of those events. Both IDs are echoed
+// in the response.
+func CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = events.NewID()
+		}
+		correlationID := r.Header.Get(CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = requestID
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		w.Header().Set(CorrelationIDHeader, correlationID)
+		ctx := events.ContextWithCausationID(events.ContextWithCorrelationID(r.Context(), correlationID), requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
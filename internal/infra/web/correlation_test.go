@@ -0,0 +1,34 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+func TestCorrelationMiddleware(t *testing.T) {
+	var correlationID, causationID string
+	handler := CorrelationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID = events.CorrelationIDFromContext(r.Context())
+		causationID = events.CausationIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/order", nil)
+	req.Header.Set(CorrelationIDHeader, "correlation-1")
+	req.Header.Set(RequestIDHeader, "request-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, "correlation-1", correlationID)
+	assert.Equal(t, "request-1", causationID)
+	assert.Equal(t, "correlation-1", rr.Header().Get(CorrelationIDHeader))
+	assert.Equal(t, "request-1", rr.Header().Get(RequestIDHeader))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/order", nil))
+	assert.NotEmpty(t, correlationID)
+	assert.Equal(t, correlationID, causationID)
+	assert.Equal(t, correlationID, rr.Header().Get(CorrelationIDHeader))
+}
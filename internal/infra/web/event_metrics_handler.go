@@ -0,0 +1,35 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+type WebEventMetricsHandler struct {
+	HandlerMetrics *events.HandlerMetrics
+}
+
+func NewWebEventMetricsHandler(handlerMetrics *events.HandlerMetrics) *WebEventMetricsHandler {
+	return &WebEventMetricsHandler{
+		HandlerMetrics: handlerMetrics,
+	}
+}
+
+func (h *WebEventMetricsHandler) List(w http.ResponseWriter, r *http.Request) {
+	stats := h.HandlerMetrics.Snapshot()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].EventName != stats[j].EventName {
+			return stats[i].EventName < stats[j].EventName
+		}
+		return stats[i].Handler < stats[j].Handler
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
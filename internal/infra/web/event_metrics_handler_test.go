@@ -0,0 +1,24 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+func TestWebEventMetricsHandler_List(t *testing.T) {
+	metrics := events.NewHandlerMetrics()
+	metrics.Observe("OrderCreated", "*handler.OrderCreatedHandler", 3*time.Millisecond, nil)
+	metrics.Observe("OrderCreated", "*handler.OrderCreatedHandler", 5*time.Millisecond, assert.AnError)
+
+	handler := NewWebEventMetricsHandler(metrics)
+	rr := httptest.NewRecorder()
+	handler.List(rr, httptest.NewRequest(http.MethodGet, "/events/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"event_name":"OrderCreated","handler":"*handler.OrderCreatedHandler","calls":2,"failures":1,"total_duration":8000000,"max_duration":5000000}]`, rr.Body.String())
+}
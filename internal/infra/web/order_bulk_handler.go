@@ -0,0 +1,75 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
+)
+
+var orderFormatContentTypes = map[string]string{
+	usecase.OrderFormatCSV:   "text/csv",
+	usecase.OrderFormatJSONL: "application/x-ndjson",
+}
+
+type WebOrderBulkHandler struct {
+	OrderRepository entity.OrderRepositoryInterface
+	ImportBatchSize int
+}
+
+func NewWebOrderBulkHandler(
+	OrderRepository entity.OrderRepositoryInterface,
+	ImportBatchSize int,
+) *WebOrderBulkHandler {
+	return &WebOrderBulkHandler{
+		OrderRepository: OrderRepository,
+		ImportBatchSize: ImportBatchSize,
+	}
+}
+
+func (h *WebOrderBulkHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := orderFormat(r)
+	contentType, ok := orderFormatContentTypes[format]
+	if !ok {
+		http.Error(w, usecase.ErrUnsupportedOrderFormat.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="orders.`+format+`"`)
+
+	exportOrders := usecase.NewExportOrdersUseCase(h.OrderRepository)
+	if err := exportOrders.Execute(r.Context(), format, w); err != nil {
+		// The status line is already gone once the first order is written.
+		log.Printf("Failed to export orders: %v", err)
+	}
+}
+
+func (h *WebOrderBulkHandler) Import(w http.ResponseWriter, r *http.Request) {
+	importOrders := usecase.NewImportOrdersUseCase(h.OrderRepository, h.ImportBatchSize)
+	output, err := importOrders.Execute(r.Context(), orderFormat(r), r.Body)
+	if errors.Is(err, usecase.ErrUnsupportedOrderFormat) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(output)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func orderFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	return usecase.OrderFormatCSV
+}
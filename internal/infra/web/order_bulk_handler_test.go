@@ -0,0 +1,116 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+func TestWebOrderBulkHandler_Export(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 13, 0, 0, 0, time.UTC)
+	repository := &MockOrderRepository{Orders: []entity.Order{
+		{ID: "1", Price: 100, Tax: 10, FinalPrice: 110, CreatedAt: createdAt},
+	}}
+	handler := NewWebOrderBulkHandler(repository, 10)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "should export csv by default",
+			url:            "/orders/export",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv",
+			expectedBody:   "id,price,tax,final_price,created_at\n1,100,10,110,2023-01-01 10:00:00 -03:00\n",
+		},
+		{
+			name:           "should export jsonl",
+			url:            "/orders/export?format=jsonl",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/x-ndjson",
+			expectedBody:   `{"id":"1","price":100,"tax":10,"final_price":110,"created_at":"2023-01-01 10:00:00 -03:00"}` + "\n",
+		},
+		{
+			name:           "should reject unsupported format",
+			url:            "/orders/export?format=xml",
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   "unsupported format, use csv or jsonl\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.Export(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestWebOrderBulkHandler_Import(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedSaved  int
+	}{
+		{
+			name:           "should import csv and report invalid lines",
+			url:            "/orders/import",
+			body:           "id,price,tax\n1,10,1\n2,-1,1\n",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"imported":1,"failed":1,"errors":[{"line":3,"id":"2","error":"invalid price"}]}`,
+			expectedSaved:  1,
+		},
+		{
+			name:           "should import jsonl",
+			url:            "/orders/import?format=jsonl",
+			body:           `{"id":"1","price":10,"tax":1}` + "\n" + `{"id":"2","price":20,"tax":2}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"imported":2,"failed":0,"errors":[]}`,
+			expectedSaved:  2,
+		},
+		{
+			name:           "should reject unsupported format",
+			url:            "/orders/import?format=xml",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject csv without header columns",
+			url:            "/orders/import",
+			body:           "id\n1\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &MockOrderRepository{}
+			handler := NewWebOrderBulkHandler(repository, 10)
+
+			rr := httptest.NewRecorder()
+			handler.Import(rr, httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+			assert.Len(t, repository.Orders, tt.expectedSaved)
+		})
+	}
+}
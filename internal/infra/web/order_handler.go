@@ -2,6 +2,8 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/vs0uz4/clean_architecture/internal/dto"
@@ -13,13 +15,13 @@ import (
 type WebOrderHandler struct {
 	EventDispatcher   events.EventDispatcherInterface
 	OrderRepository   entity.OrderRepositoryInterface
-	OrderCreatedEvent events.EventInterface
+	OrderCreatedEvent events.EventFactory
 }
 
 func NewWebOrderHandler(
 	EventDispatcher events.EventDispatcherInterface,
 	OrderRepository entity.OrderRepositoryInterface,
-	OrderCreatedEvent events.EventInterface,
+	OrderCreatedEvent events.EventFactory,
 ) *WebOrderHandler {
 	return &WebOrderHandler{
 		EventDispatcher:   EventDispatcher,
@@ -37,7 +39,11 @@ func (h *WebOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	createOrder := usecase.NewCreateOrderUseCase(h.OrderRepository, h.OrderCreatedEvent, h.EventDispatcher)
-	output, err := createOrder.Execute(dto)
+	output, err := createOrder.Execute(r.Context(), dto)
+	if errors.Is(err, usecase.ErrOrderCreatedNotDispatched) {
+		log.Printf("Order %s: %v", output.ID, err)
+		err = nil
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -51,7 +57,7 @@ func (h *WebOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 func (h *WebOrderHandler) List(w http.ResponseWriter, r *http.Request) {
 	listOrder := usecase.NewListOrderUseCase(h.OrderRepository)
-	output, err := listOrder.Execute()
+	output, err := listOrder.Execute(r.Context())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -2,6 +2,7 @@ package web
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,7 +12,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/vs0uz4/clean_architecture/internal/dto"
 	"github.com/vs0uz4/clean_architecture/internal/entity"
-	"github.com/vs0uz4/clean_architecture/pkg/events"
+	"github.com/vs0uz4/clean_architecture/internal/event"
+	"github.com/vs0uz4/clean_architecture/pkg/events/eventstest"
 )
 
 type MockOrderRepository struct {
@@ -19,7 +21,7 @@ type MockOrderRepository struct {
 	Err    error
 }
 
-func (m *MockOrderRepository) Save(order *entity.Order) error {
+func (m *MockOrderRepository) Save(ctx context.Context, order *entity.Order) error {
 	if m.Err != nil {
 		return m.Err
 	}
@@ -33,48 +35,50 @@ func (m *MockOrderCreatedEvent) Raise(order *entity.Order) error {
 	return nil
 }
 
-type MockEvent struct{}
-
-func (m *MockEvent) GetName() string {
-	return "mock_event"
-}
-
-func (m *MockEvent) GetDateTime() time.Time {
-	return time.Now()
+func (m *MockOrderRepository) List(ctx context.Context) ([]entity.Order, error) {
+	return m.Orders, m.Err
 }
 
-func (m *MockEvent) GetPayload() interface{} {
-	return nil
+func (m *MockOrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	return m.Err
 }
 
-func (m *MockEvent) SetPayload(payload interface{}) {
+func (m *MockOrderRepository) Delete(ctx context.Context, id string) error {
+	return m.Err
 }
 
-type MockEventDispatcher struct{}
-
-func (m *MockEventDispatcher) Dispatch(event events.EventInterface) error {
-	return nil
+func (m *MockOrderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	for _, order := range m.Orders {
+		if order.ID == id {
+			return &order, nil
+		}
+	}
+	return nil, entity.ErrOrderNotFound
 }
 
-func (m *MockEventDispatcher) Register(eventName string, handler events.EventHandlerInterface) error {
+func (m *MockOrderRepository) Stream(ctx context.Context, fn func(order entity.Order) error) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	for _, order := range m.Orders {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (m *MockEventDispatcher) Remove(eventName string, handler events.EventHandlerInterface) error {
+func (m *MockOrderRepository) SaveBatch(ctx context.Context, orders []entity.Order) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Orders = append(m.Orders, orders...)
 	return nil
 }
 
-func (m *MockEventDispatcher) Has(eventName string, handler events.EventHandlerInterface) bool {
-	return false
-}
-
-func (m *MockEventDispatcher) Clear() {
-}
-
-func (m *MockOrderRepository) List() ([]entity.Order, error) {
-	return m.Orders, m.Err
-}
-
 func TestWebOrderHandler_Create(t *testing.T) {
 	fixedZone := time.FixedZone("UTC-3", -3*60*60)
 	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, fixedZone)
@@ -83,8 +87,10 @@ func TestWebOrderHandler_Create(t *testing.T) {
 		name           string
 		body           interface{}
 		repository     *MockOrderRepository
+		dispatchErr    error
 		expectedStatus int
 		expectedBody   string
+		expectedEvents int
 	}{
 		{
 			name: "should create order successfully",
@@ -107,6 +113,20 @@ func TestWebOrderHandler_Create(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"id":"1","price":100,"tax":10,"final_price":110,"created_at":"2023-01-01 00:00:00 -03:00"}`,
+			expectedEvents: 1,
+		},
+		{
+			name: "should create order even when event dispatch fails",
+			body: dto.OrderInputDTO{
+				ID:    "1",
+				Price: 100.0,
+				Tax:   10.0,
+			},
+			repository:     &MockOrderRepository{},
+			dispatchErr:    assert.AnError,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"1","price":100,"tax":10,"final_price":110,"created_at":"2023-01-01 00:00:00 -03:00"}`,
+			expectedEvents: 1,
 		},
 		{
 			name: "should return error when decoding body fails",
@@ -135,7 +155,9 @@ func TestWebOrderHandler_Create(t *testing.T) {
 
 	for _, tt := range create_tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := NewWebOrderHandler(&MockEventDispatcher{}, tt.repository, &MockEvent{})
+			eventDispatcher := eventstest.NewDispatcher()
+			eventDispatcher.Err = tt.dispatchErr
+			handler := NewWebOrderHandler(eventDispatcher, tt.repository, event.NewOrderCreatedFactory())
 
 			if handler.OrderRepository == nil {
 				t.Fatalf("OrderRepository is nil")
@@ -160,6 +182,7 @@ func TestWebOrderHandler_Create(t *testing.T) {
 			handler.Create(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			eventstest.AssertDispatchedCount(t, eventDispatcher, event.OrderCreatedName, tt.expectedEvents)
 
 			if tt.expectedBody != "" {
 				if tt.expectedStatus == http.StatusBadRequest {
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
+)
+
+type WebOrderHistoryHandler struct {
+	OrderRepository        entity.OrderRepositoryInterface
+	OrderHistoryRepository entity.OrderHistoryRepositoryInterface
+}
+
+func NewWebOrderHistoryHandler(
+	OrderRepository entity.OrderRepositoryInterface,
+	OrderHistoryRepository entity.OrderHistoryRepositoryInterface,
+) *WebOrderHistoryHandler {
+	return &WebOrderHistoryHandler{
+		OrderRepository:        OrderRepository,
+		OrderHistoryRepository: OrderHistoryRepository,
+	}
+}
+
+func (h *WebOrderHistoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	getOrderHistory := usecase.NewGetOrderHistoryUseCase(h.OrderRepository, h.OrderHistoryRepository)
+	output, err := getOrderHistory.Execute(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, entity.ErrOrderNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(dto.OrderHistoryListOutputDTO{History: output})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+type MockOrderHistoryRepository struct {
+	History []entity.OrderHistory
+	Err     error
+}
+
+func (m *MockOrderHistoryRepository) ListByOrderID(ctx context.Context, orderID string) ([]entity.OrderHistory, error) {
+	return m.History, m.Err
+}
+
+func TestWebOrderHistoryHandler_List(t *testing.T) {
+	occurredAt := time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	history_tests := []struct {
+		name              string
+		orderID           string
+		repository        *MockOrderRepository
+		historyRepository *MockOrderHistoryRepository
+		expectedStatus    int
+		expectedBody      string
+	}{
+		{
+			name:       "should return order history successfully",
+			orderID:    "1",
+			repository: &MockOrderRepository{},
+			historyRepository: &MockOrderHistoryRepository{
+				History: []entity.OrderHistory{
+					{
+						ID:         1,
+						OrderID:    "1",
+						Actor:      "alice",
+						Operation:  entity.OrderOperationCreate,
+						OccurredAt: occurredAt,
+						After:      &entity.Order{ID: "1", Price: 100, Tax: 10, FinalPrice: 110, CreatedAt: occurredAt},
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"history":[{"id":1,"order_id":"1","actor":"alice","operation":"create","occurred_at":"2023-01-01 00:00:00 -03:00","before":null,"after":{"id":"1","price":100,"tax":10,"final_price":110,"created_at":"2023-01-01 00:00:00 -03:00"}}]}`,
+		},
+		{
+			name:              "should return not found for an unknown order",
+			orderID:           "2",
+			repository:        &MockOrderRepository{},
+			historyRepository: &MockOrderHistoryRepository{},
+			expectedStatus:    http.StatusNotFound,
+		},
+		{
+			name:              "should return error when repository fails",
+			orderID:           "1",
+			repository:        &MockOrderRepository{},
+			historyRepository: &MockOrderHistoryRepository{Err: assert.AnError},
+			expectedStatus:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range history_tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewWebOrderHistoryHandler(tt.repository, tt.historyRepository)
+
+			router := chi.NewRouter()
+			router.Get("/order/{id}/history", handler.List)
+
+			req := httptest.NewRequest(http.MethodGet, "/order/"+tt.orderID+"/history", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestActorMiddleware(t *testing.T) {
+	var actor string
+	handler := ActorMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = entity.ActorFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/order", nil)
+	req.Header.Set(ActorHeader, "alice")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "alice", actor)
+
+	req = httptest.NewRequest(http.MethodGet, "/order", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, entity.AnonymousActor, actor)
+}
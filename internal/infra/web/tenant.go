@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/infra/auth"
+)
+
+const (
+	TenantHeader        = "X-Tenant-ID"
+	AuthorizationHeader = "Authorization"
+)
+
+// TenantClaimMiddleware sets the tenant from the tenant_id claim of the
+// bearer token, taking precedence over the X-Tenant-ID header read by
+// TenantMiddleware after it. Invalid tokens are rejected. Requests without a
+// token, or whose token has no tenant claim, are left to the header, so any
+// caller can still pick its tenant, unless the verifier requires the claim,
+// which rejects them too.
+func TenantClaimMiddleware(verifier *auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := auth.BearerToken(r.Header.Get(AuthorizationHeader))
+			if token == "" && verifier.RequireTenant {
+				http.Error(w, auth.ErrTenantClaimRequired.Error(), http.StatusUnauthorized)
+				return
+			}
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tenant, err := verifier.Tenant(token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			ctx := r.Context()
+			if tenant != "" {
+				ctx = entity.ContextWithTenant(ctx, tenant)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// TenantMiddleware resolves the tenant from the X-Tenant-ID header, unless
+// TenantClaimMiddleware, earlier in the chain, already set it from a claim.
+func TenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if _, ok := entity.LookupTenant(ctx); !ok {
+			ctx = entity.ContextWithTenant(ctx, r.Header.Get(TenantHeader))
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
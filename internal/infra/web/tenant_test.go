@@ -0,0 +1,122 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/infra/auth"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	var tenant string
+	handler := TenantMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = entity.TenantFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/order", nil)
+	req.Header.Set(TenantHeader, "store-a")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "store-a", tenant)
+
+	req = httptest.NewRequest(http.MethodGet, "/order", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, entity.DefaultTenant, tenant)
+}
+
+func TestTenantMiddleware_KeepsTenantFromClaim(t *testing.T) {
+	var tenant string
+	handler := TenantMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = entity.TenantFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/order", nil)
+	req = req.WithContext(entity.ContextWithTenant(req.Context(), "store-a"))
+	req.Header.Set(TenantHeader, "store-b")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "store-a", tenant)
+}
+
+func signedToken(secret, claims string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestTenantClaimMiddleware(t *testing.T) {
+	var tenant string
+	handler := TenantClaimMiddleware(auth.NewTokenVerifier("secret"))(TenantMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = entity.TenantFromContext(r.Context())
+	})))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		header         string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{name: "claim over header", authorization: "Bearer " + signedToken("secret", `{"tenant_id":"store-a"}`), header: "store-b", expectedStatus: http.StatusOK, expectedTenant: "store-a"},
+		{name: "token without claim", authorization: "Bearer " + signedToken("secret", `{"sub":"user-1"}`), header: "store-b", expectedStatus: http.StatusOK, expectedTenant: "store-b"},
+		{name: "no token", header: "store-b", expectedStatus: http.StatusOK, expectedTenant: "store-b"},
+		{name: "forged token", authorization: "Bearer " + signedToken("other", `{"tenant_id":"store-a"}`), header: "store-b", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant = ""
+			req := httptest.NewRequest(http.MethodGet, "/order", nil)
+			req.Header.Set(TenantHeader, tt.header)
+			if tt.authorization != "" {
+				req.Header.Set(AuthorizationHeader, tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedTenant, tenant)
+		})
+	}
+}
+
+func TestTenantClaimMiddleware_RequireTenant(t *testing.T) {
+	verifier := auth.NewTokenVerifier("secret")
+	verifier.RequireTenant = true
+	var tenant string
+	handler := TenantClaimMiddleware(verifier)(TenantMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = entity.TenantFromContext(r.Context())
+	})))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{name: "claim", authorization: "Bearer " + signedToken("secret", `{"tenant_id":"store-a"}`), expectedStatus: http.StatusOK, expectedTenant: "store-a"},
+		{name: "token without claim", authorization: "Bearer " + signedToken("secret", `{"sub":"user-1"}`), expectedStatus: http.StatusUnauthorized},
+		{name: "no token", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant = ""
+			req := httptest.NewRequest(http.MethodGet, "/order", nil)
+			req.Header.Set(TenantHeader, "store-b")
+			if tt.authorization != "" {
+				req.Header.Set(AuthorizationHeader, tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedTenant, tenant)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package webserver
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -10,10 +11,12 @@ import (
 type WebServer struct {
 	WebServerPort string
 	Router        *chi.Mux
+	Middlewares   []func(http.Handler) http.Handler
 	Handlers      map[string]struct {
 		Handler http.HandlerFunc
 		Method  string
 	}
+	server *http.Server
 }
 
 func NewWebServer(serverPort string) *WebServer {
@@ -24,6 +27,7 @@ func NewWebServer(serverPort string) *WebServer {
 			Handler http.HandlerFunc
 			Method  string
 		}),
+		server: &http.Server{Addr: serverPort},
 	}
 }
 
@@ -35,8 +39,15 @@ func (s *WebServer) AddHandler(path string, handler http.HandlerFunc, method str
 	}{Handler: handler, Method: method}
 }
 
-func (s *WebServer) Start() {
+func (s *WebServer) AddMiddleware(middleware func(http.Handler) http.Handler) {
+	s.Middlewares = append(s.Middlewares, middleware)
+}
+
+// Start serves the registered handlers until Shutdown, when it returns
+// http.ErrServerClosed.
+func (s *WebServer) Start() error {
 	s.Router.Use(middleware.Logger)
+	s.Router.Use(s.Middlewares...)
 	for key, entry := range s.Handlers {
 		switch entry.Method {
 		case "GET":
@@ -53,5 +64,12 @@ func (s *WebServer) Start() {
 			s.Router.Method(entry.Method, key[:len(key)-len("_"+entry.Method)], entry.Handler)
 		}
 	}
-	http.ListenAndServe(s.WebServerPort, s.Router)
+	s.server.Handler = s.Router
+	return s.server.ListenAndServe()
+}
+
+// Shutdown stops taking new requests and waits for the ones in progress until
+// ctx is done.
+func (s *WebServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
 }
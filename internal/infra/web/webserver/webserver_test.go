@@ -1,6 +1,7 @@
 package webserver
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -60,3 +61,18 @@ func TestStart(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
+
+func TestShutdown(t *testing.T) {
+	webServer := NewWebServer("127.0.0.1:0")
+	started := make(chan error, 1)
+	go func() { started <- webServer.Start() }()
+	time.Sleep(100 * time.Millisecond)
+
+	assert.NoError(t, webServer.Shutdown(context.Background()))
+	select {
+	case err := <-started:
+		assert.ErrorIs(t, err, http.ErrServerClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Shutdown")
+	}
+}
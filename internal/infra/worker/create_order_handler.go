@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/infra/database"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// CreateOrderCommand is the type of the messages asking for an order to be
+// created, with an OrderInputDTO as body.
+const CreateOrderCommand = "CreateOrder"
+
+type CreateOrderHandler struct {
+	CreateOrderUseCase *usecase.CreateOrderUseCase
+	OrderRepository    entity.OrderRepositoryInterface
+}
+
+func NewCreateOrderHandler(CreateOrderUseCase *usecase.CreateOrderUseCase, OrderRepository entity.OrderRepositoryInterface) *CreateOrderHandler {
+	return &CreateOrderHandler{
+		CreateOrderUseCase: CreateOrderUseCase,
+		OrderRepository:    OrderRepository,
+	}
+}
+
+// Handle creates the order. Invalid orders, and other orders already using
+// the ID, fail permanently, as retrying cannot fix them. An order saved whose
+// OrderCreated handlers failed is done, the dispatcher retries and
+// dead-letters those on its own.
+func (h *CreateOrderHandler) Handle(ctx context.Context, command *events.Event[dto.OrderInputDTO]) error {
+	input := command.Payload()
+	if _, err := entity.NewOrder(input.ID, input.Price, input.Tax); err != nil {
+		return events.Permanent(err)
+	}
+
+	output, err := h.CreateOrderUseCase.Execute(ctx, input)
+	switch {
+	case errors.Is(err, usecase.ErrOrderCreatedNotDispatched):
+		log.Printf("Order %s: %v", output.ID, err)
+		return nil
+	case errors.Is(err, entity.ErrOrderAlreadyExists):
+		return h.alreadyCreated(ctx, input)
+	}
+	return err
+}
+
+// alreadyCreated tells a command delivered again, whose order was saved
+// before the worker could ack it, from a different order reusing the ID.
+func (h *CreateOrderHandler) alreadyCreated(ctx context.Context, input dto.OrderInputDTO) error {
+	existing, err := h.OrderRepository.FindByID(database.WithPrimaryRead(ctx), input.ID)
+	if err != nil {
+		return err
+	}
+	if existing.Price != input.Price || existing.Tax != input.Tax {
+		return events.Permanent(fmt.Errorf("%w with a different price or tax", entity.ErrOrderAlreadyExists))
+	}
+	log.Printf("Order %s already created, acknowledging the command", input.ID)
+	return nil
+}
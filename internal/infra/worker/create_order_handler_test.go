@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/event"
+	"github.com/vs0uz4/clean_architecture/internal/usecase"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+	"github.com/vs0uz4/clean_architecture/pkg/events/eventstest"
+)
+
+type orderRepository struct {
+	entity.OrderRepositoryInterface
+	existing []entity.Order
+	saved    []entity.Order
+	err      error
+}
+
+func (r *orderRepository) Save(ctx context.Context, order *entity.Order) error {
+	if r.err != nil {
+		return r.err
+	}
+	for _, existing := range r.existing {
+		if existing.ID == order.ID {
+			return entity.ErrOrderAlreadyExists
+		}
+	}
+	r.saved = append(r.saved, *order)
+	return nil
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	for _, existing := range r.existing {
+		if existing.ID == id {
+			return &existing, nil
+		}
+	}
+	return nil, entity.ErrOrderNotFound
+}
+
+func TestCreateOrderHandler_Handle(t *testing.T) {
+	databaseDown := errors.New("database unavailable")
+
+	tests := []struct {
+		name          string
+		input         dto.OrderInputDTO
+		existing      []entity.Order
+		repositoryErr error
+		dispatchErr   error
+		expectedSaved int
+		expectedErr   error
+		permanent     bool
+	}{
+		{
+			name:          "creates the order",
+			input:         dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2},
+			expectedSaved: 1,
+		},
+		{
+			name:        "rejects an invalid order",
+			input:       dto.OrderInputDTO{ID: "123", Price: 0, Tax: 2},
+			expectedErr: errors.New("invalid price"),
+			permanent:   true,
+		},
+		{
+			name:        "rejects another order with the same ID",
+			input:       dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2},
+			existing:    []entity.Order{{ID: "123", Price: 20, Tax: 2}},
+			expectedErr: entity.ErrOrderAlreadyExists,
+			permanent:   true,
+		},
+		{
+			name:     "acks a command delivered again after its order was created",
+			input:    dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2},
+			existing: []entity.Order{{ID: "123", Price: 10, Tax: 2}},
+		},
+		{
+			name:          "retries when the order cannot be saved",
+			input:         dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2},
+			repositoryErr: databaseDown,
+			expectedErr:   databaseDown,
+		},
+		{
+			name:          "succeeds when only the OrderCreated dispatch fails",
+			input:         dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2},
+			dispatchErr:   errors.New("broker unavailable"),
+			expectedSaved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &orderRepository{existing: tt.existing, err: tt.repositoryErr}
+			dispatcher := eventstest.NewDispatcher()
+			dispatcher.Err = tt.dispatchErr
+			createOrderHandler := NewCreateOrderHandler(usecase.NewCreateOrderUseCase(repository, event.NewOrderCreatedFactory(), dispatcher), repository)
+
+			err := createOrderHandler.Handle(context.Background(), events.NewEvent(CreateOrderCommand, tt.input))
+
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.permanent, events.IsPermanent(err))
+			assert.Len(t, repository.saved, tt.expectedSaved)
+		})
+	}
+}
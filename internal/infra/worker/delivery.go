@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/event/handler"
+	"github.com/vs0uz4/clean_architecture/internal/infra/messaging"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// Actor is recorded in the audit trail for the changes made by the worker.
+const Actor = "worker"
+
+// NewDeliveryHandler decodes the JSON body of each message through registry,
+// upcasting it from the schema version of the message, and hands it to
+// eventHandler as an event named after the message type, or name when it has
+// none. The envelope is read from the message properties and headers the
+// application publishes with, so the message ID works with the inbox and the
+// events created while handling it keep the tenant and correlation. Bodies
+// that cannot be decoded are rejected for good.
+func NewDeliveryHandler(name string, registry *events.SchemaRegistry, eventHandler events.EventHandlerInterface) messaging.DeliveryHandler {
+	return func(ctx context.Context, delivery amqp.Delivery) error {
+		eventName := delivery.Type
+		if eventName == "" {
+			eventName = name
+		}
+		metadata := deliveryMetadata(delivery)
+		event, err := registry.Decode(eventName, metadata, delivery.Body)
+		if err != nil {
+			return events.Permanent(fmt.Errorf("decoding message %s: %w", delivery.MessageId, err))
+		}
+
+		ctx = entity.ContextWithTenant(ctx, headerString(delivery.Headers, handler.TenantIDHeader))
+		ctx = entity.ContextWithActor(ctx, Actor)
+		ctx = events.ContextWithCorrelationID(ctx, metadata.CorrelationID)
+		ctx = events.ContextWithCausationID(ctx, metadata.ID)
+		return eventHandler.Handle(ctx, event)
+	}
+}
+
+func deliveryMetadata(delivery amqp.Delivery) events.Metadata {
+	metadata := events.Metadata{
+		ID:            delivery.MessageId,
+		OccurredAt:    delivery.Timestamp.UTC(),
+		CorrelationID: delivery.CorrelationId,
+		Version:       headerInt(delivery.Headers, handler.SchemaVersionHeader),
+		Source:        delivery.AppId,
+	}
+	if metadata.OccurredAt.IsZero() {
+		metadata.OccurredAt = time.Now().UTC()
+	}
+	if metadata.CorrelationID == "" {
+		metadata.CorrelationID = metadata.ID
+	}
+	if causationID := headerString(delivery.Headers, handler.CausationIDHeader); causationID != "" {
+		metadata.CausationID = causationID
+	}
+	return metadata
+}
+
+func headerString(headers amqp.Table, key string) string {
+	value, _ := headers[key].(string)
+	return value
+}
+
+// headerInt reads an integer header, whatever the width the publisher used.
+// Messages without it are taken as the first schema version by the registry.
+func headerInt(headers amqp.Table, key string) int {
+	switch value := headers[key].(type) {
+	case int8:
+		return int(value)
+	case int16:
+		return int(value)
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
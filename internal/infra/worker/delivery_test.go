@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/event/handler"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+func TestNewDeliveryHandler(t *testing.T) {
+	var handledCtx context.Context
+	var handled events.EventInterface
+	deliveryHandler := NewDeliveryHandler(CreateOrderCommand, NewSchemaRegistry(), events.HandlerFunc(func(ctx context.Context, event events.EventInterface) error {
+		handledCtx, handled = ctx, event
+		return nil
+	}))
+	sentAt := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	err := deliveryHandler(context.Background(), amqp.Delivery{
+		MessageId:     "command-1",
+		CorrelationId: "request-1",
+		Timestamp:     sentAt,
+		Headers:       amqp.Table{handler.TenantIDHeader: "store-1", handler.SchemaVersionHeader: int32(CreateOrderVersion)},
+		Body:          []byte(`{"id":"123","price":10,"tax":2}`),
+	})
+
+	require.NoError(t, err)
+	require.IsType(t, &events.Event[dto.OrderInputDTO]{}, handled)
+	assert.Equal(t, CreateOrderCommand, handled.GetName())
+	assert.Equal(t, dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2}, handled.GetPayload())
+	metadata := events.MetadataOf(handled)
+	assert.Equal(t, "command-1", metadata.ID)
+	assert.Equal(t, "request-1", metadata.CorrelationID)
+	assert.Equal(t, sentAt, metadata.OccurredAt)
+	assert.Equal(t, CreateOrderVersion, metadata.Version)
+	assert.Equal(t, "store-1", entity.TenantFromContext(handledCtx))
+	assert.Equal(t, Actor, entity.ActorFromContext(handledCtx))
+	assert.Equal(t, "request-1", events.CorrelationIDFromContext(handledCtx))
+	assert.Equal(t, "command-1", events.CausationIDFromContext(handledCtx))
+}
+
+func TestNewDeliveryHandler_InvalidBody(t *testing.T) {
+	deliveryHandler := NewDeliveryHandler(CreateOrderCommand, NewSchemaRegistry(), events.HandlerFunc(func(ctx context.Context, event events.EventInterface) error {
+		t.Fatal("handler called with an invalid body")
+		return nil
+	}))
+
+	err := deliveryHandler(context.Background(), amqp.Delivery{MessageId: "command-1", Body: []byte("not json")})
+
+	assert.True(t, events.IsPermanent(err))
+}
+
+func TestNewDeliveryHandler_UpcastsOlderVersions(t *testing.T) {
+	registry := events.NewSchemaRegistry()
+	require.NoError(t, events.RegisterSchema[dto.OrderInputDTO](registry, CreateOrderCommand, 2))
+	require.NoError(t, registry.RegisterUpcaster(CreateOrderCommand, 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		return []byte(strings.Replace(string(payload), `"order_id"`, `"id"`, 1)), nil
+	}))
+	var handled events.EventInterface
+	deliveryHandler := NewDeliveryHandler(CreateOrderCommand, registry, events.HandlerFunc(func(ctx context.Context, event events.EventInterface) error {
+		handled = event
+		return nil
+	}))
+
+	err := deliveryHandler(context.Background(), amqp.Delivery{
+		MessageId: "command-1",
+		Headers:   amqp.Table{handler.SchemaVersionHeader: int32(1)},
+		Body:      []byte(`{"order_id":"123","price":10,"tax":2}`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, dto.OrderInputDTO{ID: "123", Price: 10, Tax: 2}, handled.GetPayload())
+	assert.Equal(t, 2, events.MetadataOf(handled).Version)
+}
+
+func TestNewDeliveryHandler_UnknownVersion(t *testing.T) {
+	deliveryHandler := NewDeliveryHandler(CreateOrderCommand, NewSchemaRegistry(), events.HandlerFunc(func(ctx context.Context, event events.EventInterface) error {
+		t.Fatal("handler called with an unknown schema version")
+		return nil
+	}))
+
+	err := deliveryHandler(context.Background(), amqp.Delivery{
+		MessageId: "command-1",
+		Headers:   amqp.Table{handler.SchemaVersionHeader: int32(CreateOrderVersion + 1)},
+		Body:      []byte(`{"id":"123","price":10,"tax":2}`),
+	})
+
+	assert.True(t, events.IsPermanent(err))
+	assert.ErrorIs(t, err, events.ErrSchemaTooNew)
+}
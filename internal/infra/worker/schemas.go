@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// CreateOrderVersion is the current schema version of CreateOrderCommand.
+const CreateOrderVersion = 1
+
+// NewSchemaRegistry registers the current schema of every command the worker
+// consumes. When a body changes shape, bump its version constant and register
+// an upcaster from the previous version here, so the commands already queued
+// still decode.
+func NewSchemaRegistry() *events.SchemaRegistry {
+	registry := events.NewSchemaRegistry()
+	if err := events.RegisterSchema[dto.OrderInputDTO](registry, CreateOrderCommand, CreateOrderVersion); err != nil {
+		panic(err)
+	}
+	return registry
+}
@@ -1,20 +1,29 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/vs0uz4/clean_architecture/internal/dto"
 	"github.com/vs0uz4/clean_architecture/internal/entity"
 	"github.com/vs0uz4/clean_architecture/pkg/events"
 )
 
+// ErrOrderCreatedNotDispatched is returned along with the created order when
+// it was saved but some OrderCreated handler failed. Callers decide whether
+// that is fatal, only logged or retried.
+var ErrOrderCreatedNotDispatched = errors.New("order created but event dispatch failed")
+
 type CreateOrderUseCase struct {
 	OrderRepository entity.OrderRepositoryInterface
-	OrderCreated    events.EventInterface
+	OrderCreated    events.EventFactory
 	EventDispatcher events.EventDispatcherInterface
 }
 
 func NewCreateOrderUseCase(
 	OrderRepository entity.OrderRepositoryInterface,
-	OrderCreated events.EventInterface,
+	OrderCreated events.EventFactory,
 	EventDispatcher events.EventDispatcherInterface,
 ) *CreateOrderUseCase {
 	return &CreateOrderUseCase{
@@ -24,7 +33,7 @@ func NewCreateOrderUseCase(
 	}
 }
 
-func (c *CreateOrderUseCase) Execute(input dto.OrderInputDTO) (dto.OrderOutputDTO, error) {
+func (c *CreateOrderUseCase) Execute(ctx context.Context, input dto.OrderInputDTO) (dto.OrderOutputDTO, error) {
 	order := entity.Order{
 		ID:    input.ID,
 		Price: input.Price,
@@ -33,7 +42,7 @@ func (c *CreateOrderUseCase) Execute(input dto.OrderInputDTO) (dto.OrderOutputDT
 
 	order.SetCreatedAt()
 	order.CalculateFinalPrice()
-	if err := c.OrderRepository.Save(&order); err != nil {
+	if err := c.OrderRepository.Save(ctx, &order); err != nil {
 		return dto.OrderOutputDTO{}, err
 	}
 
@@ -45,8 +54,9 @@ func (c *CreateOrderUseCase) Execute(input dto.OrderInputDTO) (dto.OrderOutputDT
 		CreatedAt:  order.CreatedAt.Format("2006-01-02 15:04:05 -07:00"),
 	}
 
-	c.OrderCreated.SetPayload(dto)
-	c.EventDispatcher.Dispatch(c.OrderCreated)
+	if err := c.EventDispatcher.Dispatch(ctx, c.OrderCreated(ctx, dto)); err != nil {
+		return dto, fmt.Errorf("%w: %w", ErrOrderCreatedNotDispatched, err)
+	}
 
 	return dto, nil
 }
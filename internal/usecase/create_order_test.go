@@ -1,74 +1,23 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+	"github.com/vs0uz4/clean_architecture/internal/event"
 	"github.com/vs0uz4/clean_architecture/pkg/events"
+	"github.com/vs0uz4/clean_architecture/pkg/events/eventstest"
 )
 
-type EventMock struct {
-	mock.Mock
-}
-
-func (m *EventMock) Clear() {
-	m.Called()
-}
-
-func (m *EventMock) GetName() string {
-	args := m.Called()
-	return args.String(0)
-}
-
-func (m *EventMock) GetDateTime() time.Time {
-	args := m.Called()
-	return args.Get(0).(time.Time)
-}
-
-func (m *EventMock) GetPayload() interface{} {
-	args := m.Called()
-	return args.Get(0)
-}
-
-func (m *EventMock) SetPayload(payload interface{}) {
-	m.Called(payload)
-}
-
-type EventDispatcherMock struct {
-	mock.Mock
-}
-
-func (m *EventDispatcherMock) Register(eventName string, handler events.EventHandlerInterface) error {
-	args := m.Called(eventName, handler)
-	return args.Error(0)
-}
-
-func (m *EventDispatcherMock) Dispatch(event events.EventInterface) error {
-	args := m.Called(event)
-	return args.Error(0)
-}
-
-func (m *EventDispatcherMock) Remove(eventName string, handler events.EventHandlerInterface) error {
-	args := m.Called(eventName, handler)
-	return args.Error(0)
-}
-
-func (m *EventDispatcherMock) Has(eventName string, handler events.EventHandlerInterface) bool {
-	args := m.Called(eventName, handler)
-	return args.Bool(0)
-}
-
-func (m *EventDispatcherMock) Clear() {
-	m.Called()
-}
-
 func TestCreateOrderUseCase_Execute(t *testing.T) {
 	var mockOrderRepository *OrderRepositoryMock
-	var mockEvent *EventMock
-	var mockEventDispatcher *EventDispatcherMock
+	var eventDispatcher *eventstest.Dispatcher
 
 	input := dto.OrderInputDTO{
 		ID:    "123",
@@ -78,29 +27,21 @@ func TestCreateOrderUseCase_Execute(t *testing.T) {
 
 	setup := func() {
 		mockOrderRepository = &OrderRepositoryMock{}
-		mockEvent = &EventMock{}
-		mockEventDispatcher = &EventDispatcherMock{}
+		eventDispatcher = eventstest.NewDispatcher()
 	}
 
 	t.Run("should create order successfully", func(t *testing.T) {
 		setup()
 
-		var output dto.OrderOutputDTO
-		defer func() {
-			output = dto.OrderOutputDTO{}
-		}()
-
 		mockOrderRepository.On("Save", mock.Anything).Return(nil)
-		mockEvent.On("SetPayload", mock.Anything).Return()
-		mockEventDispatcher.On("Dispatch", mockEvent).Return(nil)
 
 		useCase := NewCreateOrderUseCase(
 			mockOrderRepository,
-			mockEvent,
-			mockEventDispatcher,
+			event.NewOrderCreatedFactory(),
+			eventDispatcher,
 		)
 
-		output, err := useCase.Execute(input)
+		output, err := useCase.Execute(context.Background(), input)
 
 		assert.Nil(t, err)
 		assert.Equal(t, input.ID, output.ID)
@@ -108,10 +49,32 @@ func TestCreateOrderUseCase_Execute(t *testing.T) {
 		assert.Equal(t, input.Tax, output.Tax)
 		assert.Equal(t, input.Price+input.Tax, output.FinalPrice)
 		assert.NotEmpty(t, output.CreatedAt)
+		eventstest.AssertDispatchedCount(t, eventDispatcher, event.OrderCreatedName, 1)
+		eventstest.AssertDispatchedWithPayload(t, eventDispatcher, event.OrderCreatedName, output)
+
+		mockOrderRepository.AssertExpectations(t)
+	})
+
+	t.Run("should return the order and a dispatch error when a handler fails", func(t *testing.T) {
+		setup()
+
+		mockOrderRepository.On("Save", mock.Anything).Return(nil)
+		eventDispatcher.Err = assert.AnError
+
+		useCase := NewCreateOrderUseCase(
+			mockOrderRepository,
+			event.NewOrderCreatedFactory(),
+			eventDispatcher,
+		)
+
+		output, err := useCase.Execute(context.Background(), input)
+
+		assert.ErrorIs(t, err, ErrOrderCreatedNotDispatched)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, input.ID, output.ID)
+		eventstest.AssertDispatchedWithPayload(t, eventDispatcher, event.OrderCreatedName, output)
 
 		mockOrderRepository.AssertExpectations(t)
-		mockEvent.AssertExpectations(t)
-		mockEventDispatcher.AssertExpectations(t)
 	})
 
 	t.Run("should return error when repository fails", func(t *testing.T) {
@@ -121,15 +84,55 @@ func TestCreateOrderUseCase_Execute(t *testing.T) {
 
 		useCase := NewCreateOrderUseCase(
 			mockOrderRepository,
-			mockEvent,
-			mockEventDispatcher,
+			event.NewOrderCreatedFactory(),
+			eventDispatcher,
 		)
 
-		output, err := useCase.Execute(input)
+		output, err := useCase.Execute(context.Background(), input)
 
 		assert.Equal(t, assert.AnError, err)
 		assert.Empty(t, output)
+		eventstest.AssertNotDispatched(t, eventDispatcher, event.OrderCreatedName)
 
 		mockOrderRepository.AssertExpectations(t)
 	})
 }
+
+// lockedOrderRepository only implements Save, guarded for concurrent calls.
+type lockedOrderRepository struct {
+	entity.OrderRepositoryInterface
+	mu     sync.Mutex
+	orders []entity.Order
+}
+
+func (r *lockedOrderRepository) Save(ctx context.Context, order *entity.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders = append(r.orders, *order)
+	return nil
+}
+
+func TestCreateOrderUseCase_Execute_Concurrently(t *testing.T) {
+	dispatcher := events.NewEventDispatcher()
+	handler := eventstest.NewHandler()
+	dispatcher.Register(event.OrderCreatedName, handler)
+
+	orderRepository := &lockedOrderRepository{}
+	useCase := NewCreateOrderUseCase(orderRepository, event.NewOrderCreatedFactory(), dispatcher)
+
+	var wg sync.WaitGroup
+	outputs := make([]dto.OrderOutputDTO, 50)
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			output, err := useCase.Execute(context.Background(), dto.OrderInputDTO{ID: fmt.Sprintf("order-%d", i), Price: float64(i), Tax: 1})
+
+			assert.NoError(t, err)
+			outputs[i] = output
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, orderRepository.orders, 50)
+	assert.ElementsMatch(t, outputs, eventstest.Payloads[dto.OrderOutputDTO](handler, event.OrderCreatedName))
+}
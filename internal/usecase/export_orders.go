@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+type ExportOrdersUseCase struct {
+	OrderRepository entity.OrderRepositoryInterface
+}
+
+func NewExportOrdersUseCase(
+	OrderRepository entity.OrderRepositoryInterface,
+) *ExportOrdersUseCase {
+	return &ExportOrdersUseCase{
+		OrderRepository: OrderRepository,
+	}
+}
+
+// Execute streams the orders to w as they are read from the repository, so
+// the whole table is never held in memory. An unsupported format is reported
+// before anything is written.
+func (c *ExportOrdersUseCase) Execute(ctx context.Context, format string, w io.Writer) error {
+	writer, err := newOrderWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	err = c.OrderRepository.Stream(ctx, func(order entity.Order) error {
+		return writer.Write(*toOrderOutputDTO(&order))
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+func exportRepository() *OrderRepositoryMock {
+	repository := &OrderRepositoryMock{
+		orders: []entity.Order{
+			{ID: "1", Price: 100.5, Tax: 10, FinalPrice: 110.5, CreatedAt: time.Date(2023, 1, 1, 13, 0, 0, 0, time.UTC)},
+			{ID: "2", Price: 20, Tax: 2, FinalPrice: 22, CreatedAt: time.Date(2023, 1, 2, 13, 0, 0, 0, time.UTC)},
+		},
+	}
+	repository.On("Stream").Return(nil)
+	return repository
+}
+
+func TestExportOrdersUseCase_Execute(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		repository := exportRepository()
+		var out bytes.Buffer
+
+		err := NewExportOrdersUseCase(repository).Execute(context.Background(), OrderFormatCSV, &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Join([]string{
+			"id,price,tax,final_price,created_at",
+			"1,100.5,10,110.5,2023-01-01 10:00:00 -03:00",
+			"2,20,2,22,2023-01-02 10:00:00 -03:00",
+		}, "\n")+"\n", out.String())
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		repository := exportRepository()
+		var out bytes.Buffer
+
+		err := NewExportOrdersUseCase(repository).Execute(context.Background(), OrderFormatJSONL, &out)
+
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.JSONEq(t, `{"id":"1","price":100.5,"tax":10,"final_price":110.5,"created_at":"2023-01-01 10:00:00 -03:00"}`, lines[0])
+	})
+
+	t.Run("empty csv still has a header", func(t *testing.T) {
+		repository := &OrderRepositoryMock{}
+		repository.On("Stream").Return(nil)
+		var out bytes.Buffer
+
+		err := NewExportOrdersUseCase(repository).Execute(context.Background(), OrderFormatCSV, &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "id,price,tax,final_price,created_at\n", out.String())
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		repository := &OrderRepositoryMock{}
+		var out bytes.Buffer
+
+		err := NewExportOrdersUseCase(repository).Execute(context.Background(), "xml", &out)
+
+		assert.ErrorIs(t, err, ErrUnsupportedOrderFormat)
+		assert.Empty(t, out.String())
+		repository.AssertNotCalled(t, "Stream")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository := &OrderRepositoryMock{err: assert.AnError}
+		repository.On("Stream").Return(nil)
+
+		err := NewExportOrdersUseCase(repository).Execute(context.Background(), OrderFormatJSONL, &bytes.Buffer{})
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+type GetOrderHistoryUseCase struct {
+	OrderRepository        entity.OrderRepositoryInterface
+	OrderHistoryRepository entity.OrderHistoryRepositoryInterface
+}
+
+func NewGetOrderHistoryUseCase(
+	OrderRepository entity.OrderRepositoryInterface,
+	OrderHistoryRepository entity.OrderHistoryRepositoryInterface,
+) *GetOrderHistoryUseCase {
+	return &GetOrderHistoryUseCase{
+		OrderRepository:        OrderRepository,
+		OrderHistoryRepository: OrderHistoryRepository,
+	}
+}
+
+func (c *GetOrderHistoryUseCase) Execute(ctx context.Context, orderID string) ([]dto.OrderHistoryOutputDTO, error) {
+	history, err := c.OrderHistoryRepository.ListByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(history) == 0 {
+		if _, err := c.OrderRepository.FindByID(ctx, orderID); err != nil {
+			return nil, err
+		}
+	}
+
+	var output = []dto.OrderHistoryOutputDTO{}
+	for _, entry := range history {
+		output = append(output, dto.OrderHistoryOutputDTO{
+			ID:         entry.ID,
+			OrderID:    entry.OrderID,
+			Actor:      entry.Actor,
+			Operation:  entry.Operation,
+			OccurredAt: convertToTimezone(entry.OccurredAt).Format("2006-01-02 15:04:05 -07:00"),
+			Before:     toOrderOutputDTO(entry.Before),
+			After:      toOrderOutputDTO(entry.After),
+		})
+	}
+
+	return output, nil
+}
+
+func toOrderOutputDTO(order *entity.Order) *dto.OrderOutputDTO {
+	if order == nil {
+		return nil
+	}
+	return &dto.OrderOutputDTO{
+		ID:         order.ID,
+		Price:      order.Price,
+		Tax:        order.Tax,
+		FinalPrice: order.FinalPrice,
+		CreatedAt:  convertToTimezone(order.CreatedAt).Format("2006-01-02 15:04:05 -07:00"),
+	}
+}
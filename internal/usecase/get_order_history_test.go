@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+func TestGetOrderHistoryUseCase_Execute(t *testing.T) {
+	occurredAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should return the order history", func(t *testing.T) {
+		orderRepository := &OrderRepositoryMock{}
+		historyRepository := &OrderHistoryRepositoryMock{}
+		historyRepository.On("ListByOrderID", "1").Return([]entity.OrderHistory{
+			{
+				ID:         1,
+				OrderID:    "1",
+				Actor:      "alice",
+				Operation:  entity.OrderOperationCreate,
+				OccurredAt: occurredAt,
+				After:      &entity.Order{ID: "1", Price: 100, Tax: 10, FinalPrice: 110, CreatedAt: occurredAt},
+			},
+			{
+				ID:         2,
+				OrderID:    "1",
+				Actor:      "bob",
+				Operation:  entity.OrderOperationDelete,
+				OccurredAt: occurredAt.Add(time.Hour),
+				Before:     &entity.Order{ID: "1", Price: 100, Tax: 10, FinalPrice: 110, CreatedAt: occurredAt},
+			},
+		}, nil)
+
+		useCase := NewGetOrderHistoryUseCase(orderRepository, historyRepository)
+		output, err := useCase.Execute(context.Background(), "1")
+
+		assert.Nil(t, err)
+		assert.Len(t, output, 2)
+		assert.Equal(t, "alice", output[0].Actor)
+		assert.Equal(t, entity.OrderOperationCreate, output[0].Operation)
+		assert.Equal(t, "2023-01-01 07:00:00 -03:00", output[0].OccurredAt)
+		assert.Nil(t, output[0].Before)
+		assert.Equal(t, 110.0, output[0].After.FinalPrice)
+		assert.Equal(t, "bob", output[1].Actor)
+		assert.Nil(t, output[1].After)
+
+		historyRepository.AssertExpectations(t)
+		orderRepository.AssertNotCalled(t, "FindByID", "1")
+	})
+
+	t.Run("should return an empty history for an order without changes", func(t *testing.T) {
+		orderRepository := &OrderRepositoryMock{orders: []entity.Order{{ID: "1"}}}
+		orderRepository.On("FindByID", "1").Return(nil, nil)
+		historyRepository := &OrderHistoryRepositoryMock{}
+		historyRepository.On("ListByOrderID", "1").Return(nil, nil)
+
+		useCase := NewGetOrderHistoryUseCase(orderRepository, historyRepository)
+		output, err := useCase.Execute(context.Background(), "1")
+
+		assert.Nil(t, err)
+		assert.NotNil(t, output)
+		assert.Empty(t, output)
+	})
+
+	t.Run("should return not found for an unknown order", func(t *testing.T) {
+		orderRepository := &OrderRepositoryMock{}
+		orderRepository.On("FindByID", "2").Return(nil, nil)
+		historyRepository := &OrderHistoryRepositoryMock{}
+		historyRepository.On("ListByOrderID", "2").Return(nil, nil)
+
+		useCase := NewGetOrderHistoryUseCase(orderRepository, historyRepository)
+		output, err := useCase.Execute(context.Background(), "2")
+
+		assert.ErrorIs(t, err, entity.ErrOrderNotFound)
+		assert.Nil(t, output)
+	})
+
+	t.Run("should return error when repository fails", func(t *testing.T) {
+		historyRepository := &OrderHistoryRepositoryMock{}
+		historyRepository.On("ListByOrderID", "1").Return(nil, assert.AnError)
+
+		useCase := NewGetOrderHistoryUseCase(&OrderRepositoryMock{}, historyRepository)
+		output, err := useCase.Execute(context.Background(), "1")
+
+		assert.Equal(t, assert.AnError, err)
+		assert.Nil(t, output)
+	})
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+const DefaultImportBatchSize = 500
+
+type ImportOrdersUseCase struct {
+	OrderRepository entity.OrderRepositoryInterface
+	BatchSize       int
+}
+
+func NewImportOrdersUseCase(
+	OrderRepository entity.OrderRepositoryInterface,
+	BatchSize int,
+) *ImportOrdersUseCase {
+	if BatchSize <= 0 {
+		BatchSize = DefaultImportBatchSize
+	}
+	return &ImportOrdersUseCase{
+		OrderRepository: OrderRepository,
+		BatchSize:       BatchSize,
+	}
+}
+
+type pendingOrder struct {
+	line  int
+	order entity.Order
+}
+
+// Execute reads the records one at a time, validates them and saves the
+// valid ones in batches. Invalid records end up in the returned report with
+// their line number; the import only fails as a whole when the input itself
+// cannot be read.
+func (c *ImportOrdersUseCase) Execute(ctx context.Context, format string, r io.Reader) (dto.OrderImportOutputDTO, error) {
+	output := dto.OrderImportOutputDTO{Errors: []dto.OrderImportErrorDTO{}}
+
+	reader, err := newOrderReader(format, r)
+	if err != nil {
+		return output, err
+	}
+
+	batch := make([]pendingOrder, 0, c.BatchSize)
+	for {
+		line, input, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recordErr *orderRecordError
+		if errors.As(err, &recordErr) {
+			addImportError(&output, line, input.ID, recordErr.err)
+			continue
+		}
+		if err != nil {
+			return output, err
+		}
+
+		order := entity.Order{ID: input.ID, Price: input.Price, Tax: input.Tax}
+		if err := order.IsValid(); err != nil {
+			addImportError(&output, line, input.ID, err)
+			continue
+		}
+		order.SetCreatedAt()
+		order.CalculateFinalPrice()
+
+		batch = append(batch, pendingOrder{line: line, order: order})
+		if len(batch) == c.BatchSize {
+			if err := c.saveBatch(ctx, batch, &output); err != nil {
+				return output, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := c.saveBatch(ctx, batch, &output); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// saveBatch writes the batch in a single call and, when it is rejected,
+// retries its orders one by one to pinpoint the offending lines.
+func (c *ImportOrdersUseCase) saveBatch(ctx context.Context, batch []pendingOrder, output *dto.OrderImportOutputDTO) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	orders := make([]entity.Order, len(batch))
+	for i, pending := range batch {
+		orders[i] = pending.order
+	}
+	if err := c.OrderRepository.SaveBatch(ctx, orders); err == nil {
+		output.Imported += len(batch)
+		return nil
+	}
+
+	for _, pending := range batch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := c.OrderRepository.Save(ctx, &pending.order); err != nil {
+			addImportError(output, pending.line, pending.order.ID, err)
+			continue
+		}
+		output.Imported++
+	}
+	return nil
+}
+
+func addImportError(output *dto.OrderImportOutputDTO, line int, id string, err error) {
+	output.Failed++
+	output.Errors = append(output.Errors, dto.OrderImportErrorDTO{Line: line, ID: id, Error: err.Error()})
+}
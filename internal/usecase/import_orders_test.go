@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+	"github.com/vs0uz4/clean_architecture/internal/entity"
+)
+
+func TestNewImportOrdersUseCase(t *testing.T) {
+	repository := &OrderRepositoryMock{}
+	useCase := NewImportOrdersUseCase(repository, 0)
+	assert.Equal(t, repository, useCase.OrderRepository)
+	assert.Equal(t, DefaultImportBatchSize, useCase.BatchSize)
+}
+
+func TestImportOrdersUseCase_Execute(t *testing.T) {
+	t.Run("jsonl with invalid lines", func(t *testing.T) {
+		repository := &OrderRepositoryMock{}
+		repository.On("SaveBatch", mock.Anything).Return(nil)
+		input := strings.Join([]string{
+			`{"id":"1","price":10,"tax":1}`,
+			`{"id":"2","price":0,"tax":1}`,
+			``,
+			`not json`,
+			`{"id":"3","price":30,"tax":3}`,
+			`{"id":"4","price":40,"tax":4}`,
+		}, "\n")
+
+		output, err := NewImportOrdersUseCase(repository, 2).Execute(context.Background(), OrderFormatJSONL, strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, output.Imported)
+		assert.Equal(t, 2, output.Failed)
+		assert.Equal(t, []dto.OrderImportErrorDTO{
+			{Line: 2, ID: "2", Error: "invalid price"},
+			{Line: 4, Error: output.Errors[1].Error},
+		}, output.Errors)
+		repository.AssertNumberOfCalls(t, "SaveBatch", 2)
+		assert.Len(t, repository.orders, 3)
+		assert.Equal(t, 11.0, repository.orders[0].FinalPrice)
+		assert.False(t, repository.orders[0].CreatedAt.IsZero())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		repository := &OrderRepositoryMock{}
+		repository.On("SaveBatch", mock.Anything).Return(nil)
+		input := "ID,Price,Tax\n1,10,1\n2,abc,1\n3,30,\n4,40,4\n"
+
+		output, err := NewImportOrdersUseCase(repository, 10).Execute(context.Background(), OrderFormatCSV, strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, output.Imported)
+		assert.Equal(t, []dto.OrderImportErrorDTO{
+			{Line: 3, ID: "2", Error: "invalid price"},
+			{Line: 4, ID: "3", Error: "invalid tax"},
+		}, output.Errors)
+		repository.AssertNumberOfCalls(t, "SaveBatch", 1)
+	})
+
+	t.Run("csv without required column", func(t *testing.T) {
+		repository := &OrderRepositoryMock{}
+
+		_, err := NewImportOrdersUseCase(repository, 10).Execute(context.Background(), OrderFormatCSV, strings.NewReader("id,price\n1,10\n"))
+
+		assert.ErrorContains(t, err, `"tax"`)
+		repository.AssertNotCalled(t, "SaveBatch", mock.Anything)
+	})
+
+	t.Run("rejected batch is retried one by one", func(t *testing.T) {
+		repository := &OrderRepositoryMock{}
+		repository.On("SaveBatch", mock.Anything).Return(errors.New("duplicate entry"))
+		repository.On("Save", mock.MatchedBy(func(order *entity.Order) bool { return order.ID == "2" })).Return(entity.ErrOrderAlreadyExists)
+		repository.On("Save", mock.Anything).Return(nil)
+		input := "{\"id\":\"1\",\"price\":10,\"tax\":1}\n{\"id\":\"2\",\"price\":20,\"tax\":2}\n{\"id\":\"3\",\"price\":30,\"tax\":3}\n"
+
+		output, err := NewImportOrdersUseCase(repository, 3).Execute(context.Background(), OrderFormatJSONL, strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, output.Imported)
+		assert.Equal(t, []dto.OrderImportErrorDTO{
+			{Line: 2, ID: "2", Error: entity.ErrOrderAlreadyExists.Error()},
+		}, output.Errors)
+		repository.AssertNumberOfCalls(t, "Save", 3)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := NewImportOrdersUseCase(&OrderRepositoryMock{}, 10).Execute(context.Background(), "xml", strings.NewReader(""))
+		assert.ErrorIs(t, err, ErrUnsupportedOrderFormat)
+	})
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"time"
 
 	"github.com/vs0uz4/clean_architecture/internal/dto"
@@ -19,10 +20,10 @@ func NewListOrderUseCase(
 	}
 }
 
-func (c *ListOrderUseCase) Execute() ([]dto.OrderOutputDTO, error) {
+func (c *ListOrderUseCase) Execute(ctx context.Context) ([]dto.OrderOutputDTO, error) {
 	var orders = []dto.OrderOutputDTO{}
 
-	ordersEntity, err := c.OrderRepository.List()
+	ordersEntity, err := c.OrderRepository.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -31,7 +32,7 @@ func TestListOrderUseCase_Execute(t *testing.T) {
 		repository.On("List").Return(repository.orders, nil)
 
 		useCase := NewListOrderUseCase(repository)
-		output, err := useCase.Execute()
+		output, err := useCase.Execute(context.Background())
 
 		assert.Nil(t, err)
 		assert.Len(t, output, 1)
@@ -51,7 +52,7 @@ func TestListOrderUseCase_Execute(t *testing.T) {
 		repository.On("List").Return(repository.orders, nil)
 
 		useCase := NewListOrderUseCase(repository)
-		output, err := useCase.Execute()
+		output, err := useCase.Execute(context.Background())
 
 		assert.Nil(t, output)
 		assert.Error(t, err)
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 	"github.com/vs0uz4/clean_architecture/internal/entity"
 )
@@ -15,7 +17,7 @@ func (r *OrderRepositoryMock) Clear() {
 	r.Called()
 }
 
-func (r *OrderRepositoryMock) List() ([]entity.Order, error) {
+func (r *OrderRepositoryMock) List(ctx context.Context) ([]entity.Order, error) {
 	args := r.Called()
 	if r.err != nil {
 		return nil, r.err
@@ -23,7 +25,20 @@ func (r *OrderRepositoryMock) List() ([]entity.Order, error) {
 	return r.orders, args.Error(1)
 }
 
-func (r *OrderRepositoryMock) Save(order *entity.Order) error {
+func (r *OrderRepositoryMock) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	args := r.Called(id)
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, order := range r.orders {
+		if order.ID == id {
+			return &order, args.Error(1)
+		}
+	}
+	return nil, entity.ErrOrderNotFound
+}
+
+func (r *OrderRepositoryMock) Save(ctx context.Context, order *entity.Order) error {
 	args := r.Called(order)
 	if r.err != nil {
 		return r.err
@@ -31,3 +46,45 @@ func (r *OrderRepositoryMock) Save(order *entity.Order) error {
 	r.orders = append(r.orders, *order)
 	return args.Error(0)
 }
+
+func (r *OrderRepositoryMock) Update(ctx context.Context, order *entity.Order) error {
+	args := r.Called(order)
+	return args.Error(0)
+}
+
+func (r *OrderRepositoryMock) Delete(ctx context.Context, id string) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *OrderRepositoryMock) Stream(ctx context.Context, fn func(order entity.Order) error) error {
+	args := r.Called()
+	if r.err != nil {
+		return r.err
+	}
+	for _, order := range r.orders {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return args.Error(0)
+}
+
+func (r *OrderRepositoryMock) SaveBatch(ctx context.Context, orders []entity.Order) error {
+	args := r.Called(orders)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	r.orders = append(r.orders, orders...)
+	return nil
+}
+
+type OrderHistoryRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *OrderHistoryRepositoryMock) ListByOrderID(ctx context.Context, orderID string) ([]entity.OrderHistory, error) {
+	args := r.Called(orderID)
+	history, _ := args.Get(0).([]entity.OrderHistory)
+	return history, args.Error(1)
+}
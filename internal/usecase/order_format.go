@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vs0uz4/clean_architecture/internal/dto"
+)
+
+const (
+	OrderFormatCSV   = "csv"
+	OrderFormatJSONL = "jsonl"
+
+	maxJSONLLineSize = 1024 * 1024
+)
+
+var ErrUnsupportedOrderFormat = errors.New("unsupported format, use csv or jsonl")
+
+var orderCSVHeader = []string{"id", "price", "tax", "final_price", "created_at"}
+
+type orderWriter interface {
+	Write(order dto.OrderOutputDTO) error
+	Flush() error
+}
+
+func newOrderWriter(format string, w io.Writer) (orderWriter, error) {
+	switch format {
+	case OrderFormatCSV:
+		return &csvOrderWriter{w: csv.NewWriter(w)}, nil
+	case OrderFormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlOrderWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	default:
+		return nil, ErrUnsupportedOrderFormat
+	}
+}
+
+type csvOrderWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvOrderWriter) Write(order dto.OrderOutputDTO) error {
+	if !c.headerWritten {
+		if err := c.w.Write(orderCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	return c.w.Write([]string{
+		order.ID,
+		strconv.FormatFloat(order.Price, 'f', -1, 64),
+		strconv.FormatFloat(order.Tax, 'f', -1, 64),
+		strconv.FormatFloat(order.FinalPrice, 'f', -1, 64),
+		order.CreatedAt,
+	})
+}
+
+func (c *csvOrderWriter) Flush() error {
+	if !c.headerWritten {
+		if err := c.w.Write(orderCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlOrderWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (j *jsonlOrderWriter) Write(order dto.OrderOutputDTO) error {
+	return j.encoder.Encode(order)
+}
+
+func (j *jsonlOrderWriter) Flush() error {
+	return j.buffered.Flush()
+}
+
+// orderRecordError is a problem with a single input record; the import
+// reports it and moves on to the next record.
+type orderRecordError struct {
+	line int
+	err  error
+}
+
+func (e *orderRecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+type orderReader interface {
+	// Read returns the next record and its line number, io.EOF at the end of
+	// the input, an *orderRecordError for a malformed record or any other
+	// error when the input can no longer be read.
+	Read() (int, dto.OrderInputDTO, error)
+}
+
+func newOrderReader(format string, r io.Reader) (orderReader, error) {
+	switch format {
+	case OrderFormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+		return &csvOrderReader{r: reader}, nil
+	case OrderFormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+		return &jsonlOrderReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedOrderFormat
+	}
+}
+
+type csvOrderReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func (c *csvOrderReader) Read() (int, dto.OrderInputDTO, error) {
+	if c.columns == nil {
+		if err := c.readHeader(); err != nil {
+			return 0, dto.OrderInputDTO{}, err
+		}
+	}
+
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, dto.OrderInputDTO{}, &orderRecordError{line: parseErr.StartLine, err: parseErr.Err}
+		}
+		return 0, dto.OrderInputDTO{}, err
+	}
+	line, _ := c.r.FieldPos(0)
+
+	input := dto.OrderInputDTO{ID: c.field(record, "id")}
+	if input.Price, err = c.float(record, "price"); err != nil {
+		return line, input, &orderRecordError{line: line, err: err}
+	}
+	if input.Tax, err = c.float(record, "tax"); err != nil {
+		return line, input, &orderRecordError{line: line, err: err}
+	}
+	return line, input, nil
+}
+
+func (c *csvOrderReader) readHeader() error {
+	header, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("reading csv header: %w", err)
+	}
+	c.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		c.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"id", "price", "tax"} {
+		if _, ok := c.columns[required]; !ok {
+			return fmt.Errorf("csv header is missing the %q column", required)
+		}
+	}
+	return nil
+}
+
+func (c *csvOrderReader) field(record []string, column string) string {
+	index := c.columns[column]
+	if index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func (c *csvOrderReader) float(record []string, column string) (float64, error) {
+	value, err := strconv.ParseFloat(c.field(record, column), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", column)
+	}
+	return value, nil
+}
+
+type jsonlOrderReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (j *jsonlOrderReader) Read() (int, dto.OrderInputDTO, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := j.scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		var input dto.OrderInputDTO
+		if err := json.Unmarshal(data, &input); err != nil {
+			return j.line, input, &orderRecordError{line: j.line, err: err}
+		}
+		return j.line, input, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return j.line + 1, dto.OrderInputDTO{}, err
+	}
+	return 0, dto.OrderInputDTO{}, io.EOF
+}
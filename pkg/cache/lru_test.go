@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_GetAndSet(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Set("a", 1)
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	c.Set("a", 2)
+	value, _ = c.Get("a")
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+}
+
+func TestLRU_ExpiresEntriesAfterTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU[string, int](2, time.Second)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(2 * time.Second)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_DeleteAndPurge(t *testing.T) {
+	c := NewLRU[string, int](3, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	c.Delete("a")
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_Stats(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, 2, stats.Capacity)
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrDispatchQueueFull = errors.New("dispatch queue is full")
+	ErrDispatcherClosed  = errors.New("dispatcher is closed")
+)
+
+// Backpressure decides what an async Dispatch does when the queue is full.
+type Backpressure string
+
+const (
+	// BackpressureBlock waits for room in the queue, or for ctx to be done.
+	BackpressureBlock Backpressure = "block"
+	// BackpressureDrop discards the event and reports success.
+	BackpressureDrop Backpressure = "drop"
+	// BackpressureError discards the event and returns ErrDispatchQueueFull.
+	BackpressureError Backpressure = "error"
+)
+
+func ParseBackpressure(value string) (Backpressure, error) {
+	switch backpressure := Backpressure(value); backpressure {
+	case BackpressureBlock, BackpressureDrop, BackpressureError:
+		return backpressure, nil
+	}
+	return "", fmt.Errorf("unknown backpressure %q", value)
+}
+
+type AsyncConfig struct {
+	Workers      int
+	QueueSize    int
+	Backpressure Backpressure
+	// OnError receives the handler failures, which no longer reach the
+	// caller of Dispatch. Defaults to logging them.
+	OnError func(event EventInterface, err error)
+}
+
+type asyncJob struct {
+	ctx   context.Context
+	event EventInterface
+}
+
+type asyncQueue struct {
+	mu           sync.RWMutex
+	closed       bool
+	jobs         chan asyncJob
+	wg           sync.WaitGroup
+	backpressure Backpressure
+	onError      func(event EventInterface, err error)
+	dropped      atomic.Uint64
+}
+
+func newAsyncQueue(config AsyncConfig, dispatch func(ctx context.Context, event EventInterface) error) *asyncQueue {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.Backpressure == "" {
+		config.Backpressure = BackpressureBlock
+	}
+	if config.OnError == nil {
+		config.OnError = func(event EventInterface, err error) {
+			log.Printf("Async dispatch of %s failed: %v", event.GetName(), err)
+		}
+	}
+
+	q := &asyncQueue{
+		jobs:         make(chan asyncJob, config.QueueSize),
+		backpressure: config.Backpressure,
+		onError:      config.OnError,
+	}
+	q.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go func() {
+			defer q.wg.Done()
+			for job := range q.jobs {
+				if err := dispatch(job.ctx, job.event); err != nil {
+					q.onError(job.event, err)
+				}
+			}
+		}()
+	}
+	return q
+}
+
+// enqueue detaches the event from the caller's cancellation, since the
+// handlers run after the request that dispatched it has finished, but keeps
+// its values (tenant, actor).
+func (q *asyncQueue) enqueue(ctx context.Context, event EventInterface) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrDispatcherClosed
+	}
+	job := asyncJob{ctx: context.WithoutCancel(ctx), event: event}
+
+	if q.backpressure == BackpressureBlock {
+		select {
+		case q.jobs <- job:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+	}
+	if q.backpressure == BackpressureDrop {
+		q.dropped.Add(1)
+		return nil
+	}
+	return ErrDispatchQueueFull
+}
+
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
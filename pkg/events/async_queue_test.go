@@ -0,0 +1,194 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingHandler struct {
+	release chan struct{}
+	started chan struct{}
+	handled atomic.Int32
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{release: make(chan struct{}), started: make(chan struct{}, 100)}
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, event EventInterface) error {
+	h.started <- struct{}{}
+	<-h.release
+	h.handled.Add(1)
+	return nil
+}
+
+type countingHandler struct {
+	handled atomic.Int32
+	err     error
+}
+
+func (h *countingHandler) Handle(ctx context.Context, event EventInterface) error {
+	h.handled.Add(1)
+	return h.err
+}
+
+// fillQueue occupies the single worker and the queue slot of a dispatcher
+// built with Workers: 1 and QueueSize: 1.
+func fillQueue(t *testing.T, ed *EventDispatcher, handler *blockingHandler) {
+	event := &TestEvent{Name: "test"}
+	require.NoError(t, ed.Dispatch(context.Background(), event))
+	<-handler.started
+	require.NoError(t, ed.Dispatch(context.Background(), event))
+}
+
+func TestAsyncDispatcher_RunsHandlersInBackground(t *testing.T) {
+	handler := newBlockingHandler()
+	ed := NewAsyncEventDispatcher(AsyncConfig{Workers: 2, QueueSize: 10})
+	require.NoError(t, ed.Register("test", handler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, ed.Dispatch(ctx, &TestEvent{Name: "test"}))
+	cancel()
+	<-handler.started
+	assert.Equal(t, int32(0), handler.handled.Load())
+
+	close(handler.release)
+	ed.Close()
+	assert.Equal(t, int32(1), handler.handled.Load())
+}
+
+func TestAsyncDispatcher_Backpressure(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		handler := newBlockingHandler()
+		ed := NewAsyncEventDispatcher(AsyncConfig{Workers: 1, QueueSize: 1, Backpressure: BackpressureError})
+		require.NoError(t, ed.Register("test", handler))
+		fillQueue(t, ed, handler)
+
+		err := ed.Dispatch(context.Background(), &TestEvent{Name: "test"})
+		assert.ErrorIs(t, err, ErrDispatchQueueFull)
+
+		close(handler.release)
+		ed.Close()
+		assert.Equal(t, int32(2), handler.handled.Load())
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		handler := newBlockingHandler()
+		ed := NewAsyncEventDispatcher(AsyncConfig{Workers: 1, QueueSize: 1, Backpressure: BackpressureDrop})
+		require.NoError(t, ed.Register("test", handler))
+		fillQueue(t, ed, handler)
+
+		assert.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+		assert.Equal(t, uint64(1), ed.DroppedEvents())
+
+		close(handler.release)
+		ed.Close()
+		assert.Equal(t, int32(2), handler.handled.Load())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		handler := newBlockingHandler()
+		ed := NewAsyncEventDispatcher(AsyncConfig{Workers: 1, QueueSize: 1, Backpressure: BackpressureBlock})
+		require.NoError(t, ed.Register("test", handler))
+		fillQueue(t, ed, handler)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, ed.Dispatch(ctx, &TestEvent{Name: "test"}), context.DeadlineExceeded)
+
+		done := make(chan error)
+		go func() {
+			done <- ed.Dispatch(context.Background(), &TestEvent{Name: "test"})
+		}()
+		close(handler.release)
+		assert.NoError(t, <-done)
+
+		ed.Close()
+		assert.Equal(t, int32(3), handler.handled.Load())
+	})
+}
+
+func TestAsyncDispatcher_ReportsHandlerErrors(t *testing.T) {
+	errPublish := errors.New("publish failed")
+	var mu sync.Mutex
+	var reported []error
+	ed := NewAsyncEventDispatcher(AsyncConfig{
+		Workers:   1,
+		QueueSize: 1,
+		OnError: func(event EventInterface, err error) {
+			mu.Lock()
+			reported = append(reported, err)
+			mu.Unlock()
+		},
+	})
+	require.NoError(t, ed.Register("test", &countingHandler{err: errPublish}))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+	ed.Close()
+
+	require.Len(t, reported, 1)
+	assert.ErrorIs(t, reported[0], errPublish)
+}
+
+func TestAsyncDispatcher_RejectsEventsAfterClose(t *testing.T) {
+	ed := NewAsyncEventDispatcher(AsyncConfig{Workers: 1, QueueSize: 1})
+	ed.Close()
+	ed.Close()
+
+	assert.ErrorIs(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}), ErrDispatcherClosed)
+}
+
+func TestParseBackpressure(t *testing.T) {
+	backpressure, err := ParseBackpressure("drop")
+	assert.NoError(t, err)
+	assert.Equal(t, BackpressureDrop, backpressure)
+
+	_, err = ParseBackpressure("retry")
+	assert.Error(t, err)
+}
+
+// Run with -race: registering and removing handlers while events are being
+// dispatched, synchronously and through the worker pool.
+func TestEventDispatcher_ConcurrentUse(t *testing.T) {
+	for name, ed := range map[string]*EventDispatcher{
+		"sync":  NewEventDispatcher(),
+		"async": NewAsyncEventDispatcher(AsyncConfig{Workers: 4, QueueSize: 16}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			stable := &countingHandler{}
+			require.NoError(t, ed.Register("test", stable))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						assert.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						handler := &countingHandler{}
+						ed.Register("test", handler)
+						ed.Has("test", handler)
+						ed.Remove("test", handler)
+					}
+				}()
+			}
+			wg.Wait()
+			ed.Close()
+
+			assert.Equal(t, int32(400), stable.handled.Load())
+			assert.True(t, ed.Has("test", stable))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeadLetter is a handler run that failed for good: it exhausted its retry
+// policy or returned a permanent error.
+type DeadLetter struct {
+	EventName string
+	Handler   string
+	Metadata  Metadata
+	Payload   interface{}
+	Attempts  int
+	Err       string
+	FailedAt  time.Time
+}
+
+// DeadLetterSink keeps the dead letters so they can be inspected and
+// redelivered later.
+type DeadLetterSink interface {
+	Store(ctx context.Context, deadLetter DeadLetter) error
+}
+
+// EventDecoder rebuilds an event from the metadata and payload stored in a
+// dead letter.
+type EventDecoder func(metadata Metadata, payload []byte) (EventInterface, error)
+
+// HandlerName identifies a handler in dead letters and redeliveries. Adapters
+// report the name of the handler they wrap.
+func HandlerName(handler EventHandlerInterface) string {
+	if named, ok := handler.(interface{ HandlerName() string }); ok {
+		return named.HandlerName()
+	}
+	return fmt.Sprintf("%T", handler)
+}
@@ -1,69 +1,429 @@
 package events
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 )
 
-var ErrHandlerAlreadyRegistered = errors.New("handler already registered")
+var (
+	ErrHandlerAlreadyRegistered = errors.New("handler already registered")
+	ErrHandlerNotRegistered     = errors.New("handler not registered")
+	ErrHandlerNotComparable     = errors.New("handler must be comparable, such as a pointer")
+)
+
+// HandlerError identifies the handler behind a failure returned by Dispatch,
+// so callers can tell which handlers failed and decide what to do about it.
+type HandlerError struct {
+	EventName string
+	Handler   EventHandlerInterface
+	Err       error
+	Attempts  int
+	// DeadLettered reports whether the failure was stored in the dead-letter
+	// sink.
+	DeadLettered bool
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler %T failed for event %s after %d attempt(s): %v", e.Handler, e.EventName, e.Attempts, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
 
 type EventDispatcher struct {
-	handlers map[string][]EventHandlerInterface
+	mu          sync.RWMutex
+	handlers    map[string][]EventHandlerInterface
+	options     map[EventHandlerInterface]handlerOptions
+	sequential  map[string]bool
+	deadLetters DeadLetterSink
+	eventStore  EventStore
+	onNotStored func(event EventInterface, err error)
+	middlewares []HandlerMiddleware
+	async       *asyncQueue
 }
 
 func NewEventDispatcher() *EventDispatcher {
 	return &EventDispatcher{
-		handlers: make(map[string][]EventHandlerInterface),
+		handlers:   make(map[string][]EventHandlerInterface),
+		options:    make(map[EventHandlerInterface]handlerOptions),
+		sequential: make(map[string]bool),
+	}
+}
+
+// NewAsyncEventDispatcher returns a dispatcher whose Dispatch only enqueues
+// the event, leaving a pool of workers to run the handlers. Close must be
+// called to drain the queue and stop the workers.
+func NewAsyncEventDispatcher(config AsyncConfig) *EventDispatcher {
+	ed := NewEventDispatcher()
+	ed.async = newAsyncQueue(config, ed.dispatch)
+	return ed
+}
+
+// Dispatch runs every handler subscribed to the event, by its name or by a
+// matching pattern, concurrently and waits for them. The failures are joined
+// into the returned error, each one wrapped in a *HandlerError. Events set
+// with RunSequentially run their handlers one at a time instead. In async
+// mode it only reports whether the event could be queued; handler failures
+// go to AsyncConfig.OnError. Failures to append the event to the event log
+// are not returned, they go to the callback given to SetEventStore.
+func (ev *EventDispatcher) Dispatch(ctx context.Context, event EventInterface) error {
+	ev.store(ctx, event)
+	if ev.async != nil {
+		return ev.async.enqueue(ctx, event)
+	}
+	return ev.dispatch(ctx, event)
+}
+
+// store appends the event to the event log, if there is one. A failure is
+// reported but does not keep the handlers from running.
+func (ev *EventDispatcher) store(ctx context.Context, event EventInterface) {
+	ev.mu.RLock()
+	eventStore, onNotStored := ev.eventStore, ev.onNotStored
+	ev.mu.RUnlock()
+
+	if eventStore == nil {
+		return
+	}
+	if err := eventStore.Append(context.WithoutCancel(ctx), event); err != nil {
+		onNotStored(event, fmt.Errorf("%w: %w", ErrEventNotStored, err))
+	}
+}
+
+func (ev *EventDispatcher) dispatch(ctx context.Context, event EventInterface) error {
+	handlers, sequential := ev.subscribers(event.GetName())
+	return ev.run(ctx, event, handlers, sequential, true)
+}
+
+// subscribers returns the handlers for the event: the ones registered under
+// its exact name first, then the ones of each matching pattern, without
+// repeating a handler subscribed more than once. For sequential events they
+// are sorted by priority.
+func (ev *EventDispatcher) subscribers(eventName string) ([]EventHandlerInterface, bool) {
+	ev.mu.RLock()
+	defer ev.mu.RUnlock()
+
+	handlers := ev.handlers[eventName]
+	var patterns []string
+	for subscription := range ev.handlers {
+		if isPattern(subscription) && subscriptionMatches(subscription, eventName) {
+			patterns = append(patterns, subscription)
+		}
+	}
+	if len(patterns) > 0 {
+		sort.Strings(patterns)
+		handlers = slices.Clone(handlers)
+		for _, pattern := range patterns {
+			for _, handler := range ev.handlers[pattern] {
+				if !slices.Contains(handlers, handler) {
+					handlers = append(handlers, handler)
+				}
+			}
+		}
+	}
+
+	sequential := false
+	for subscription := range ev.sequential {
+		if subscriptionMatches(subscription, eventName) {
+			sequential = true
+			break
+		}
+	}
+	if sequential {
+		handlers = slices.Clone(handlers)
+		sort.SliceStable(handlers, func(i, j int) bool {
+			return ev.options[handlers[i]].priority > ev.options[handlers[j]].priority
+		})
+	}
+	return handlers, sequential
+}
+
+// Redeliver runs the event again on the handlers registered for it under the
+// given name only, so the handlers that already succeeded are not repeated.
+// Failures are returned but not dead-lettered a second time.
+func (ev *EventDispatcher) Redeliver(ctx context.Context, handlerName string, event EventInterface) error {
+	subscribers, sequential := ev.subscribers(event.GetName())
+	var handlers []EventHandlerInterface
+	for _, handler := range subscribers {
+		if HandlerName(handler) == handlerName {
+			handlers = append(handlers, handler)
+		}
+	}
+
+	if len(handlers) == 0 {
+		return fmt.Errorf("%w: %s for event %s", ErrHandlerNotRegistered, handlerName, event.GetName())
+	}
+	return ev.run(ctx, event, handlers, sequential, false)
+}
+
+// Replay runs a logged event again on the handlers subscribed to it, or only
+// on the ones named in handlerNames when given. Like Redeliver, it neither
+// logs the event again nor dead-letters the failures.
+func (ev *EventDispatcher) Replay(ctx context.Context, event EventInterface, handlerNames ...string) error {
+	subscribers, sequential := ev.subscribers(event.GetName())
+	handlers := subscribers
+	if len(handlerNames) > 0 {
+		handlers = nil
+		for _, handler := range subscribers {
+			if slices.ContainsFunc(handlerNames, func(name string) bool { return handlerNamed(handler, name) }) {
+				handlers = append(handlers, handler)
+			}
+		}
 	}
+	return ev.run(ctx, event, handlers, sequential, false)
 }
 
-func (ev *EventDispatcher) Dispatch(event EventInterface) error {
-	if handlers, ok := ev.handlers[event.GetName()]; ok {
-		wg := &sync.WaitGroup{}
+// HasHandlerNamed reports whether a handler with the given full or bare type
+// name is registered for any event.
+func (ev *EventDispatcher) HasHandlerNamed(name string) bool {
+	ev.mu.RLock()
+	defer ev.mu.RUnlock()
+
+	for _, handlers := range ev.handlers {
+		for _, handler := range handlers {
+			if handlerNamed(handler, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (ev *EventDispatcher) run(ctx context.Context, event EventInterface, handlers []EventHandlerInterface, sequential, deadLetter bool) error {
+	if sequential {
 		for _, handler := range handlers {
-			wg.Add(1)
-			go handler.Handle(event, wg)
+			if err := ev.handle(ctx, event, handler, deadLetter); err != nil {
+				return err
+			}
 		}
-		wg.Wait()
+		return nil
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	wg := &sync.WaitGroup{}
+	for _, handler := range handlers {
+		wg.Add(1)
+		go func(handler EventHandlerInterface) {
+			defer wg.Done()
+			if err := ev.handle(ctx, event, handler, deadLetter); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(handler)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// handle runs the handler under its retry policy and, once it has failed for
+// good, hands the event to the dead-letter sink. The events the handler
+// creates are correlated with the one it handles, which becomes their cause.
+func (ev *EventDispatcher) handle(ctx context.Context, event EventInterface, handler EventHandlerInterface, deadLetter bool) error {
+	ev.mu.RLock()
+	policy := ev.options[handler].retry
+	sink := ev.deadLetters
+	wrapped := chainMiddlewares(handler, ev.middlewares)
+	ev.mu.RUnlock()
+
+	metadata := MetadataOf(event)
+	ctx = context.WithValue(ctx, handlerNameKey{}, HandlerName(handler))
+	if metadata.ID != "" {
+		ctx = ContextWithCausationID(ContextWithCorrelationID(ctx, metadata.CorrelationID), metadata.ID)
+	}
+	attempts, err := policy.run(ctx, func() error {
+		return wrapped.Handle(ctx, event)
+	})
+	if err == nil {
+		return nil
+	}
+
+	handlerErr := &HandlerError{EventName: event.GetName(), Handler: handler, Err: err, Attempts: attempts}
+	if !deadLetter || sink == nil {
+		return handlerErr
+	}
+	storeErr := sink.Store(context.WithoutCancel(ctx), DeadLetter{
+		EventName: event.GetName(),
+		Handler:   HandlerName(handler),
+		Metadata:  metadata,
+		Payload:   event.GetPayload(),
+		Attempts:  attempts,
+		Err:       err.Error(),
+		FailedAt:  time.Now().UTC(),
+	})
+	if storeErr != nil {
+		return errors.Join(handlerErr, fmt.Errorf("storing dead letter: %w", storeErr))
 	}
+	handlerErr.DeadLettered = true
+	return handlerErr
+}
+
+// Use appends middlewares wrapping every handler attempt, the first one being
+// the outermost.
+func (ev *EventDispatcher) Use(middlewares ...HandlerMiddleware) {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	ev.middlewares = append(ev.middlewares[:len(ev.middlewares):len(ev.middlewares)], middlewares...)
+}
+
+// RunSequentially makes the events with the given name, or matching the given
+// pattern, run their handlers one at a time in priority order (see
+// WithPriority), each starting only once the previous one succeeded. The
+// first failure stops the chain, leaving the remaining handlers out.
+func (ev *EventDispatcher) RunSequentially(eventName string) error {
+	if err := validateSubscription(eventName); err != nil {
+		return err
+	}
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	ev.sequential[eventName] = true
 	return nil
 }
 
+// SetEventStore sets the log every dispatched event is appended to, and
+// onNotStored, which receives the events that could not be appended, wrapped
+// in ErrEventNotStored. A nil onNotStored logs them.
+func (ev *EventDispatcher) SetEventStore(eventStore EventStore, onNotStored func(event EventInterface, err error)) {
+	if onNotStored == nil {
+		onNotStored = func(event EventInterface, err error) {
+			log.Printf("Event %s %s: %v", event.GetName(), MetadataOf(event).ID, err)
+		}
+	}
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	ev.eventStore = eventStore
+	ev.onNotStored = onNotStored
+}
+
+// SetDeadLetterSink sets where the handler failures that exhausted their
+// retries are kept.
+func (ev *EventDispatcher) SetDeadLetterSink(sink DeadLetterSink) {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	ev.deadLetters = sink
+}
+
+// Close stops accepting events and waits for the queued ones to be handled.
+// It is a no-op for a synchronous dispatcher.
+func (ev *EventDispatcher) Close() {
+	if ev.async != nil {
+		ev.async.close()
+	}
+}
+
+// DroppedEvents counts the events discarded by BackpressureDrop.
+func (ev *EventDispatcher) DroppedEvents() uint64 {
+	if ev.async == nil {
+		return 0
+	}
+	return ev.async.dropped.Load()
+}
+
+// The handler slices are never modified in place, so Dispatch can keep
+// iterating over the slice it read while Register or Remove replace it.
+
 func (ed *EventDispatcher) Register(eventName string, handler EventHandlerInterface) error {
-	if _, ok := ed.handlers[eventName]; ok {
-		for _, h := range ed.handlers[eventName] {
-			if h == handler {
-				return ErrHandlerAlreadyRegistered
-			}
+	return ed.RegisterWith(eventName, handler)
+}
+
+// RegisterWithRetry registers the handler and applies the retry policy to
+// every event it handles.
+func (ed *EventDispatcher) RegisterWithRetry(eventName string, handler EventHandlerInterface, policy RetryPolicy) error {
+	return ed.RegisterWith(eventName, handler, WithRetry(policy))
+}
+
+// RegisterWith registers the handler for an event name or pattern. The
+// options apply to the handler itself, in every event it handles, and replace
+// the ones given in a previous registration.
+func (ed *EventDispatcher) RegisterWith(eventName string, handler EventHandlerInterface, options ...HandlerOption) error {
+	if err := validateSubscription(eventName); err != nil {
+		return err
+	}
+	if !reflect.TypeOf(handler).Comparable() {
+		return ErrHandlerNotComparable
+	}
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+
+	handlers := ed.handlers[eventName]
+	for _, h := range handlers {
+		if h == handler {
+			return ErrHandlerAlreadyRegistered
 		}
 	}
-	ed.handlers[eventName] = append(ed.handlers[eventName], handler)
+	ed.handlers[eventName] = append(handlers[:len(handlers):len(handlers)], handler)
+
+	if len(options) > 0 {
+		var handlerOptions handlerOptions
+		for _, option := range options {
+			option(&handlerOptions)
+		}
+		ed.options[handler] = handlerOptions
+	}
 	return nil
 }
 
 func (ed *EventDispatcher) Has(eventName string, handler EventHandlerInterface) bool {
-	if _, ok := ed.handlers[eventName]; ok {
-		for _, h := range ed.handlers[eventName] {
-			if h == handler {
-				return true
-			}
+	ed.mu.RLock()
+	defer ed.mu.RUnlock()
+
+	for _, h := range ed.handlers[eventName] {
+		if h == handler {
+			return true
 		}
 	}
 	return false
 }
 
 func (ed *EventDispatcher) Remove(eventName string, handler EventHandlerInterface) error {
-	if _, ok := ed.handlers[eventName]; ok {
-		for i, h := range ed.handlers[eventName] {
-			if h == handler {
-				ed.handlers[eventName] = append(ed.handlers[eventName][:i], ed.handlers[eventName][i+1:]...)
-				return nil
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+
+	handlers := ed.handlers[eventName]
+	for i, h := range handlers {
+		if h == handler {
+			remaining := make([]EventHandlerInterface, 0, len(handlers)-1)
+			remaining = append(remaining, handlers[:i]...)
+			ed.handlers[eventName] = append(remaining, handlers[i+1:]...)
+			if !ed.registered(handler) {
+				delete(ed.options, handler)
 			}
+			return nil
 		}
 	}
 	return nil
 }
 
+func (ed *EventDispatcher) registered(handler EventHandlerInterface) bool {
+	for _, handlers := range ed.handlers {
+		for _, h := range handlers {
+			if h == handler {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Clear removes every handler, along with the events marked to run them
+// sequentially.
 func (ed *EventDispatcher) Clear() {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+
 	ed.handlers = make(map[string][]EventHandlerInterface)
+	ed.options = make(map[EventHandlerInterface]handlerOptions)
+	ed.sequential = make(map[string]bool)
 }
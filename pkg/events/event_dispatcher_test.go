@@ -1,7 +1,8 @@
 package events
 
 import (
-	"sync"
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -27,15 +28,12 @@ func (e *TestEvent) GetDateTime() time.Time {
 	return time.Now()
 }
 
-func (e *TestEvent) SetPayload(payload interface{}) {
-	e.Payload = payload
-}
-
 type TestEventHandler struct {
 	ID int
 }
 
-func (h *TestEventHandler) Handle(event EventInterface, wg *sync.WaitGroup) {
+func (h *TestEventHandler) Handle(ctx context.Context, event EventInterface) error {
+	return nil
 }
 
 type EventDispatcherTestSuite struct {
@@ -101,8 +99,11 @@ func (suite *EventDispatcherTestSuite) TestEventDispatcher_Clear() {
 	suite.Nil(err)
 	suite.Equal(1, len(suite.eventDispatcher.handlers[suite.event2.GetName()]))
 
+	suite.Nil(suite.eventDispatcher.RunSequentially(suite.event.GetName()))
+
 	suite.eventDispatcher.Clear()
 	suite.Equal(0, len(suite.eventDispatcher.handlers))
+	suite.Equal(0, len(suite.eventDispatcher.sequential))
 }
 
 func (suite *EventDispatcherTestSuite) TestEventDispatcher_Has() {
@@ -150,28 +151,61 @@ type MockHandler struct {
 	mock.Mock
 }
 
-func (m *MockHandler) Handle(event EventInterface, wg *sync.WaitGroup) {
-	m.Called(event)
-	wg.Done()
+func (m *MockHandler) Handle(ctx context.Context, event EventInterface) error {
+	args := m.Called(event)
+	return args.Error(0)
 }
 
 func (suite *EventDispatcherTestSuite) TestEventDispatch_Dispatch() {
 	eh := &MockHandler{}
-	eh.On("Handle", &suite.event)
+	eh.On("Handle", &suite.event).Return(nil)
 
 	eh2 := &MockHandler{}
-	eh2.On("Handle", &suite.event)
+	eh2.On("Handle", &suite.event).Return(nil)
 
 	suite.eventDispatcher.Register(suite.event.GetName(), eh)
 	suite.eventDispatcher.Register(suite.event.GetName(), eh2)
 
-	suite.eventDispatcher.Dispatch(&suite.event)
+	err := suite.eventDispatcher.Dispatch(context.Background(), &suite.event)
+	suite.NoError(err)
 	eh.AssertExpectations(suite.T())
 	eh2.AssertExpectations(suite.T())
 	eh.AssertNumberOfCalls(suite.T(), "Handle", 1)
 	eh2.AssertNumberOfCalls(suite.T(), "Handle", 1)
 }
 
+func (suite *EventDispatcherTestSuite) TestEventDispatch_Dispatch_WithFailingHandlers() {
+	errPublish := errors.New("publish failed")
+	errTimeout := errors.New("timeout")
+
+	eh := &MockHandler{}
+	eh.On("Handle", &suite.event).Return(errPublish)
+
+	eh2 := &MockHandler{}
+	eh2.On("Handle", &suite.event).Return(nil)
+
+	eh3 := &MockHandler{}
+	eh3.On("Handle", &suite.event).Return(errTimeout)
+
+	suite.eventDispatcher.Register(suite.event.GetName(), eh)
+	suite.eventDispatcher.Register(suite.event.GetName(), eh2)
+	suite.eventDispatcher.Register(suite.event.GetName(), eh3)
+
+	err := suite.eventDispatcher.Dispatch(context.Background(), &suite.event)
+	suite.ErrorIs(err, errPublish)
+	suite.ErrorIs(err, errTimeout)
+
+	var handlerErr *HandlerError
+	suite.True(errors.As(err, &handlerErr))
+	suite.Equal(suite.event.GetName(), handlerErr.EventName)
+	suite.Contains([]EventHandlerInterface{eh, eh3}, handlerErr.Handler)
+	eh2.AssertNumberOfCalls(suite.T(), "Handle", 1)
+}
+
+func (suite *EventDispatcherTestSuite) TestEventDispatch_Dispatch_WithoutHandlers() {
+	suite.NoError(suite.eventDispatcher.Dispatch(context.Background(), &suite.event))
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(EventDispatcherTestSuite))
 }
@@ -0,0 +1,22 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrEventNotStored = errors.New("event not stored in the event log")
+
+// EventStore is an append-only log of the dispatched events, kept so they can
+// be replayed to rebuild what the handlers produce.
+type EventStore interface {
+	Append(ctx context.Context, event EventInterface) error
+}
+
+// handlerNamed matches a handler by its full name ("*handler.OrderCreatedHandler")
+// or by its bare type name ("OrderCreatedHandler").
+func handlerNamed(handler EventHandlerInterface, name string) bool {
+	fullName := HandlerName(handler)
+	return fullName == name || fullName[strings.LastIndex(fullName, ".")+1:] == name
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryEventStore struct {
+	mu     sync.Mutex
+	events []EventInterface
+	err    error
+}
+
+func (s *memoryEventStore) Append(ctx context.Context, event EventInterface) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestEventDispatcher_Dispatch_AppendsToEventStore(t *testing.T) {
+	store := &memoryEventStore{}
+	ed := NewEventDispatcher()
+	ed.SetEventStore(store, nil)
+	handler := &countingHandler{}
+	require.NoError(t, ed.Register("test", handler))
+
+	event := NewEvent("test", "payload")
+	require.NoError(t, ed.Dispatch(context.Background(), event))
+	require.NoError(t, ed.Dispatch(context.Background(), NewEvent("unhandled", "payload")))
+
+	require.Len(t, store.events, 2)
+	assert.Same(t, event, store.events[0])
+	assert.Equal(t, int32(1), handler.handled.Load())
+}
+
+func TestEventDispatcher_Dispatch_EventStoreFailure(t *testing.T) {
+	ed := NewEventDispatcher()
+	var notStored []error
+	ed.SetEventStore(&memoryEventStore{err: errors.New("database is down")}, func(event EventInterface, err error) {
+		notStored = append(notStored, err)
+	})
+	handler := &countingHandler{}
+	require.NoError(t, ed.Register("test", handler))
+
+	err := ed.Dispatch(context.Background(), NewEvent("test", "payload"))
+	assert.NoError(t, err, "the handlers succeeded")
+	assert.Equal(t, int32(1), handler.handled.Load())
+	require.Len(t, notStored, 1)
+	assert.ErrorIs(t, notStored[0], ErrEventNotStored)
+}
+
+func TestEventDispatcher_Replay(t *testing.T) {
+	store := &memoryEventStore{}
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetEventStore(store, nil)
+	ed.SetDeadLetterSink(sink)
+	selected := &countingHandler{}
+	other := &flakyHandler{failures: 1, err: errors.New("unavailable")}
+	require.NoError(t, ed.Register("test", selected))
+	require.NoError(t, ed.Register("test", other))
+
+	event := NewEvent("test", "payload")
+	require.NoError(t, ed.Replay(context.Background(), event, "countingHandler"))
+	assert.Equal(t, int32(1), selected.handled.Load())
+
+	assert.Error(t, ed.Replay(context.Background(), event))
+	assert.Equal(t, int32(2), selected.handled.Load())
+	assert.Empty(t, sink.deadLetters)
+	assert.Empty(t, store.events)
+
+	assert.NoError(t, ed.Replay(context.Background(), event, HandlerName(other)))
+	assert.NoError(t, ed.Replay(context.Background(), NewEvent("unhandled", "payload")))
+}
+
+func TestEventDispatcher_HasHandlerNamed(t *testing.T) {
+	ed := NewEventDispatcher()
+	require.NoError(t, ed.Register("test", &countingHandler{}))
+
+	assert.True(t, ed.HasHandlerNamed("countingHandler"))
+	assert.True(t, ed.HasHandlerNamed("*events.countingHandler"))
+	assert.False(t, ed.HasHandlerNamed("flakyHandler"))
+}
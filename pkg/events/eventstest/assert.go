@@ -0,0 +1,104 @@
+package eventstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// AssertDispatched checks an event with the given name was recorded and
+// returns the first one.
+func AssertDispatched(t testing.TB, recorder Recorder, eventName string) events.EventInterface {
+	t.Helper()
+	for _, event := range recorder.Events() {
+		if event.GetName() == eventName {
+			return event
+		}
+	}
+	assert.Fail(t, fmt.Sprintf("event %s was not dispatched", eventName), "dispatched: %s", names(recorder))
+	return nil
+}
+
+// AssertDispatchedWithPayload checks an event with the given name and an
+// equal payload was recorded.
+func AssertDispatchedWithPayload(t testing.TB, recorder Recorder, eventName string, payload interface{}) bool {
+	t.Helper()
+	var payloads []interface{}
+	for _, event := range recorder.Events() {
+		if event.GetName() != eventName {
+			continue
+		}
+		if assert.ObjectsAreEqual(payload, event.GetPayload()) {
+			return true
+		}
+		payloads = append(payloads, event.GetPayload())
+	}
+	if len(payloads) == 0 {
+		return assert.Fail(t, fmt.Sprintf("event %s was not dispatched", eventName), "dispatched: %s", names(recorder))
+	}
+	return assert.Fail(t, fmt.Sprintf("event %s was not dispatched with payload %#v", eventName, payload), "payloads: %#v", payloads)
+}
+
+// AssertNotDispatched checks no event with the given name was recorded.
+func AssertNotDispatched(t testing.TB, recorder Recorder, eventName string) bool {
+	t.Helper()
+	return AssertDispatchedCount(t, recorder, eventName, 0)
+}
+
+// AssertDispatchedCount checks how many events with the given name were
+// recorded.
+func AssertDispatchedCount(t testing.TB, recorder Recorder, eventName string, count int) bool {
+	t.Helper()
+	return assert.Equal(t, count, countNamed(recorder, eventName), "number of %s events dispatched", eventName)
+}
+
+// AssertDispatchOrder checks the recorded events, by name, are exactly the
+// given ones in the given order.
+func AssertDispatchOrder(t testing.TB, recorder Recorder, eventNames ...string) bool {
+	t.Helper()
+	return assert.Equal(t, append([]string{}, eventNames...), nameList(recorder), "events dispatched")
+}
+
+// EventuallyDispatched waits up to timeout for count events with the given
+// name to be recorded, for handlers run by an async dispatcher.
+func EventuallyDispatched(t testing.TB, recorder Recorder, eventName string, count int, timeout time.Duration) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for countNamed(recorder, eventName) < count {
+		if time.Now().After(deadline) {
+			return assert.Fail(t, fmt.Sprintf("expected %d %s events within %s", count, eventName, timeout), "dispatched: %s", names(recorder))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+func countNamed(recorder Recorder, eventName string) int {
+	count := 0
+	for _, event := range recorder.Events() {
+		if event.GetName() == eventName {
+			count++
+		}
+	}
+	return count
+}
+
+func nameList(recorder Recorder) []string {
+	eventNames := []string{}
+	for _, event := range recorder.Events() {
+		eventNames = append(eventNames, event.GetName())
+	}
+	return eventNames
+}
+
+func names(recorder Recorder) string {
+	eventNames := nameList(recorder)
+	if len(eventNames) == 0 {
+		return "none"
+	}
+	return strings.Join(eventNames, ", ")
+}
@@ -0,0 +1,102 @@
+package eventstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// failureRecorder stands in for the test passed to the assertions, so their
+// failures can be checked.
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (f *failureRecorder) Helper() {}
+
+func (f *failureRecorder) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func TestDispatcher_RecordsAndRunsHandlers(t *testing.T) {
+	dispatcher := NewDispatcher()
+	handler := NewHandler()
+	require.NoError(t, dispatcher.Register("OrderCreated", handler))
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), events.NewEvent("OrderCreated", "order-1")))
+	require.NoError(t, dispatcher.Dispatch(context.Background(), events.NewEvent("OrderCancelled", "order-1")))
+
+	AssertDispatchOrder(t, dispatcher, "OrderCreated", "OrderCancelled")
+	AssertDispatchOrder(t, handler, "OrderCreated")
+	assert.Equal(t, []string{"order-1"}, Payloads[string](handler, "OrderCreated"))
+
+	dispatcher.Reset()
+	AssertDispatchOrder(t, dispatcher)
+}
+
+func TestDispatcher_Err(t *testing.T) {
+	dispatcher := NewDispatcher()
+	dispatcher.Err = assert.AnError
+	handler := NewHandler()
+	require.NoError(t, dispatcher.Register("OrderCreated", handler))
+
+	err := dispatcher.Dispatch(context.Background(), events.NewEvent("OrderCreated", "order-1"))
+
+	assert.ErrorIs(t, err, assert.AnError)
+	AssertDispatchedCount(t, dispatcher, "OrderCreated", 1)
+	AssertNotDispatched(t, handler, "OrderCreated")
+}
+
+func TestAssertions(t *testing.T) {
+	dispatcher := NewDispatcher()
+	dispatcher.Dispatch(context.Background(), events.NewEvent("OrderCreated", map[string]string{"id": "1"}))
+	dispatcher.Dispatch(context.Background(), events.NewEvent("OrderCreated", map[string]string{"id": "2"}))
+
+	event := AssertDispatched(t, dispatcher, "OrderCreated")
+	assert.Equal(t, map[string]string{"id": "1"}, event.GetPayload())
+	AssertDispatchedWithPayload(t, dispatcher, "OrderCreated", map[string]string{"id": "2"})
+	AssertDispatchedCount(t, dispatcher, "OrderCreated", 2)
+	AssertNotDispatched(t, dispatcher, "OrderCancelled")
+
+	failures := []struct {
+		name   string
+		assert func(t testing.TB) bool
+	}{
+		{"dispatched", func(t testing.TB) bool { return AssertDispatched(t, dispatcher, "OrderCancelled") != nil }},
+		{"payload", func(t testing.TB) bool {
+			return AssertDispatchedWithPayload(t, dispatcher, "OrderCreated", map[string]string{"id": "3"})
+		}},
+		{"count", func(t testing.TB) bool { return AssertDispatchedCount(t, dispatcher, "OrderCreated", 1) }},
+		{"not dispatched", func(t testing.TB) bool { return AssertNotDispatched(t, dispatcher, "OrderCreated") }},
+		{"order", func(t testing.TB) bool { return AssertDispatchOrder(t, dispatcher, "OrderCreated") }},
+		{"eventually", func(t testing.TB) bool {
+			return EventuallyDispatched(t, dispatcher, "OrderCreated", 3, 10*time.Millisecond)
+		}},
+	}
+	for _, failure := range failures {
+		t.Run(failure.name, func(t *testing.T) {
+			recorder := &failureRecorder{TB: t}
+			assert.False(t, failure.assert(recorder))
+			assert.True(t, recorder.failed)
+		})
+	}
+}
+
+func TestEventuallyDispatched_AsyncDispatcher(t *testing.T) {
+	dispatcher := events.NewAsyncEventDispatcher(events.AsyncConfig{Workers: 2, QueueSize: 10})
+	defer dispatcher.Close()
+	handler := NewHandler()
+	require.NoError(t, dispatcher.Register("OrderCreated", handler))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, dispatcher.Dispatch(context.Background(), events.NewEvent("OrderCreated", i)))
+	}
+
+	EventuallyDispatched(t, handler, "OrderCreated", 5, time.Second)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, Payloads[int](handler, "OrderCreated"))
+}
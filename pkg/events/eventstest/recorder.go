@@ -0,0 +1,89 @@
+// Package eventstest provides a recording dispatcher, a recording handler and
+// assertions to verify the events raised by the code under test.
+package eventstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vs0uz4/clean_architecture/pkg/events"
+)
+
+// Recorder is anything that keeps the events it saw, in the order it saw
+// them.
+type Recorder interface {
+	Events() []events.EventInterface
+}
+
+type recording struct {
+	mu     sync.Mutex
+	events []events.EventInterface
+}
+
+func (r *recording) record(event events.EventInterface) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recording) Events() []events.EventInterface {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]events.EventInterface(nil), r.events...)
+}
+
+// Reset forgets the events recorded so far.
+func (r *recording) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// Dispatcher is an events.EventDispatcherInterface that records every event
+// dispatched and then hands it to the handlers registered on it, unless Err is
+// set, in which case Dispatch returns Err without running them.
+type Dispatcher struct {
+	*events.EventDispatcher
+	recording
+	Err error
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{EventDispatcher: events.NewEventDispatcher()}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, event events.EventInterface) error {
+	d.record(event)
+	if d.Err != nil {
+		return d.Err
+	}
+	return d.EventDispatcher.Dispatch(ctx, event)
+}
+
+// Handler is an events.EventHandlerInterface that records every event it
+// handles and returns Err.
+type Handler struct {
+	recording
+	Err error
+}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+func (h *Handler) Handle(ctx context.Context, event events.EventInterface) error {
+	h.record(event)
+	return h.Err
+}
+
+// Payloads returns the payloads of the recorded events with the given name
+// that are a T.
+func Payloads[T any](recorder Recorder, eventName string) []T {
+	var payloads []T
+	for _, event := range recorder.Events() {
+		if payload, ok := event.GetPayload().(T); ok && event.GetName() == eventName {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrInboxInProgress is returned for an event another delivery is still
+// processing. It is not permanent: the delivery should be retried, so it can
+// take the event over if the other one dies before processing it.
+var ErrInboxInProgress = errors.New("event is being processed by another delivery")
+
+// InboxStore records which handler processed which event, so events
+// delivered more than once are handled once.
+type InboxStore interface {
+	// Claim reserves the event for the handler until leaseUntil. It returns
+	// false when the event was already processed by it, and
+	// ErrInboxInProgress when it is claimed by a delivery whose lease has not
+	// expired.
+	Claim(ctx context.Context, eventID, handler string, now, leaseUntil time.Time) (bool, error)
+	// Complete marks a claimed event as processed, for good.
+	Complete(ctx context.Context, eventID, handler string, processedAt time.Time) error
+	// Release drops the claim of an event the handler failed to process, so
+	// a later delivery can try again.
+	Release(ctx context.Context, eventID, handler string) error
+}
+
+// InboxHandler wraps a handler so each event, by its ID, is processed by it
+// only once: the events it already processed are skipped, and the ones being
+// processed by another delivery fail with ErrInboxInProgress. Events without
+// an ID cannot be told apart and are always handled.
+type InboxHandler struct {
+	store   InboxStore
+	handler EventHandlerInterface
+	// Lease is how long a delivery has to process the event before another
+	// one may claim it, in case the first one died halfway.
+	Lease time.Duration
+	now   func() time.Time
+}
+
+func NewInboxHandler(store InboxStore, handler EventHandlerInterface) *InboxHandler {
+	return &InboxHandler{
+		store:   store,
+		handler: handler,
+		Lease:   5 * time.Minute,
+		now:     time.Now,
+	}
+}
+
+// HandlerName keeps the wrapped handler name in dead letters, metrics and
+// redeliveries.
+func (h *InboxHandler) HandlerName() string {
+	return HandlerName(h.handler)
+}
+
+func (h *InboxHandler) Handle(ctx context.Context, event EventInterface) error {
+	eventID := MetadataOf(event).ID
+	if eventID == "" {
+		return h.handler.Handle(ctx, event)
+	}
+
+	handlerName := h.HandlerName()
+	now := h.now().UTC()
+	claimed, err := h.store.Claim(ctx, eventID, handlerName, now, now.Add(h.Lease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		log.Printf("Skipping duplicate %s %s for %s", event.GetName(), eventID, handlerName)
+		return nil
+	}
+
+	if err := h.handler.Handle(ctx, event); err != nil {
+		if releaseErr := h.store.Release(context.WithoutCancel(ctx), eventID, handlerName); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+	return h.store.Complete(context.WithoutCancel(ctx), eventID, handlerName, h.now().UTC())
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inboxEntry struct {
+	leaseUntil time.Time
+	processed  bool
+}
+
+type memoryInboxStore struct {
+	mu      sync.Mutex
+	entries map[[2]string]*inboxEntry
+}
+
+func newMemoryInboxStore() *memoryInboxStore {
+	return &memoryInboxStore{entries: make(map[[2]string]*inboxEntry)}
+}
+
+func (s *memoryInboxStore) Claim(ctx context.Context, eventID, handler string, now, leaseUntil time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[[2]string{eventID, handler}]
+	if ok && entry.processed {
+		return false, nil
+	}
+	if ok && entry.leaseUntil.After(now) {
+		return false, ErrInboxInProgress
+	}
+	s.entries[[2]string{eventID, handler}] = &inboxEntry{leaseUntil: leaseUntil}
+	return true, nil
+}
+
+func (s *memoryInboxStore) Complete(ctx context.Context, eventID, handler string, processedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[[2]string{eventID, handler}].processed = true
+	return nil
+}
+
+func (s *memoryInboxStore) Release(ctx context.Context, eventID, handler string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, [2]string{eventID, handler})
+	return nil
+}
+
+func TestInboxHandler_SkipsDuplicates(t *testing.T) {
+	store := newMemoryInboxStore()
+	inner := &countingHandler{}
+	handler := NewInboxHandler(store, inner)
+	event := NewEvent("test", "payload")
+
+	require.NoError(t, handler.Handle(context.Background(), event))
+	require.NoError(t, handler.Handle(context.Background(), event))
+	assert.Equal(t, int32(1), inner.handled.Load())
+
+	require.NoError(t, handler.Handle(context.Background(), NewEvent("test", "payload")))
+	assert.Equal(t, int32(2), inner.handled.Load())
+
+	other := &countingHandler{}
+	require.NoError(t, NewInboxHandler(store, other).Handle(context.Background(), event))
+	assert.Equal(t, int32(0), other.handled.Load(), "handlers with the same name share the inbox entry")
+}
+
+func TestInboxHandler_RetriesFailures(t *testing.T) {
+	store := newMemoryInboxStore()
+	inner := &flakyHandler{failures: 1, err: errors.New("unavailable")}
+	handler := NewInboxHandler(store, inner)
+	event := NewEvent("test", "payload")
+
+	assert.Error(t, handler.Handle(context.Background(), event))
+	assert.NoError(t, handler.Handle(context.Background(), event))
+	assert.NoError(t, handler.Handle(context.Background(), event))
+	assert.Equal(t, int32(2), inner.calls.Load())
+}
+
+func TestInboxHandler_ExpiredLease(t *testing.T) {
+	store := newMemoryInboxStore()
+	inner := &countingHandler{}
+	handler := NewInboxHandler(store, inner)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+	event := NewEvent("test", "payload")
+
+	claimed, err := store.Claim(context.Background(), event.GetMetadata().ID, handler.HandlerName(), now, now.Add(handler.Lease))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	err = handler.Handle(context.Background(), event)
+	assert.ErrorIs(t, err, ErrInboxInProgress)
+	assert.False(t, IsPermanent(err))
+	assert.Equal(t, int32(0), inner.handled.Load())
+
+	now = now.Add(handler.Lease + time.Second)
+	require.NoError(t, handler.Handle(context.Background(), event))
+	assert.Equal(t, int32(1), inner.handled.Load())
+}
+
+func TestInboxHandler_EventsWithoutID(t *testing.T) {
+	inner := &countingHandler{}
+	handler := NewInboxHandler(newMemoryInboxStore(), inner)
+
+	require.NoError(t, handler.Handle(context.Background(), &TestEvent{Name: "test"}))
+	require.NoError(t, handler.Handle(context.Background(), &TestEvent{Name: "test"}))
+	assert.Equal(t, int32(2), inner.handled.Load())
+	assert.Equal(t, "*events.countingHandler", HandlerName(handler))
+}
@@ -1,7 +1,7 @@
 package events
 
 import (
-	"sync"
+	"context"
 	"time"
 )
 
@@ -9,16 +9,20 @@ type EventInterface interface {
 	GetName() string
 	GetDateTime() time.Time
 	GetPayload() interface{}
-	SetPayload(payload interface{})
 }
 
+// EventFactory builds a new event for every dispatch, so concurrent callers
+// never share an instance or its payload. ctx carries the correlation of the
+// request the event is created for.
+type EventFactory func(ctx context.Context, payload interface{}) EventInterface
+
 type EventHandlerInterface interface {
-	Handle(event EventInterface, wg *sync.WaitGroup)
+	Handle(ctx context.Context, event EventInterface) error
 }
 
 type EventDispatcherInterface interface {
 	Register(eventName string, handler EventHandlerInterface) error
-	Dispatch(event EventInterface) error
+	Dispatch(ctx context.Context, event EventInterface) error
 	Remove(eventName string, handler EventHandlerInterface) error
 	Has(eventName string, handler EventHandlerInterface) bool
 	Clear()
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Metadata is the envelope of an event: what identifies it and ties it to the
+// request or event that caused it, so consumers can deduplicate and trace.
+type Metadata struct {
+	ID         string    `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// CorrelationID is shared by every event descending from the same
+	// request; CausationID is the ID of the request or event that directly
+	// caused this one.
+	CorrelationID string `json:"correlation_id"`
+	CausationID   string `json:"causation_id,omitempty"`
+	Version       int    `json:"version"`
+	Source        string `json:"source,omitempty"`
+}
+
+// MetadataOf returns the metadata of events that carry it. For the others
+// only OccurredAt is known.
+func MetadataOf(event EventInterface) Metadata {
+	if carrier, ok := event.(interface{ GetMetadata() Metadata }); ok {
+		return carrier.GetMetadata()
+	}
+	return Metadata{OccurredAt: event.GetDateTime()}
+}
+
+// EventOption sets the metadata of an event built with NewEvent.
+type EventOption func(metadata *Metadata)
+
+func WithVersion(version int) EventOption {
+	return func(metadata *Metadata) {
+		metadata.Version = version
+	}
+}
+
+func WithSource(source string) EventOption {
+	return func(metadata *Metadata) {
+		metadata.Source = source
+	}
+}
+
+// newMetadata builds the envelope of a new event. Without a correlation in
+// ctx the event starts a new one, correlated with itself.
+func newMetadata(ctx context.Context, options []EventOption) Metadata {
+	metadata := Metadata{
+		ID:            NewID(),
+		OccurredAt:    time.Now().UTC(),
+		CorrelationID: CorrelationIDFromContext(ctx),
+		CausationID:   CausationIDFromContext(ctx),
+		Version:       1,
+	}
+	if metadata.CorrelationID == "" {
+		metadata.CorrelationID = metadata.ID
+	}
+	for _, option := range options {
+		option(&metadata)
+	}
+	return metadata
+}
+
+// NewID returns a random UUID (version 4).
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type correlationIDKey struct{}
+
+type causationIDKey struct{}
+
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// ContextWithCausationID records what is being processed in ctx, an incoming
+// request or an event being handled, as the cause of the events created
+// under it.
+func ContextWithCausationID(ctx context.Context, causationID string) context.Context {
+	return context.WithValue(ctx, causationIDKey{}, causationID)
+}
+
+func CausationIDFromContext(ctx context.Context) string {
+	causationID, _ := ctx.Value(causationIDKey{}).(string)
+	return causationID
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvent_Metadata(t *testing.T) {
+	event := NewEvent("Order.Placed", orderPlaced{ID: "1"}, WithVersion(2), WithSource("orders"))
+	other := NewEvent("Order.Placed", orderPlaced{ID: "1"})
+	metadata := event.GetMetadata()
+
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, metadata.ID)
+	assert.NotEqual(t, metadata.ID, other.GetMetadata().ID)
+	assert.Equal(t, metadata.ID, metadata.CorrelationID)
+	assert.Empty(t, metadata.CausationID)
+	assert.Equal(t, 2, metadata.Version)
+	assert.Equal(t, "orders", metadata.Source)
+	assert.Equal(t, 1, other.GetMetadata().Version)
+
+	occurredAt := event.GetDateTime()
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, occurredAt, event.GetDateTime())
+	assert.Equal(t, metadata, MetadataOf(event))
+}
+
+func TestNewEventWithContext_UsesRequestCorrelation(t *testing.T) {
+	ctx := ContextWithCausationID(ContextWithCorrelationID(context.Background(), "correlation-1"), "request-1")
+
+	metadata := NewEventWithContext(ctx, "Order.Placed", orderPlaced{}).GetMetadata()
+
+	assert.Equal(t, "correlation-1", metadata.CorrelationID)
+	assert.Equal(t, "request-1", metadata.CausationID)
+	assert.NotEqual(t, "correlation-1", metadata.ID)
+}
+
+func TestMetadataOf_UntypedEvent(t *testing.T) {
+	metadata := MetadataOf(&TestEvent{Name: "test"})
+
+	assert.Empty(t, metadata.ID)
+	assert.False(t, metadata.OccurredAt.IsZero())
+}
+
+// chainingHandler publishes a follow-up event while handling one.
+type chainingHandler struct {
+	dispatcher *EventDispatcher
+}
+
+func (h *chainingHandler) Handle(ctx context.Context, event *Event[orderPlaced]) error {
+	return Publish(ctx, h.dispatcher, "Order.Confirmed", event.Payload())
+}
+
+type metadataRecorder struct {
+	received chan Metadata
+}
+
+func (h *metadataRecorder) Handle(ctx context.Context, event EventInterface) error {
+	h.received <- MetadataOf(event)
+	return nil
+}
+
+func TestEventDispatcher_PropagatesCorrelationToFollowUpEvents(t *testing.T) {
+	ed := NewEventDispatcher()
+	recorder := &metadataRecorder{received: make(chan Metadata, 1)}
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", &chainingHandler{dispatcher: ed}))
+	require.NoError(t, ed.Register("Order.Confirmed", recorder))
+
+	ctx := ContextWithCorrelationID(context.Background(), "correlation-1")
+	placed := NewEventWithContext(ctx, "Order.Placed", orderPlaced{ID: "1"})
+	require.NoError(t, ed.Dispatch(ctx, placed))
+
+	confirmed := <-recorder.received
+	assert.Equal(t, "correlation-1", confirmed.CorrelationID)
+	assert.Equal(t, placed.GetMetadata().ID, confirmed.CausationID)
+	assert.NotEqual(t, placed.GetMetadata().ID, confirmed.ID)
+}
+
+func TestEventDispatcher_DeadLetterKeepsMetadata(t *testing.T) {
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(sink)
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", &orderPlacedHandler{err: assert.AnError}))
+
+	event := NewEvent("Order.Placed", orderPlaced{ID: "1"})
+	assert.Error(t, ed.Dispatch(context.Background(), event))
+
+	require.Len(t, sink.deadLetters, 1)
+	assert.Equal(t, event.GetMetadata(), sink.deadLetters[0].Metadata)
+	restored := RestoreEvent("Order.Placed", orderPlaced{ID: "1"}, sink.deadLetters[0].Metadata)
+	assert.Equal(t, event.GetMetadata(), restored.GetMetadata())
+}
@@ -0,0 +1,276 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// HandlerMiddleware wraps a handler with cross-cutting behavior. Middlewares
+// registered with EventDispatcher.Use run around every handler attempt, the
+// first one registered being the outermost.
+type HandlerMiddleware func(next EventHandlerInterface) EventHandlerInterface
+
+// HandlerFunc adapts a function to EventHandlerInterface.
+type HandlerFunc func(ctx context.Context, event EventInterface) error
+
+func (f HandlerFunc) Handle(ctx context.Context, event EventInterface) error {
+	return f(ctx, event)
+}
+
+type handlerNameKey struct{}
+
+// HandlerNameFromContext returns the name of the handler being run, since
+// inside a middleware next may be another middleware.
+func HandlerNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(handlerNameKey{}).(string)
+	return name
+}
+
+func chainMiddlewares(handler EventHandlerInterface, middlewares []HandlerMiddleware) EventHandlerInterface {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// PanicError is returned by Recovery for a handler that panicked. It is
+// permanent, a panic being a bug that retrying will not fix.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// Recovery turns a handler panic into a *PanicError instead of crashing the
+// process.
+func Recovery() HandlerMiddleware {
+	return func(next EventHandlerInterface) EventHandlerInterface {
+		return HandlerFunc(func(ctx context.Context, event EventInterface) (err error) {
+			defer func() {
+				if value := recover(); value != nil {
+					err = Permanent(&PanicError{Value: value, Stack: debug.Stack()})
+				}
+			}()
+			return next.Handle(ctx, event)
+		})
+	}
+}
+
+// Logging writes one structured record per handler run.
+func Logging(logger *slog.Logger) HandlerMiddleware {
+	return func(next EventHandlerInterface) EventHandlerInterface {
+		return HandlerFunc(func(ctx context.Context, event EventInterface) error {
+			start := time.Now()
+			err := next.Handle(ctx, event)
+			attrs := []any{
+				slog.String("event", event.GetName()),
+				slog.String("handler", HandlerNameFromContext(ctx)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "event handler failed", append(attrs, slog.Any("error", err))...)
+				return err
+			}
+			logger.InfoContext(ctx, "event handled", attrs...)
+			return nil
+		})
+	}
+}
+
+// Timeout bounds each handler run. The handler gets a context with the
+// deadline and Timeout returns once it expires, even if the handler ignores
+// the context and keeps running in the background. Since the handler runs in
+// its own goroutine, Recovery must come after Timeout in the chain. A
+// timeout of zero or less leaves the handlers unbounded.
+func Timeout(timeout time.Duration) HandlerMiddleware {
+	return func(next EventHandlerInterface) EventHandlerInterface {
+		if timeout <= 0 {
+			return next
+		}
+		return HandlerFunc(func(ctx context.Context, event EventInterface) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- next.Handle(ctx, event)
+			}()
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				return fmt.Errorf("handler %s timed out after %s: %w", HandlerNameFromContext(ctx), timeout, ctx.Err())
+			}
+		})
+	}
+}
+
+// HandlerStat aggregates the runs of one handler for one event.
+type HandlerStat struct {
+	EventName     string        `json:"event_name"`
+	Handler       string        `json:"handler"`
+	Calls         int64         `json:"calls"`
+	Failures      int64         `json:"failures"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+}
+
+// HandlerMetrics collects the durations recorded by Timing.
+type HandlerMetrics struct {
+	mu    sync.Mutex
+	stats map[[2]string]*HandlerStat
+}
+
+func NewHandlerMetrics() *HandlerMetrics {
+	return &HandlerMetrics{stats: make(map[[2]string]*HandlerStat)}
+}
+
+func (m *HandlerMetrics) Observe(eventName, handler string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]string{eventName, handler}
+	stat, ok := m.stats[key]
+	if !ok {
+		stat = &HandlerStat{EventName: eventName, Handler: handler}
+		m.stats[key] = stat
+	}
+	stat.Calls++
+	if err != nil {
+		stat.Failures++
+	}
+	stat.TotalDuration += duration
+	stat.MaxDuration = max(stat.MaxDuration, duration)
+}
+
+// Snapshot returns a copy of the collected stats.
+func (m *HandlerMetrics) Snapshot() []HandlerStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]HandlerStat, 0, len(m.stats))
+	for _, stat := range m.stats {
+		stats = append(stats, *stat)
+	}
+	return stats
+}
+
+// Timing records how long every handler run takes and whether it failed.
+func Timing(metrics *HandlerMetrics) HandlerMiddleware {
+	return func(next EventHandlerInterface) EventHandlerInterface {
+		return HandlerFunc(func(ctx context.Context, event EventInterface) error {
+			start := time.Now()
+			err := next.Handle(ctx, event)
+			metrics.Observe(event.GetName(), HandlerNameFromContext(ctx), time.Since(start), err)
+			return err
+		})
+	}
+}
+
+// Tracer starts spans for Tracing. It mirrors the OpenTelemetry tracer so an
+// adapter is a few lines, without tying this package to a tracing library.
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracing runs every handler inside its own span, children of the span of
+// the dispatching request when there is one in the context.
+func Tracing(tracer Tracer) HandlerMiddleware {
+	return func(next EventHandlerInterface) EventHandlerInterface {
+		return HandlerFunc(func(ctx context.Context, event EventInterface) error {
+			ctx, span := tracer.Start(ctx, "event "+event.GetName())
+			defer span.End()
+			span.SetAttribute("event.name", event.GetName())
+			span.SetAttribute("event.handler", HandlerNameFromContext(ctx))
+
+			err := next.Handle(ctx, event)
+			if err != nil {
+				span.RecordError(err)
+			}
+			return err
+		})
+	}
+}
+
+type spanKey struct{}
+
+// LogTracer is a Tracer writing each span as a structured record when it
+// ends, for deployments without a tracing backend. Spans started under
+// another one share its trace; the others start a trace of their own,
+// identified by the correlation of the context when there is one.
+type LogTracer struct {
+	logger *slog.Logger
+}
+
+func NewLogTracer(logger *slog.Logger) *LogTracer {
+	return &LogTracer{logger: logger}
+}
+
+func (t *LogTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	span := &logSpan{
+		logger: t.logger,
+		ctx:    ctx,
+		name:   spanName,
+		id:     NewID(),
+		start:  time.Now(),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*logSpan); ok {
+		span.traceID, span.parentID = parent.traceID, parent.id
+	} else if span.traceID = CorrelationIDFromContext(ctx); span.traceID == "" {
+		span.traceID = span.id
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type logSpan struct {
+	logger            *slog.Logger
+	ctx               context.Context
+	name              string
+	id                string
+	traceID, parentID string
+	start             time.Time
+	mu                sync.Mutex
+	attrs             []any
+	err               error
+}
+
+func (s *logSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, slog.Any(key, value))
+}
+
+func (s *logSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *logSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attrs := append([]any{
+		slog.String("span", s.name),
+		slog.String("trace_id", s.traceID),
+		slog.String("span_id", s.id),
+		slog.String("parent_span_id", s.parentID),
+		slog.Duration("duration", time.Since(s.start)),
+	}, s.attrs...)
+	if s.err != nil {
+		attrs = append(attrs, slog.Any("error", s.err))
+	}
+	s.logger.DebugContext(s.ctx, "span ended", attrs...)
+}
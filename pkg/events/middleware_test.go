@@ -0,0 +1,189 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type panickingHandler struct{}
+
+func (h *panickingHandler) Handle(ctx context.Context, event EventInterface) error {
+	panic("boom")
+}
+
+type slowHandler struct {
+	delay time.Duration
+}
+
+func (h *slowHandler) Handle(ctx context.Context, event EventInterface) error {
+	select {
+	case <-time.After(h.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestEventDispatcher_Use_WrapsHandlersInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) HandlerMiddleware {
+		return func(next EventHandlerInterface) EventHandlerInterface {
+			return HandlerFunc(func(ctx context.Context, event EventInterface) error {
+				mu.Lock()
+				calls = append(calls, name+":"+HandlerNameFromContext(ctx))
+				mu.Unlock()
+				return next.Handle(ctx, event)
+			})
+		}
+	}
+
+	ed := NewEventDispatcher()
+	ed.Use(record("outer"), record("inner"))
+	handler := &countingHandler{}
+	require.NoError(t, ed.Register("test", handler))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+
+	assert.Equal(t, []string{"outer:*events.countingHandler", "inner:*events.countingHandler"}, calls)
+	assert.Equal(t, int32(1), handler.handled.Load())
+}
+
+func TestRecovery(t *testing.T) {
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.Use(Recovery())
+	ed.SetDeadLetterSink(sink)
+	other := &countingHandler{}
+	require.NoError(t, ed.RegisterWithRetry("test", &panickingHandler{}, fastRetry))
+	require.NoError(t, ed.Register("test", other))
+
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "test"})
+
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Equal(t, int32(1), other.handled.Load())
+	require.Len(t, sink.deadLetters, 1)
+	assert.Equal(t, 1, sink.deadLetters[0].Attempts)
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	ed := NewEventDispatcher()
+	ed.Use(Logging(slog.New(slog.NewJSONHandler(&buf, nil))))
+	require.NoError(t, ed.Register("test", &flakyHandler{failures: 1, err: errors.New("unavailable")}))
+
+	assert.Error(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+
+	assert.Contains(t, buf.String(), `"level":"ERROR"`)
+	assert.Contains(t, buf.String(), `"event":"test"`)
+	assert.Contains(t, buf.String(), `"handler":"*events.flakyHandler"`)
+	assert.Contains(t, buf.String(), `"error":"unavailable"`)
+}
+
+func TestTimeout(t *testing.T) {
+	ed := NewEventDispatcher()
+	ed.Use(Timeout(10 * time.Millisecond))
+	require.NoError(t, ed.Register("test", &slowHandler{delay: time.Hour}))
+
+	start := time.Now()
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "test"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestTimeout_Disabled(t *testing.T) {
+	ed := NewEventDispatcher()
+	ed.Use(Timeout(0))
+	require.NoError(t, ed.Register("test", &slowHandler{delay: 10 * time.Millisecond}))
+
+	assert.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+}
+
+func TestTiming(t *testing.T) {
+	metrics := NewHandlerMetrics()
+	ed := NewEventDispatcher()
+	ed.Use(Timing(metrics))
+	require.NoError(t, ed.RegisterWithRetry("test", &flakyHandler{failures: 1, err: errors.New("unavailable")}, fastRetry))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+
+	stats := metrics.Snapshot()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "test", stats[0].EventName)
+	assert.Equal(t, "*events.flakyHandler", stats[0].Handler)
+	assert.Equal(t, int64(2), stats[0].Calls)
+	assert.Equal(t, int64(1), stats[0].Failures)
+	assert.GreaterOrEqual(t, stats[0].TotalDuration, stats[0].MaxDuration)
+}
+
+type recordedSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordedSpan) RecordError(err error)                      { s.err = err }
+func (s *recordedSpan) End()                                       { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	span := &recordedSpan{name: spanName, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestTracing(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	tracer := &recordingTracer{}
+	ed := NewEventDispatcher()
+	ed.Use(Tracing(tracer))
+	require.NoError(t, ed.Register("test", &flakyHandler{failures: 1, err: errUnavailable}))
+
+	assert.Error(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	assert.Equal(t, "event test", span.name)
+	assert.Equal(t, "*events.flakyHandler", span.attributes["event.handler"])
+	assert.ErrorIs(t, span.err, errUnavailable)
+	assert.True(t, span.ended)
+}
+
+func TestLogTracer(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewLogTracer(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	ctx := ContextWithCorrelationID(context.Background(), "request-1")
+
+	ctx, parent := tracer.Start(ctx, "dispatch")
+	_, child := tracer.Start(ctx, "event test")
+	child.SetAttribute("event.name", "test")
+	child.RecordError(errors.New("unavailable"))
+	child.End()
+	parent.End()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"span":"event test"`)
+	assert.Contains(t, string(lines[0]), `"trace_id":"request-1"`)
+	assert.Contains(t, string(lines[0]), `"parent_span_id":"`+parent.(*logSpan).id+`"`)
+	assert.Contains(t, string(lines[0]), `"event.name":"test"`)
+	assert.Contains(t, string(lines[0]), `"error":"unavailable"`)
+	assert.Contains(t, string(lines[1]), `"parent_span_id":""`)
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how many times a failing handler is run again and how
+// long the dispatcher waits between attempts. The zero value runs the handler
+// once.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Multiplier grows the backoff after each attempt, defaulting to 2.
+	Multiplier float64
+	// Jitter randomizes each backoff by up to this fraction (0 to 1), so
+	// handlers failing together do not retry in lockstep.
+	Jitter float64
+	// Retryable classifies the errors worth retrying. When nil every error is
+	// retried unless it was marked with Permanent.
+	Retryable func(err error) bool
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks a handler error as not worth retrying, such as a payload
+// that can never be encoded.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Backoff returns how long to wait after the given failed attempt, starting
+// at 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= multiplier
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		backoff += backoff * jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+// run calls fn until it succeeds, the attempts are exhausted, the error is
+// not retryable or ctx is done, returning how many attempts were made.
+func (p RetryPolicy) run(ctx context.Context, fn func() error) (int, error) {
+	maxAttempts := max(p.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxAttempts || !p.retryable(err) {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(p.Backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		}
+	}
+}
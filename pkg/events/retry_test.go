@@ -0,0 +1,165 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flakyHandler struct {
+	failures int32
+	err      error
+	calls    atomic.Int32
+}
+
+func (h *flakyHandler) Handle(ctx context.Context, event EventInterface) error {
+	if h.calls.Add(1) <= h.failures {
+		return h.err
+	}
+	return nil
+}
+
+type memoryDeadLetterSink struct {
+	mu          sync.Mutex
+	deadLetters []DeadLetter
+	err         error
+}
+
+func (s *memoryDeadLetterSink) Store(ctx context.Context, deadLetter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.deadLetters = append(s.deadLetters, deadLetter)
+	return nil
+}
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 800*time.Millisecond, policy.Backoff(4))
+	assert.Equal(t, time.Second, policy.Backoff(5))
+	assert.Equal(t, time.Second, policy.Backoff(50))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(2)
+		assert.GreaterOrEqual(t, backoff, 100*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 300*time.Millisecond)
+	}
+}
+
+func TestEventDispatcher_RetriesFailingHandler(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &flakyHandler{failures: 2, err: errors.New("unavailable")}
+	require.NoError(t, ed.RegisterWithRetry("test", handler, fastRetry))
+
+	assert.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}))
+	assert.Equal(t, int32(3), handler.calls.Load())
+}
+
+func TestEventDispatcher_DeadLettersExhaustedHandler(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(sink)
+	handler := &flakyHandler{failures: 10, err: errUnavailable}
+	require.NoError(t, ed.RegisterWithRetry("test", handler, fastRetry))
+
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "test", Payload: "order-1"})
+
+	var handlerErr *HandlerError
+	require.True(t, errors.As(err, &handlerErr))
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, 3, handlerErr.Attempts)
+	assert.True(t, handlerErr.DeadLettered)
+	require.Len(t, sink.deadLetters, 1)
+	assert.Equal(t, "test", sink.deadLetters[0].EventName)
+	assert.Equal(t, HandlerName(handler), sink.deadLetters[0].Handler)
+	assert.Equal(t, "order-1", sink.deadLetters[0].Payload)
+	assert.Equal(t, 3, sink.deadLetters[0].Attempts)
+	assert.Equal(t, "unavailable", sink.deadLetters[0].Err)
+}
+
+func TestEventDispatcher_DoesNotRetryPermanentErrors(t *testing.T) {
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(sink)
+	handler := &flakyHandler{failures: 10, err: Permanent(errors.New("invalid payload"))}
+	require.NoError(t, ed.RegisterWithRetry("test", handler, fastRetry))
+
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "test"})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), handler.calls.Load())
+	assert.Len(t, sink.deadLetters, 1)
+}
+
+func TestEventDispatcher_UsesRetryableClassification(t *testing.T) {
+	errRejected := errors.New("rejected")
+	policy := fastRetry
+	policy.Retryable = func(err error) bool { return !errors.Is(err, errRejected) }
+
+	ed := NewEventDispatcher()
+	handler := &flakyHandler{failures: 10, err: errRejected}
+	require.NoError(t, ed.RegisterWithRetry("test", handler, policy))
+
+	assert.ErrorIs(t, ed.Dispatch(context.Background(), &TestEvent{Name: "test"}), errRejected)
+	assert.Equal(t, int32(1), handler.calls.Load())
+}
+
+func TestEventDispatcher_StopsRetryingWhenContextIsDone(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &flakyHandler{failures: 10, err: errors.New("unavailable")}
+	require.NoError(t, ed.RegisterWithRetry("test", handler, RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, ed.Dispatch(ctx, &TestEvent{Name: "test"}))
+	assert.Equal(t, int32(1), handler.calls.Load())
+}
+
+func TestEventDispatcher_ReportsDeadLetterFailures(t *testing.T) {
+	errStore := errors.New("database down")
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(&memoryDeadLetterSink{err: errStore})
+	require.NoError(t, ed.Register("test", &flakyHandler{failures: 1, err: errors.New("unavailable")}))
+
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "test"})
+
+	assert.ErrorIs(t, err, errStore)
+	var handlerErr *HandlerError
+	require.True(t, errors.As(err, &handlerErr))
+	assert.False(t, handlerErr.DeadLettered)
+}
+
+func TestEventDispatcher_Redeliver(t *testing.T) {
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(sink)
+	failing := &flakyHandler{failures: 1, err: errors.New("unavailable")}
+	other := &countingHandler{}
+	require.NoError(t, ed.Register("test", failing))
+	require.NoError(t, ed.Register("test", other))
+
+	assert.Error(t, ed.Redeliver(context.Background(), HandlerName(failing), &TestEvent{Name: "test"}))
+	assert.Empty(t, sink.deadLetters)
+	assert.Equal(t, int32(0), other.handled.Load())
+
+	assert.NoError(t, ed.Redeliver(context.Background(), HandlerName(failing), &TestEvent{Name: "test"}))
+
+	err := ed.Redeliver(context.Background(), "*events.unknownHandler", &TestEvent{Name: "test"})
+	assert.ErrorIs(t, err, ErrHandlerNotRegistered)
+}
@@ -0,0 +1,216 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrScheduleNotFound   = errors.New("scheduled event not found")
+	ErrScheduleNotPending = errors.New("scheduled event already dispatched or cancelled")
+)
+
+// ScheduledEvent is an event waiting in the ScheduleStore to be dispatched
+// at DueAt. Its ID is the ID of the event.
+type ScheduledEvent struct {
+	ID        string
+	EventName string
+	Metadata  Metadata
+	Payload   json.RawMessage
+	DueAt     time.Time
+}
+
+// ScheduleStore persists the scheduled events so they survive restarts.
+type ScheduleStore interface {
+	Schedule(ctx context.Context, scheduled ScheduledEvent) error
+	// Due calls fn with the pending events due at now and not leased past
+	// it, earliest first, in a context carrying what the store kept from the
+	// one they were scheduled in.
+	Due(ctx context.Context, now time.Time, limit int, fn func(ctx context.Context, scheduled ScheduledEvent) error) error
+	// Claim leases a pending event until leaseUntil, returning
+	// ErrScheduleNotPending when it was dispatched or cancelled, or another
+	// scheduler holds a lease on it that has not expired.
+	Claim(ctx context.Context, id string, now, leaseUntil time.Time) error
+	// MarkDispatched settles a claimed event once it was dispatched.
+	MarkDispatched(ctx context.Context, id string, dispatchedAt time.Time) error
+	// MarkFailed settles a claimed event that can never be dispatched,
+	// keeping the reason.
+	MarkFailed(ctx context.Context, id string, failedAt time.Time, reason string) error
+	Cancel(ctx context.Context, id string, cancelledAt time.Time) error
+}
+
+type SchedulerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a scheduler has to dispatch a claimed event before
+	// another one may claim it, in case the first one died halfway. Defaults
+	// to a minute.
+	Lease time.Duration
+	// OnError receives the scheduled events that could not be dispatched.
+	// Defaults to logging them.
+	OnError func(scheduled ScheduledEvent, err error)
+}
+
+// Scheduler dispatches events at a later time. Due events are leased before
+// being dispatched and marked dispatched once they reach their handlers, so
+// several schedulers can poll the same store without dispatching an event
+// twice, and an event whose scheduler died or failed before dispatching it is
+// claimed again once the lease expires. Events that cannot be decoded are
+// marked failed, and handler failures are left to the retry policies and dead
+// letters of the dispatcher.
+type Scheduler struct {
+	store      ScheduleStore
+	dispatcher EventDispatcherInterface
+	decoders   map[string]EventDecoder
+	config     SchedulerConfig
+	now        func() time.Time
+}
+
+func NewScheduler(store ScheduleStore, dispatcher EventDispatcherInterface, decoders map[string]EventDecoder, config SchedulerConfig) *Scheduler {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+	if config.OnError == nil {
+		config.OnError = func(scheduled ScheduledEvent, err error) {
+			log.Printf("Scheduled dispatch of %s %s failed: %v", scheduled.EventName, scheduled.ID, err)
+		}
+	}
+	return &Scheduler{
+		store:      store,
+		dispatcher: dispatcher,
+		decoders:   decoders,
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+// ScheduleAt stores the event to be dispatched at dueAt and returns the ID
+// that cancels it.
+func (s *Scheduler) ScheduleAt(ctx context.Context, event EventInterface, dueAt time.Time) (string, error) {
+	if _, ok := s.decoders[event.GetName()]; !ok {
+		return "", fmt.Errorf("no decoder for event %s", event.GetName())
+	}
+	payload, err := json.Marshal(event.GetPayload())
+	if err != nil {
+		return "", err
+	}
+	metadata := MetadataOf(event)
+	if metadata.ID == "" {
+		metadata.ID = NewID()
+	}
+	err = s.store.Schedule(ctx, ScheduledEvent{
+		ID:        metadata.ID,
+		EventName: event.GetName(),
+		Metadata:  metadata,
+		Payload:   payload,
+		DueAt:     dueAt.UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return metadata.ID, nil
+}
+
+// ScheduleAfter stores the event to be dispatched once delay has passed.
+func (s *Scheduler) ScheduleAfter(ctx context.Context, event EventInterface, delay time.Duration) (string, error) {
+	return s.ScheduleAt(ctx, event, s.now().Add(delay))
+}
+
+// Cancel keeps a pending event from being dispatched.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	return s.store.Cancel(ctx, id, s.now().UTC())
+}
+
+// Run dispatches the due events every PollInterval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to load the scheduled events: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue dispatches the events due now, a batch at a time, and returns
+// how many it dispatched.
+func (s *Scheduler) DispatchDue(ctx context.Context) (int, error) {
+	dispatched := 0
+	for {
+		batch, settled := 0, 0
+		err := s.store.Due(ctx, s.now().UTC(), s.config.BatchSize, func(ctx context.Context, scheduled ScheduledEvent) error {
+			batch++
+			claimed, err := s.dispatch(ctx, scheduled)
+			if claimed {
+				settled++
+			}
+			if err == nil {
+				dispatched++
+			} else if !errors.Is(err, ErrScheduleNotPending) {
+				s.config.OnError(scheduled, err)
+			}
+			return ctx.Err()
+		})
+		// A batch where nothing could be claimed would come back as is.
+		if err != nil || batch < s.config.BatchSize || settled == 0 {
+			return dispatched, err
+		}
+	}
+}
+
+// dispatch reports whether the event left the pending ones, which it does
+// once claimed even if it then fails to dispatch. An event that cannot be
+// decoded never will be, so it is marked failed. Failures of the dispatcher
+// itself keep it leased, to be dispatched again once the lease expires;
+// handler failures are the dispatcher's, so the event is settled.
+func (s *Scheduler) dispatch(ctx context.Context, scheduled ScheduledEvent) (bool, error) {
+	now := s.now().UTC()
+	err := s.store.Claim(ctx, scheduled.ID, now, now.Add(s.config.Lease))
+	if errors.Is(err, ErrScheduleNotPending) {
+		return true, err
+	}
+	if err != nil {
+		return false, err
+	}
+
+	event, err := s.decode(scheduled)
+	if err != nil {
+		if markErr := s.store.MarkFailed(context.WithoutCancel(ctx), scheduled.ID, s.now().UTC(), err.Error()); markErr != nil {
+			return true, errors.Join(err, markErr)
+		}
+		return true, err
+	}
+	err = s.dispatcher.Dispatch(ctx, event)
+	var handlerErr *HandlerError
+	if err != nil && !errors.As(err, &handlerErr) {
+		return true, err
+	}
+	if markErr := s.store.MarkDispatched(context.WithoutCancel(ctx), scheduled.ID, s.now().UTC()); markErr != nil {
+		return true, errors.Join(err, markErr)
+	}
+	return true, err
+}
+
+func (s *Scheduler) decode(scheduled ScheduledEvent) (EventInterface, error) {
+	decode, ok := s.decoders[scheduled.EventName]
+	if !ok {
+		return nil, fmt.Errorf("no decoder for event %s", scheduled.EventName)
+	}
+	return decode(scheduled.Metadata, scheduled.Payload)
+}
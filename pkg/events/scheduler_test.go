@@ -0,0 +1,324 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryScheduleStore struct {
+	mu        sync.Mutex
+	scheduled map[string]ScheduledEvent
+	states    map[string]string
+	leases    map[string]time.Time
+	err       error
+}
+
+func newMemoryScheduleStore() *memoryScheduleStore {
+	return &memoryScheduleStore{scheduled: make(map[string]ScheduledEvent), states: make(map[string]string), leases: make(map[string]time.Time)}
+}
+
+func (s *memoryScheduleStore) Schedule(ctx context.Context, scheduled ScheduledEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduled[scheduled.ID] = scheduled
+	s.states[scheduled.ID] = "pending"
+	return nil
+}
+
+func (s *memoryScheduleStore) Due(ctx context.Context, now time.Time, limit int, fn func(ctx context.Context, scheduled ScheduledEvent) error) error {
+	s.mu.Lock()
+	var due []ScheduledEvent
+	for id, scheduled := range s.scheduled {
+		if s.states[id] == "pending" && !scheduled.DueAt.After(now) && !s.leases[id].After(now) {
+			due = append(due, scheduled)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(due, func(i, j int) bool { return due[i].DueAt.Before(due[j].DueAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, scheduled := range due {
+		if err := fn(ctx, scheduled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryScheduleStore) Claim(ctx context.Context, id string, now, leaseUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if _, ok := s.states[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	if s.states[id] != "pending" || s.leases[id].After(now) {
+		return ErrScheduleNotPending
+	}
+	s.leases[id] = leaseUntil
+	return nil
+}
+
+func (s *memoryScheduleStore) MarkDispatched(ctx context.Context, id string, dispatchedAt time.Time) error {
+	return s.settle(id, "dispatched")
+}
+
+func (s *memoryScheduleStore) MarkFailed(ctx context.Context, id string, failedAt time.Time, reason string) error {
+	return s.settle(id, "failed")
+}
+
+func (s *memoryScheduleStore) Cancel(ctx context.Context, id string, cancelledAt time.Time) error {
+	return s.settle(id, "cancelled")
+}
+
+func (s *memoryScheduleStore) settle(id, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if _, ok := s.states[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	if s.states[id] != "pending" {
+		return ErrScheduleNotPending
+	}
+	s.states[id] = state
+	return nil
+}
+
+// recordingPayloadHandler keeps the payloads and metadata it handled, in
+// order. It is only used with the synchronous dispatcher.
+type recordingPayloadHandler struct {
+	payloads []interface{}
+	metadata []Metadata
+}
+
+func (h *recordingPayloadHandler) Handle(ctx context.Context, event EventInterface) error {
+	h.payloads = append(h.payloads, event.GetPayload())
+	h.metadata = append(h.metadata, MetadataOf(event))
+	return nil
+}
+
+func decodeString(metadata Metadata, payload []byte) (EventInterface, error) {
+	var decoded string
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, err
+	}
+	return RestoreEvent("reminder", decoded, metadata), nil
+}
+
+func newTestScheduler(store ScheduleStore, dispatcher EventDispatcherInterface, now *time.Time, config SchedulerConfig) *Scheduler {
+	scheduler := NewScheduler(store, dispatcher, map[string]EventDecoder{"reminder": decodeString}, config)
+	scheduler.now = func() time.Time { return *now }
+	return scheduler
+}
+
+func TestScheduler_DispatchDue(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryScheduleStore()
+	ed := NewEventDispatcher()
+	handler := &recordingPayloadHandler{}
+	require.NoError(t, ed.Register("reminder", handler))
+	scheduler := newTestScheduler(store, ed, &now, SchedulerConfig{BatchSize: 1})
+
+	event := NewEvent("reminder", "order-1 unpaid")
+	id, err := scheduler.ScheduleAfter(context.Background(), event, 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, event.GetMetadata().ID, id)
+	_, err = scheduler.ScheduleAt(context.Background(), NewEvent("reminder", "order-2 unpaid"), now.Add(10*time.Minute))
+	require.NoError(t, err)
+
+	dispatched, err := scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+
+	now = now.Add(time.Hour)
+	dispatched, err = scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+	assert.Equal(t, []interface{}{"order-2 unpaid", "order-1 unpaid"}, handler.payloads)
+	assert.Equal(t, id, handler.metadata[1].ID)
+
+	dispatched, err = scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryScheduleStore()
+	ed := NewEventDispatcher()
+	handler := &recordingPayloadHandler{}
+	require.NoError(t, ed.Register("reminder", handler))
+	scheduler := newTestScheduler(store, ed, &now, SchedulerConfig{})
+
+	id, err := scheduler.ScheduleAfter(context.Background(), NewEvent("reminder", "order-1 unpaid"), time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, scheduler.Cancel(context.Background(), id))
+	assert.ErrorIs(t, scheduler.Cancel(context.Background(), id), ErrScheduleNotPending)
+	assert.ErrorIs(t, scheduler.Cancel(context.Background(), "unknown"), ErrScheduleNotFound)
+
+	now = now.Add(time.Hour)
+	dispatched, err := scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	assert.Empty(t, handler.payloads)
+}
+
+func TestScheduler_Failures(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryScheduleStore()
+	var failed []error
+	scheduler := newTestScheduler(store, NewEventDispatcher(), &now, SchedulerConfig{
+		BatchSize: 1,
+		OnError:   func(scheduled ScheduledEvent, err error) { failed = append(failed, err) },
+	})
+
+	_, err := scheduler.ScheduleAfter(context.Background(), NewEvent("unknown", "payload"), time.Minute)
+	assert.Error(t, err)
+
+	_, err = scheduler.ScheduleAfter(context.Background(), NewEvent("reminder", "order-1 unpaid"), time.Minute)
+	require.NoError(t, err)
+	store.err = errors.New("database is down")
+	now = now.Add(time.Hour)
+
+	dispatched, err := scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	require.Len(t, failed, 1)
+	assert.EqualError(t, failed[0], "database is down")
+}
+
+func TestScheduler_Run(t *testing.T) {
+	now := time.Now()
+	store := newMemoryScheduleStore()
+	ed := NewEventDispatcher()
+	handler := &countingHandler{}
+	require.NoError(t, ed.Register("reminder", handler))
+	scheduler := NewScheduler(store, ed, map[string]EventDecoder{"reminder": decodeString}, SchedulerConfig{PollInterval: time.Millisecond})
+
+	_, err := scheduler.ScheduleAt(context.Background(), NewEvent("reminder", "order-1 unpaid"), now)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return handler.handled.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+}
+
+// unavailableDispatcher fails before reaching the handlers, like a dispatcher
+// whose event store is down.
+type unavailableDispatcher struct {
+	EventDispatcherInterface
+	err error
+}
+
+func (d *unavailableDispatcher) Dispatch(ctx context.Context, event EventInterface) error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.EventDispatcherInterface.Dispatch(ctx, event)
+}
+
+func TestScheduler_Lease(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryScheduleStore()
+	ed := NewEventDispatcher()
+	handler := &recordingPayloadHandler{}
+	require.NoError(t, ed.Register("reminder", handler))
+	dispatcher := &unavailableDispatcher{EventDispatcherInterface: ed, err: errors.New("event store unavailable")}
+	var failed []error
+	scheduler := newTestScheduler(store, dispatcher, &now, SchedulerConfig{
+		Lease:   time.Minute,
+		OnError: func(scheduled ScheduledEvent, err error) { failed = append(failed, err) },
+	})
+
+	id, err := scheduler.ScheduleAt(context.Background(), NewEvent("reminder", "order-1 unpaid"), now)
+	require.NoError(t, err)
+
+	dispatched, err := scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	assert.Equal(t, []error{dispatcher.err}, failed)
+	assert.Equal(t, "pending", store.states[id])
+
+	dispatcher.err = nil
+	dispatched, err = scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched, "the event is leased to the first attempt")
+	assert.ErrorIs(t, store.Claim(context.Background(), id, now, now.Add(time.Minute)), ErrScheduleNotPending)
+
+	now = now.Add(time.Minute)
+	dispatched, err = scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+	assert.Equal(t, []interface{}{"order-1 unpaid"}, handler.payloads)
+	assert.Equal(t, "dispatched", store.states[id])
+}
+
+func TestScheduler_DecodeFailureFailsTheEvent(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryScheduleStore()
+	ed := NewEventDispatcher()
+	handler := &recordingPayloadHandler{}
+	require.NoError(t, ed.Register("reminder", handler))
+	var failed []error
+	scheduler := newTestScheduler(store, ed, &now, SchedulerConfig{
+		OnError: func(scheduled ScheduledEvent, err error) { failed = append(failed, err) },
+	})
+
+	require.NoError(t, store.Schedule(context.Background(), ScheduledEvent{ID: "1", EventName: "reminder", Payload: []byte("{"), DueAt: now}))
+	require.NoError(t, store.Schedule(context.Background(), ScheduledEvent{ID: "2", EventName: "unknown", Payload: []byte(`"x"`), DueAt: now}))
+
+	dispatched, err := scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	assert.Len(t, failed, 2)
+	assert.Equal(t, "failed", store.states["1"])
+	assert.Equal(t, "failed", store.states["2"])
+	assert.Empty(t, handler.payloads)
+
+	now = now.Add(time.Hour)
+	dispatched, err = scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	assert.Len(t, failed, 2)
+}
+
+func TestScheduler_HandlerFailureSettlesTheEvent(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryScheduleStore()
+	ed := NewEventDispatcher()
+	require.NoError(t, ed.Register("reminder", &countingHandler{err: errors.New("handler failed")}))
+	var failed []error
+	scheduler := newTestScheduler(store, ed, &now, SchedulerConfig{
+		OnError: func(scheduled ScheduledEvent, err error) { failed = append(failed, err) },
+	})
+
+	id, err := scheduler.ScheduleAt(context.Background(), NewEvent("reminder", "order-1 unpaid"), now)
+	require.NoError(t, err)
+	_, err = scheduler.DispatchDue(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, failed, 1)
+	var handlerErr *HandlerError
+	assert.ErrorAs(t, failed[0], &handlerErr)
+	assert.Equal(t, "dispatched", store.states[id])
+}
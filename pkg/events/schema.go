@@ -0,0 +1,169 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrSchemaNotRegistered = errors.New("event schema not registered")
+	ErrSchemaTooNew        = errors.New("event schema version is newer than the registered one")
+	ErrUpcasterNotFound    = errors.New("no upcaster for event schema version")
+	ErrInvalidSchema       = errors.New("invalid event schema")
+)
+
+// Upcaster transforms the JSON payload of an event from one schema version to
+// the next.
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+type schema struct {
+	version   int
+	decode    func(payload []byte) (interface{}, error)
+	restore   func(name string, payload interface{}, metadata Metadata) EventInterface
+	upcasters map[int]Upcaster
+}
+
+// SchemaRegistry maps each event name to its current schema version and
+// payload type, and holds the upcasters that bring the payloads stored or
+// queued under older versions up to the current one.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*schema
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]*schema)}
+}
+
+// RegisterSchema sets T as the payload type of the given version of the
+// event, the one its payloads are decoded into.
+func RegisterSchema[T any](registry *SchemaRegistry, eventName string, version int) error {
+	if version < 1 {
+		return fmt.Errorf("%w: %s version %d", ErrInvalidSchema, eventName, version)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.schemas[eventName]; ok {
+		return fmt.Errorf("%w: %s already registered", ErrInvalidSchema, eventName)
+	}
+	registry.schemas[eventName] = &schema{
+		version: version,
+		decode: func(payload []byte) (interface{}, error) {
+			var decoded T
+			err := json.Unmarshal(payload, &decoded)
+			return decoded, err
+		},
+		restore: func(name string, payload interface{}, metadata Metadata) EventInterface {
+			return RestoreEvent(name, payload.(T), metadata)
+		},
+		upcasters: make(map[int]Upcaster),
+	}
+	return nil
+}
+
+// RegisterUpcaster sets how payloads of version fromVersion of the event are
+// turned into version fromVersion+1.
+func (r *SchemaRegistry) RegisterUpcaster(eventName string, fromVersion int, upcaster Upcaster) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schema, ok := r.schemas[eventName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSchemaNotRegistered, eventName)
+	}
+	if fromVersion < 1 || fromVersion >= schema.version {
+		return fmt.Errorf("%w: %s upcaster from version %d, current is %d", ErrInvalidSchema, eventName, fromVersion, schema.version)
+	}
+	schema.upcasters[fromVersion] = upcaster
+	return nil
+}
+
+// Version returns the current schema version of the event.
+func (r *SchemaRegistry) Version(eventName string) (int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[eventName]
+	if !ok {
+		return 0, false
+	}
+	return schema.version, true
+}
+
+// Upcast brings a payload of the given version up to the current one. Events
+// without a version predate versioning and are taken as version 1.
+func (r *SchemaRegistry) Upcast(eventName string, version int, payload []byte) ([]byte, error) {
+	_, payload, err := r.upcast(eventName, version, payload)
+	return payload, err
+}
+
+// Decode rebuilds the event from its metadata and JSON payload, upcasting the
+// payload first. The event keeps its ID and correlation, but its version
+// becomes the current one.
+func (r *SchemaRegistry) Decode(eventName string, metadata Metadata, payload []byte) (EventInterface, error) {
+	schema, payload, err := r.upcast(eventName, metadata.Version, payload)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := schema.decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s version %d: %w", eventName, schema.version, err)
+	}
+	metadata.Version = schema.version
+	return schema.restore(eventName, decoded, metadata), nil
+}
+
+// Decoder returns the EventDecoder of one event.
+func (r *SchemaRegistry) Decoder(eventName string) EventDecoder {
+	return func(metadata Metadata, payload []byte) (EventInterface, error) {
+		return r.Decode(eventName, metadata, payload)
+	}
+}
+
+// Decoders returns the EventDecoder of every registered event, by name.
+func (r *SchemaRegistry) Decoders() map[string]EventDecoder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decoders := make(map[string]EventDecoder, len(r.schemas))
+	for eventName := range r.schemas {
+		decoders[eventName] = r.Decoder(eventName)
+	}
+	return decoders
+}
+
+func (r *SchemaRegistry) upcast(eventName string, version int, payload []byte) (*schema, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[eventName]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrSchemaNotRegistered, eventName)
+	}
+	payload, err := schema.upcast(eventName, version, payload)
+	return schema, payload, err
+}
+
+func (s *schema) upcast(eventName string, version int, payload []byte) ([]byte, error) {
+	if version == 0 {
+		version = 1
+	}
+	if version > s.version {
+		return nil, fmt.Errorf("%w: %s version %d, registered %d", ErrSchemaTooNew, eventName, version, s.version)
+	}
+	for ; version < s.version; version++ {
+		upcaster, ok := s.upcasters[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s version %d", ErrUpcasterNotFound, eventName, version)
+		}
+		upcasted, err := upcaster(payload)
+		if err != nil {
+			return nil, fmt.Errorf("upcasting %s from version %d: %w", eventName, version, err)
+		}
+		payload = upcasted
+	}
+	return payload, nil
+}
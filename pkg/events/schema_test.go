@@ -0,0 +1,119 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderPlacedV3 struct {
+	ID    string  `json:"id"`
+	Total float64 `json:"total"`
+	Notes string  `json:"notes"`
+}
+
+// newOrderPlacedRegistry has orderPlaced at version 3: version 2 renamed
+// price to total and version 3 added notes.
+func newOrderPlacedRegistry(t *testing.T) *SchemaRegistry {
+	registry := NewSchemaRegistry()
+	require.NoError(t, RegisterSchema[orderPlacedV3](registry, "orderPlaced", 3))
+	require.NoError(t, registry.RegisterUpcaster("orderPlaced", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(strings.Replace(string(payload), `"price"`, `"total"`, 1)), nil
+	}))
+	require.NoError(t, registry.RegisterUpcaster("orderPlaced", 2, func(payload json.RawMessage) (json.RawMessage, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return nil, err
+		}
+		fields["notes"] = "none"
+		return json.Marshal(fields)
+	}))
+	return registry
+}
+
+func TestSchemaRegistry_Decode(t *testing.T) {
+	registry := newOrderPlacedRegistry(t)
+	occurredAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		version  int
+		payload  string
+		expected orderPlacedV3
+	}{
+		{"unversioned", 0, `{"id":"1","price":10}`, orderPlacedV3{ID: "1", Total: 10, Notes: "none"}},
+		{"version 1", 1, `{"id":"1","price":10}`, orderPlacedV3{ID: "1", Total: 10, Notes: "none"}},
+		{"version 2", 2, `{"id":"1","total":10}`, orderPlacedV3{ID: "1", Total: 10, Notes: "none"}},
+		{"current version", 3, `{"id":"1","total":10,"notes":"gift"}`, orderPlacedV3{ID: "1", Total: 10, Notes: "gift"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := Metadata{ID: "event-1", OccurredAt: occurredAt, CorrelationID: "request-1", Version: tt.version}
+
+			event, err := registry.Decode("orderPlaced", metadata, []byte(tt.payload))
+
+			require.NoError(t, err)
+			typed, ok := event.(*Event[orderPlacedV3])
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, typed.Payload())
+			assert.Equal(t, "orderPlaced", typed.GetName())
+			assert.Equal(t, "event-1", typed.GetMetadata().ID)
+			assert.Equal(t, "request-1", typed.GetMetadata().CorrelationID)
+			assert.Equal(t, 3, typed.GetMetadata().Version)
+		})
+	}
+}
+
+func TestSchemaRegistry_DecodeErrors(t *testing.T) {
+	registry := newOrderPlacedRegistry(t)
+
+	_, err := registry.Decode("orderCancelled", Metadata{Version: 1}, []byte(`{}`))
+	assert.ErrorIs(t, err, ErrSchemaNotRegistered)
+
+	_, err = registry.Decode("orderPlaced", Metadata{Version: 4}, []byte(`{}`))
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+
+	_, err = registry.Decode("orderPlaced", Metadata{Version: 2}, []byte(`not json`))
+	assert.Error(t, err)
+
+	gapped := NewSchemaRegistry()
+	require.NoError(t, RegisterSchema[orderPlacedV3](gapped, "orderPlaced", 3))
+	require.NoError(t, gapped.RegisterUpcaster("orderPlaced", 2, func(payload json.RawMessage) (json.RawMessage, error) { return payload, nil }))
+	_, err = gapped.Decode("orderPlaced", Metadata{Version: 1}, []byte(`{}`))
+	assert.ErrorIs(t, err, ErrUpcasterNotFound)
+}
+
+func TestSchemaRegistry_Register(t *testing.T) {
+	registry := NewSchemaRegistry()
+	require.NoError(t, RegisterSchema[orderPlacedV3](registry, "orderPlaced", 2))
+
+	assert.ErrorIs(t, RegisterSchema[orderPlacedV3](registry, "orderPlaced", 3), ErrInvalidSchema)
+	assert.ErrorIs(t, RegisterSchema[orderPlacedV3](registry, "orderCancelled", 0), ErrInvalidSchema)
+	assert.ErrorIs(t, registry.RegisterUpcaster("orderPlaced", 2, nil), ErrInvalidSchema)
+	assert.ErrorIs(t, registry.RegisterUpcaster("orderCancelled", 1, nil), ErrSchemaNotRegistered)
+
+	version, ok := registry.Version("orderPlaced")
+	assert.True(t, ok)
+	assert.Equal(t, 2, version)
+	_, ok = registry.Version("orderCancelled")
+	assert.False(t, ok)
+}
+
+func TestSchemaRegistry_Decoders(t *testing.T) {
+	registry := newOrderPlacedRegistry(t)
+
+	decoders := registry.Decoders()
+	require.Contains(t, decoders, "orderPlaced")
+
+	event, err := decoders["orderPlaced"](Metadata{Version: 1}, []byte(`{"id":"1","price":10}`))
+	require.NoError(t, err)
+	assert.Equal(t, orderPlacedV3{ID: "1", Total: 10, Notes: "none"}, event.GetPayload())
+
+	payload, err := registry.Upcast("orderPlaced", 2, []byte(`{"id":"1","total":10}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","total":10,"notes":"none"}`, string(payload))
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Event names registered with Register may be patterns in the syntax of
+// path.Match: "Order.*" subscribes to every event starting with "Order." and
+// "*" to all of them.
+
+func isPattern(eventName string) bool {
+	return strings.ContainsAny(eventName, `*?[\`)
+}
+
+func validateSubscription(eventName string) error {
+	if _, err := path.Match(eventName, ""); err != nil {
+		return fmt.Errorf("invalid event pattern %q: %w", eventName, err)
+	}
+	return nil
+}
+
+func subscriptionMatches(subscription, eventName string) bool {
+	if !isPattern(subscription) {
+		return subscription == eventName
+	}
+	matched, _ := path.Match(subscription, eventName)
+	return matched
+}
+
+type handlerOptions struct {
+	retry    RetryPolicy
+	priority int
+}
+
+// HandlerOption configures a handler registered with RegisterWith.
+type HandlerOption func(options *handlerOptions)
+
+// WithRetry runs the handler again under the policy when it fails.
+func WithRetry(policy RetryPolicy) HandlerOption {
+	return func(options *handlerOptions) {
+		options.retry = policy
+	}
+}
+
+// WithPriority orders the handler for the events run sequentially, higher
+// priorities first. Handlers default to priority 0 and keep their
+// registration order on ties.
+func WithPriority(priority int) HandlerOption {
+	return func(options *handlerOptions) {
+		options.priority = priority
+	}
+}
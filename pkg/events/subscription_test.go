@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderRecorder collects the names of the handlers in the order they finish.
+type orderRecorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+type recordedHandler struct {
+	recorder *orderRecorder
+	name     string
+	delay    time.Duration
+	err      error
+}
+
+func (h *recordedHandler) Handle(ctx context.Context, event EventInterface) error {
+	time.Sleep(h.delay)
+	h.recorder.mu.Lock()
+	defer h.recorder.mu.Unlock()
+	h.recorder.names = append(h.recorder.names, h.name)
+	return h.err
+}
+
+func (r *orderRecorder) handler(name string, delay time.Duration, err error) EventHandlerInterface {
+	return &recordedHandler{recorder: r, name: name, delay: delay, err: err}
+}
+
+func TestEventDispatcher_PatternSubscriptions(t *testing.T) {
+	ed := NewEventDispatcher()
+	exact := &countingHandler{}
+	orders := &countingHandler{}
+	all := &countingHandler{}
+	require.NoError(t, ed.Register("Order.Created", exact))
+	require.NoError(t, ed.Register("Order.*", orders))
+	require.NoError(t, ed.Register("*", all))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Created"}))
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Deleted"}))
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Customer.Created"}))
+
+	assert.Equal(t, int32(1), exact.handled.Load())
+	assert.Equal(t, int32(2), orders.handled.Load())
+	assert.Equal(t, int32(3), all.handled.Load())
+	assert.True(t, ed.Has("Order.*", orders))
+	assert.False(t, ed.Has("Order.Created", orders))
+}
+
+func TestEventDispatcher_PatternSubscriptions_RunHandlerOnce(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &countingHandler{}
+	require.NoError(t, ed.Register("Order.Created", handler))
+	require.NoError(t, ed.Register("Order.*", handler))
+	require.NoError(t, ed.Register("*", handler))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Created"}))
+
+	assert.Equal(t, int32(1), handler.handled.Load())
+}
+
+func TestEventDispatcher_RejectsInvalidPatterns(t *testing.T) {
+	ed := NewEventDispatcher()
+
+	assert.Error(t, ed.Register("Order.[", &countingHandler{}))
+	assert.Error(t, ed.RunSequentially("Order.["))
+}
+
+func TestEventDispatcher_RejectsHandlersThatAreNotComparable(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := HandlerFunc(func(ctx context.Context, event EventInterface) error { return nil })
+
+	assert.ErrorIs(t, ed.Register("test", handler), ErrHandlerNotComparable)
+}
+
+func TestEventDispatcher_RemovePatternSubscription(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &countingHandler{}
+	require.NoError(t, ed.Register("Order.*", handler))
+	require.NoError(t, ed.Remove("Order.*", handler))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Created"}))
+
+	assert.Equal(t, int32(0), handler.handled.Load())
+}
+
+func TestEventDispatcher_RunSequentially_InPriorityOrder(t *testing.T) {
+	recorder := &orderRecorder{}
+	ed := NewEventDispatcher()
+	require.NoError(t, ed.RunSequentially("Order.*"))
+	require.NoError(t, ed.RegisterWith("Order.Created", recorder.handler("audit", 0, nil), WithPriority(-1)))
+	require.NoError(t, ed.RegisterWith("Order.Created", recorder.handler("reserve-stock", 20*time.Millisecond, nil), WithPriority(10)))
+	require.NoError(t, ed.Register("Order.Created", recorder.handler("notify", 0, nil)))
+	require.NoError(t, ed.Register("*", recorder.handler("forward", 0, nil)))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Created"}))
+
+	assert.Equal(t, []string{"reserve-stock", "notify", "forward", "audit"}, recorder.names)
+}
+
+func TestEventDispatcher_RunSequentially_StopsAtFirstFailure(t *testing.T) {
+	errStock := errors.New("out of stock")
+	recorder := &orderRecorder{}
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(sink)
+	require.NoError(t, ed.RunSequentially("Order.Created"))
+	require.NoError(t, ed.RegisterWith("Order.Created", recorder.handler("reserve-stock", 0, errStock), WithPriority(1)))
+	require.NoError(t, ed.Register("Order.Created", recorder.handler("notify", 0, nil)))
+
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Created"})
+
+	assert.ErrorIs(t, err, errStock)
+	assert.Equal(t, []string{"reserve-stock"}, recorder.names)
+	assert.Len(t, sink.deadLetters, 1)
+}
+
+func TestEventDispatcher_RunsOtherEventsConcurrently(t *testing.T) {
+	recorder := &orderRecorder{}
+	ed := NewEventDispatcher()
+	require.NoError(t, ed.RunSequentially("Order.Created"))
+	require.NoError(t, ed.RegisterWith("Order.Deleted", recorder.handler("slow", 20*time.Millisecond, nil), WithPriority(10)))
+	require.NoError(t, ed.Register("Order.Deleted", recorder.handler("fast", 0, nil)))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Deleted"}))
+
+	assert.Equal(t, []string{"fast", "slow"}, recorder.names)
+}
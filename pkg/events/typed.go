@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var ErrUnexpectedPayload = errors.New("unexpected event payload")
+
+// Event is an event whose payload type is known at compile time. It
+// implements EventInterface, so it is dispatched like any other event and
+// reaches the untyped handlers too.
+type Event[T any] struct {
+	name     string
+	payload  T
+	metadata Metadata
+}
+
+// NewEvent builds an event with a new ID, starting its own correlation.
+func NewEvent[T any](name string, payload T, options ...EventOption) *Event[T] {
+	return NewEventWithContext(context.Background(), name, payload, options...)
+}
+
+// NewEventWithContext builds an event with a new ID, correlated with the
+// request or event being processed in ctx.
+func NewEventWithContext[T any](ctx context.Context, name string, payload T, options ...EventOption) *Event[T] {
+	return &Event[T]{
+		name:     name,
+		payload:  payload,
+		metadata: newMetadata(ctx, options),
+	}
+}
+
+// RestoreEvent rebuilds an event that was stored, keeping its metadata so it
+// is still recognized as the same event.
+func RestoreEvent[T any](name string, payload T, metadata Metadata) *Event[T] {
+	return &Event[T]{
+		name:     name,
+		payload:  payload,
+		metadata: metadata,
+	}
+}
+
+func (e *Event[T]) GetName() string {
+	return e.name
+}
+
+func (e *Event[T]) GetDateTime() time.Time {
+	return e.metadata.OccurredAt
+}
+
+func (e *Event[T]) GetMetadata() Metadata {
+	return e.metadata
+}
+
+func (e *Event[T]) GetPayload() interface{} {
+	return e.payload
+}
+
+// Payload returns the payload with its type.
+func (e *Event[T]) Payload() T {
+	return e.payload
+}
+
+// Handler handles the events carrying a T payload.
+type Handler[T any] interface {
+	Handle(ctx context.Context, event *Event[T]) error
+}
+
+// typedHandler adapts a Handler[T] to EventHandlerInterface. It is a value
+// holding the handler, so two adapters of the same handler are equal and
+// Has and Remove find it.
+type typedHandler[T any] struct {
+	handler Handler[T]
+}
+
+// Typed adapts a Handler[T] to be registered in the dispatcher. Events that
+// are not an *Event[T] are accepted when their payload is a T, which keeps
+// the events built before the typed API working; any other payload fails
+// with a permanent ErrUnexpectedPayload.
+func Typed[T any](handler Handler[T]) EventHandlerInterface {
+	return typedHandler[T]{handler: handler}
+}
+
+func (h typedHandler[T]) Handle(ctx context.Context, event EventInterface) error {
+	if typed, ok := event.(*Event[T]); ok {
+		return h.handler.Handle(ctx, typed)
+	}
+	payload, ok := event.GetPayload().(T)
+	if !ok {
+		var expected T
+		return Permanent(fmt.Errorf("%w: event %s carries %T, handler expects %T", ErrUnexpectedPayload, event.GetName(), event.GetPayload(), expected))
+	}
+	return h.handler.Handle(ctx, RestoreEvent(event.GetName(), payload, MetadataOf(event)))
+}
+
+func (h typedHandler[T]) HandlerName() string {
+	return fmt.Sprintf("%T", h.handler)
+}
+
+// Subscribe registers a typed handler for an event name or pattern.
+func Subscribe[T any](ed *EventDispatcher, eventName string, handler Handler[T], options ...HandlerOption) error {
+	if !reflect.TypeOf(handler).Comparable() {
+		return ErrHandlerNotComparable
+	}
+	return ed.RegisterWith(eventName, Typed(handler), options...)
+}
+
+// Unsubscribe removes a typed handler registered with Subscribe.
+func Unsubscribe[T any](ed *EventDispatcher, eventName string, handler Handler[T]) error {
+	return ed.Remove(eventName, Typed(handler))
+}
+
+// Publish builds an Event[T] correlated with ctx and dispatches it.
+func Publish[T any](ctx context.Context, dispatcher EventDispatcherInterface, eventName string, payload T, options ...EventOption) error {
+	return dispatcher.Dispatch(ctx, NewEventWithContext(ctx, eventName, payload, options...))
+}
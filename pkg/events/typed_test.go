@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderPlaced struct {
+	ID    string
+	Total float64
+}
+
+type orderPlacedHandler struct {
+	mu       sync.Mutex
+	received []orderPlaced
+	err      error
+}
+
+func (h *orderPlacedHandler) Handle(ctx context.Context, event *Event[orderPlaced]) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.received = append(h.received, event.Payload())
+	return h.err
+}
+
+func TestSubscribe_DeliversTypedPayload(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &orderPlacedHandler{}
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", handler))
+
+	require.NoError(t, Publish(context.Background(), ed, "Order.Placed", orderPlaced{ID: "1", Total: 10}))
+
+	assert.Equal(t, []orderPlaced{{ID: "1", Total: 10}}, handler.received)
+}
+
+func TestSubscribe_AcceptsUntypedEventsWithMatchingPayload(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &orderPlacedHandler{}
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", handler))
+
+	require.NoError(t, ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Placed", Payload: orderPlaced{ID: "2"}}))
+
+	assert.Equal(t, []orderPlaced{{ID: "2"}}, handler.received)
+}
+
+func TestSubscribe_RejectsUnexpectedPayload(t *testing.T) {
+	sink := &memoryDeadLetterSink{}
+	ed := NewEventDispatcher()
+	ed.SetDeadLetterSink(sink)
+	handler := &orderPlacedHandler{}
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", handler, WithRetry(fastRetry)))
+
+	err := ed.Dispatch(context.Background(), &TestEvent{Name: "Order.Placed", Payload: "not an order"})
+
+	assert.ErrorIs(t, err, ErrUnexpectedPayload)
+	assert.Empty(t, handler.received)
+	require.Len(t, sink.deadLetters, 1)
+	assert.Equal(t, 1, sink.deadLetters[0].Attempts)
+	assert.Equal(t, "*events.orderPlacedHandler", sink.deadLetters[0].Handler)
+}
+
+func TestSubscribe_KeepsHandlerIdentity(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &orderPlacedHandler{}
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", handler))
+
+	assert.ErrorIs(t, Subscribe[orderPlaced](ed, "Order.Placed", handler), ErrHandlerAlreadyRegistered)
+	assert.True(t, ed.Has("Order.Placed", Typed[orderPlaced](handler)))
+	assert.Equal(t, "*events.orderPlacedHandler", HandlerName(Typed[orderPlaced](handler)))
+
+	require.NoError(t, Unsubscribe[orderPlaced](ed, "Order.Placed", handler))
+	assert.False(t, ed.Has("Order.Placed", Typed[orderPlaced](handler)))
+}
+
+func TestSubscribe_RedeliversByWrappedHandlerName(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &orderPlacedHandler{err: errors.New("unavailable")}
+	require.NoError(t, Subscribe[orderPlaced](ed, "Order.Placed", handler))
+
+	err := ed.Redeliver(context.Background(), "*events.orderPlacedHandler", NewEvent("Order.Placed", orderPlaced{ID: "3"}))
+
+	assert.Error(t, err)
+	assert.Equal(t, []orderPlaced{{ID: "3"}}, handler.received)
+}
+
+func TestEvent_ReachesUntypedHandlers(t *testing.T) {
+	ed := NewEventDispatcher()
+	handler := &MockHandler{}
+	event := NewEvent("Order.Placed", orderPlaced{ID: "4"})
+	handler.On("Handle", event).Return(nil)
+	require.NoError(t, ed.Register("Order.Placed", handler))
+
+	require.NoError(t, ed.Dispatch(context.Background(), event))
+
+	handler.AssertExpectations(t)
+	assert.Equal(t, orderPlaced{ID: "4"}, event.GetPayload())
+}